@@ -0,0 +1,140 @@
+// Package apierror définit les erreurs retournées par l'API et le catalogue de leurs codes.
+// Les handlers signalent une erreur avec c.Error ; middleware.Errors la traduit en une réponse unique :
+//
+//	{"error": {"code": "RESOURCE_UNAVAILABLE", "message": "...", "fields": [...], "details": {...}, "request_id": "..."}}
+package apierror
+
+import (
+	"net/http"
+
+	"awesomeProject/internal/i18n"
+)
+
+// FieldError décrit un champ refusé de la requête.
+type FieldError struct {
+	Field   string `json:"field"`   // Nom du champ dans le JSON ou la query string
+	Rule    string `json:"rule"`    // Règle non respectée : "required", "email", "min", "oneof"...
+	Message string `json:"message"` // Message destiné à l'utilisateur
+
+	args []any // Valeurs des verbes du message
+}
+
+// Error est une erreur destinée au client de l'API.
+type Error struct {
+	Status    int            `json:"-"`
+	Code      Code           `json:"code"`
+	Message   string         `json:"message"`
+	Fields    []FieldError   `json:"fields,omitempty"`
+	Details   map[string]any `json:"details,omitempty"` // Précisions, par exemple le statut actuel d'une ressource
+	RequestID string         `json:"request_id,omitempty"`
+	Err       error          `json:"-"` // Cause, journalisée mais jamais exposée
+
+	args []any // Valeurs des verbes du message
+}
+
+func (e *Error) Error() string {
+	message := i18n.T(i18n.FR, e.Message, e.args...)
+	if e.Err != nil {
+		return string(e.Code) + " : " + message + " : " + e.Err.Error()
+	}
+	return string(e.Code) + " : " + message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New construit une erreur de l'API.
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// WithDetail ajoute une précision à l'erreur.
+func (e *Error) WithDetail(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// WithField ajoute un champ refusé à l'erreur. Les valeurs args remplacent les verbes du message.
+func (e *Error) WithField(field, rule, message string, args ...any) *Error {
+	e.Fields = append(e.Fields, FieldError{Field: field, Rule: rule, Message: message, args: args})
+	return e
+}
+
+// WithArgs renseigne les valeurs des verbes (%s, %d) du message, remplacés après traduction.
+// Les champs déjà ajoutés sans valeurs propres, qui reprennent le message, les reçoivent aussi.
+func (e *Error) WithArgs(args ...any) *Error {
+	e.args = args
+	for i := range e.Fields {
+		if e.Fields[i].args == nil {
+			e.Fields[i].args = args
+		}
+	}
+	return e
+}
+
+// Localize retourne une copie de l'erreur dont les messages sont traduits dans la langue demandée.
+func (e *Error) Localize(lang i18n.Lang) *Error {
+	localized := *e
+	localized.Message = i18n.T(lang, e.Message, e.args...)
+	localized.args = nil
+	localized.Fields = make([]FieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		field.Message = i18n.T(lang, field.Message, field.args...)
+		field.args = nil
+		localized.Fields[i] = field
+	}
+	if len(localized.Fields) == 0 {
+		localized.Fields = nil
+	}
+	return &localized
+}
+
+// Wrap renseigne la cause de l'erreur.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// BadRequest construit une erreur 400.
+func BadRequest(code Code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+// Invalid construit une erreur de validation portant sur un seul champ du corps de la requête.
+func Invalid(field, rule, message string) *Error {
+	return BadRequest(CodeValidationFailed, message).WithField(field, rule, message)
+}
+
+// InvalidParam construit une erreur 400 pour un paramètre de chemin ou de requête invalide.
+func InvalidParam(param, message string) *Error {
+	return BadRequest(CodeInvalidParameter, message).WithField(param, "invalid", message)
+}
+
+// Unauthorized construit une erreur 401.
+func Unauthorized(code Code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+// Forbidden construit une erreur 403 avec le code FORBIDDEN.
+func Forbidden() *Error {
+	return New(http.StatusForbidden, CodeForbidden, "Accès non autorisé")
+}
+
+// NotFound construit une erreur 404.
+func NotFound(code Code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+// Conflict construit une erreur 409.
+func Conflict(code Code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+// Internal construit une erreur 500. Le message reste générique ; la cause n'est pas exposée.
+func Internal(message string, cause error) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, message).Wrap(cause)
+}
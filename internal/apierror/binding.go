@@ -0,0 +1,80 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Les champs refusés sont désignés par leur nom JSON, celui qu'envoie le client
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName retourne le nom JSON d'un champ, ou "" (nom du champ Go) s'il n'a pas de tag json.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Binding traduit une erreur de c.ShouldBindJSON. Chaque règle de validation non respectée devient
+// un champ refusé ; un JSON illisible produit MALFORMED_BODY. Le message du validateur n'est pas exposé.
+func Binding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		apiErr := BadRequest(CodeValidationFailed, "Données invalides")
+		for _, fieldErr := range validationErrs {
+			message, args := ruleMessage(fieldErr)
+			apiErr.WithField(fieldErr.Field(), fieldErr.Tag(), message, args...)
+		}
+		return apiErr.Wrap(err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return Invalid(typeErr.Field, "type", "Type de valeur invalide").Wrap(err)
+	}
+	return BadRequest(CodeMalformedBody, "Mauvaise requête : un objet JSON est attendu").Wrap(err)
+}
+
+// ruleMessage décrit la règle de validation non respectée : un message du catalogue i18n
+// et les valeurs de ses verbes.
+func ruleMessage(fieldErr validator.FieldError) (string, []any) {
+	isText := fieldErr.Kind() == reflect.String
+	switch fieldErr.Tag() {
+	case "required":
+		return "Champ requis", nil
+	case "email":
+		return "Adresse email invalide", nil
+	case "oneof":
+		return "Valeur invalide : %s attendu", []any{strings.Join(strings.Fields(fieldErr.Param()), ", ")}
+	case "min":
+		if isText {
+			return "Au moins %s caractères attendus", []any{fieldErr.Param()}
+		}
+		return "Doit être supérieur ou égal à %s", []any{fieldErr.Param()}
+	case "max":
+		if isText {
+			return "Au plus %s caractères attendus", []any{fieldErr.Param()}
+		}
+		return "Doit être inférieur ou égal à %s", []any{fieldErr.Param()}
+	case "gte":
+		return "Doit être supérieur ou égal à %s", []any{fieldErr.Param()}
+	case "gt":
+		return "Doit être supérieur à %s", []any{fieldErr.Param()}
+	case "lte":
+		return "Doit être inférieur ou égal à %s", []any{fieldErr.Param()}
+	case "lt":
+		return "Doit être inférieur à %s", []any{fieldErr.Param()}
+	default:
+		return "Valeur invalide", nil
+	}
+}
@@ -0,0 +1,63 @@
+package apierror
+
+// Code identifie une erreur de l'API. Les codes sont stables : le frontend s'appuie sur eux
+// plutôt que sur les messages, destinés à l'utilisateur et susceptibles d'évoluer.
+type Code string
+
+// Requête invalide
+const (
+	CodeValidationFailed     Code = "VALIDATION_FAILED"      // Un ou plusieurs champs refusés (voir "fields")
+	CodeMalformedBody        Code = "MALFORMED_BODY"         // Corps de requête illisible
+	CodeInvalidParameter     Code = "INVALID_PARAMETER"      // Paramètre de chemin ou de requête invalide
+	CodePayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"      // Fichier ou nombre de lignes trop important
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE" // Format de fichier non pris en charge
+)
+
+// Authentification et autorisations
+const (
+	CodeUnauthenticated     Code = "UNAUTHENTICATED"       // Aucun utilisateur authentifié
+	CodeInvalidCredentials  Code = "INVALID_CREDENTIALS"   // Email ou mot de passe invalide
+	CodeTokenMissing        Code = "TOKEN_MISSING"         // En-tête Authorization absent
+	CodeTokenInvalid        Code = "TOKEN_INVALID"         // Token invalide ou expiré
+	CodeTokenRevoked        Code = "TOKEN_REVOKED"         // Token révoqué (déconnexion, changement de rôle)
+	CodeRefreshTokenInvalid Code = "REFRESH_TOKEN_INVALID" // Token de rafraîchissement invalide ou expiré
+	CodeForbidden           Code = "FORBIDDEN"             // Rôle ou propriétaire insuffisant
+	CodeOwnRole             Code = "OWN_ROLE"              // Un administrateur ne modifie pas son propre rôle
+)
+
+// Enregistrements absents
+const (
+	CodeRouteNotFound    Code = "ROUTE_NOT_FOUND"
+	CodeUserNotFound     Code = "USER_NOT_FOUND"
+	CodeResourceNotFound Code = "RESOURCE_NOT_FOUND"
+	CodeCopyNotFound     Code = "COPY_NOT_FOUND"
+	CodeLoanNotFound     Code = "LOAN_NOT_FOUND"
+	CodeHoldNotFound     Code = "HOLD_NOT_FOUND"
+	CodePolicyNotFound   Code = "POLICY_NOT_FOUND"
+)
+
+// Conflits avec l'état courant
+const (
+	CodeEmailTaken           Code = "EMAIL_TAKEN"
+	CodeBarcodeTaken         Code = "BARCODE_TAKEN"
+	CodeISBNTaken            Code = "ISBN_TAKEN"
+	CodePolicyExists         Code = "POLICY_EXISTS"
+	CodeInvalidTransition    Code = "INVALID_TRANSITION"   // Changement de statut interdit (voir "details")
+	CodeResourceUnavailable  Code = "RESOURCE_UNAVAILABLE" // Aucun exemplaire ne peut être emprunté
+	CodeResourceAvailable    Code = "RESOURCE_AVAILABLE"   // Réservation inutile : un exemplaire est disponible
+	CodeCopiesOnLoan         Code = "COPIES_ON_LOAN"       // Suppression impossible tant que des exemplaires sont prêtés
+	CodeAlreadyBorrowed      Code = "ALREADY_BORROWED"     // L'adhérent emprunte déjà la ressource
+	CodeHoldExists           Code = "HOLD_EXISTS"          // L'adhérent a déjà une réservation active
+	CodeHoldPending          Code = "HOLD_PENDING"         // La ressource est réservée par un autre adhérent
+	CodeBorrowTypeNotAllowed Code = "BORROW_TYPE_NOT_ALLOWED"
+	CodeLoanLimitReached     Code = "LOAN_LIMIT_REACHED"
+	CodeFinesUnpaid          Code = "FINES_UNPAID"
+	CodeLoanAlreadyReturned  Code = "LOAN_ALREADY_RETURNED"
+	CodeLoanOverdue          Code = "LOAN_OVERDUE"
+	CodeLoanNotRenewable     Code = "LOAN_NOT_RENEWABLE"
+	CodeRenewalLimitReached  Code = "RENEWAL_LIMIT_REACHED"
+	CodeAmountExceedsBalance Code = "AMOUNT_EXCEEDS_BALANCE"
+)
+
+// CodeInternal signale une erreur du serveur ; sa cause n'est jamais exposée au client.
+const CodeInternal Code = "INTERNAL_ERROR"
@@ -0,0 +1,57 @@
+// Package app assemble l'application : configuration, connexion à la base, horloge et journal,
+// transmises au routeur et aux tâches de fond plutôt que partagées par des variables globales.
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/database"
+	"awesomeProject/internal/jobs"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// App regroupe les dépendances partagées par les commandes du serveur.
+type App struct {
+	Config *config.Config
+	DB     *gorm.DB
+	Clock  clock.Clock
+	Logger *logging.Logger
+}
+
+// New crée le journal (sur la sortie standard) et ouvre la base de données décrite par la configuration.
+// Le journal devient aussi celui par défaut de slog.
+func New(cfg *config.Config) (*App, error) {
+	logger, err := logging.New(os.Stdout, cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger.Logger)
+
+	db, err := database.InitDB(cfg.Database, logger.Gorm())
+	if err != nil {
+		return nil, err
+	}
+	return &App{Config: cfg, DB: db, Clock: clock.Real(), Logger: logger}, nil
+}
+
+// Router construit le routeur HTTP de l'application.
+func (a *App) Router() *gin.Engine {
+	return routes.NewRouter(a.Config, a.DB, a.Clock, a.Logger)
+}
+
+// StartJobs lance les tâches de fond (détection des retards et amendes) jusqu'à l'annulation de ctx.
+func (a *App) StartJobs(ctx context.Context) {
+	go jobs.NewOverdueJob(a.DB, a.Clock, a.Config.Loans, a.Logger).Run(ctx)
+}
+
+// Close ferme la connexion à la base de données.
+func (a *App) Close() error {
+	return database.CloseDB(a.DB)
+}
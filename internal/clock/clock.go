@@ -0,0 +1,53 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock fournit l'heure courante. Les tests injectent une horloge contrôlée
+// pour simuler l'écoulement des jours sans attendre.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// Real retourne l'horloge système.
+func Real() Clock {
+	return realClock{}
+}
+
+// Fake est une horloge manuelle, sûre pour un usage concurrent.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake crée une horloge manuelle positionnée à now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now retourne l'heure de l'horloge.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set positionne l'horloge à now.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance avance l'horloge de d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
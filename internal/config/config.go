@@ -0,0 +1,383 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environnements d'exécution
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+// Moteurs de base de données pris en charge
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Formats des journaux
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// DefaultJWTSecret est le secret utilisé par défaut en développement.
+// Le serveur refuse de démarrer en production avec cette valeur.
+const DefaultJWTSecret = "your_jwt_secret"
+
+// Config regroupe l'ensemble des paramètres de l'application.
+type Config struct {
+	Env      string         `yaml:"env"` // "development", "production" ou "test"
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Loans    LoanConfig     `yaml:"loans"`
+	CORS     CORSConfig     `yaml:"cors"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+// ServerConfig contient les paramètres du serveur HTTP.
+type ServerConfig struct {
+	Addr string `yaml:"addr"` // Adresse d'écoute, par exemple ":8080"
+}
+
+// DatabaseConfig contient les paramètres de connexion à la base de données.
+type DatabaseConfig struct {
+	Driver      string `yaml:"driver"` // "sqlite" ou "postgres"
+	DSN         string `yaml:"dsn"`
+	AutoMigrate bool   `yaml:"auto_migrate"` // Applique les migrations en attente au démarrage du serveur
+}
+
+// AuthConfig contient les paramètres des tokens d'authentification.
+type AuthConfig struct {
+	JWTSecret       string        `yaml:"jwt_secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`  // Token d'accès JWT, volontairement court
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"` // Token de rafraîchissement, renouvelé à chaque utilisation
+}
+
+// LoanConfig contient les règles de prêt et de réservation.
+// Les règles de prêt servent de valeurs par défaut quand aucune politique de prêt
+// n'est enregistrée pour un type de ressource et un type d'emprunt.
+type LoanConfig struct {
+	LoanPeriod      time.Duration `yaml:"loan_period"`       // Durée d'un emprunt à emporter
+	HoldPickupDelay time.Duration `yaml:"hold_pickup_delay"` // Délai de retrait d'une réservation prête avant de passer au suivant
+	RenewalPeriod   time.Duration `yaml:"renewal_period"`    // Prolongation de la date de retour à chaque renouvellement
+	MaxRenewals     int           `yaml:"max_renewals"`      // Nombre maximal de renouvellements d'un prêt
+
+	// Amendes de retard, en centimes
+	FineDailyRate      int           `yaml:"fine_daily_rate"`      // Montant par jour de retard entamé
+	FineCap            int           `yaml:"fine_cap"`             // Plafond de l'amende d'un prêt
+	FineBlockThreshold int           `yaml:"fine_block_threshold"` // Au-delà de ce solde dû, les nouveaux prêts sont refusés
+	OverdueInterval    time.Duration `yaml:"overdue_interval"`     // Fréquence de la tâche de détection des retards
+}
+
+// CORSConfig contient les origines autorisées à appeler l'API.
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allow_origins"`
+}
+
+// LogConfig contient les paramètres des journaux, écrits sur la sortie standard.
+// Les niveaux valent "debug", "info", "warn" ou "error".
+type LogConfig struct {
+	Level      string `yaml:"level"`       // Niveau minimal des messages journalisés
+	Format     string `yaml:"format"`      // "json" (par défaut) ou "text"
+	EventLevel string `yaml:"event_level"` // Niveau des événements du domaine : prêt créé, échec de connexion...
+}
+
+// Default retourne la configuration de développement.
+func Default() *Config {
+	return &Config{
+		Env:      EnvDevelopment,
+		Server:   ServerConfig{Addr: ":8080"},
+		Database: DatabaseConfig{Driver: DriverSQLite, DSN: "file:database.db?cache=shared&_fk=1", AutoMigrate: true},
+		Auth: AuthConfig{
+			JWTSecret:       DefaultJWTSecret,
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Loans: LoanConfig{
+			LoanPeriod:      15 * 24 * time.Hour,
+			HoldPickupDelay: 3 * 24 * time.Hour,
+			RenewalPeriod:   15 * 24 * time.Hour,
+			MaxRenewals:     2,
+
+			FineDailyRate:      20,
+			FineCap:            1000,
+			FineBlockThreshold: 500,
+			OverdueInterval:    time.Hour,
+		},
+		CORS: CORSConfig{AllowOrigins: []string{"http://localhost:5173"}}, // Autorise le frontend en dev
+		Log:  LogConfig{Level: "info", Format: LogFormatJSON, EventLevel: "info"},
+	}
+}
+
+// Load construit la configuration à partir des valeurs par défaut, du fichier YAML
+// désigné par CONFIG_FILE (optionnel) puis des variables d'environnement, et la valide.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile complète la configuration avec le contenu d'un fichier YAML.
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("lecture du fichier de configuration %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("analyse du fichier de configuration %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv surcharge la configuration avec les variables d'environnement définies.
+func (cfg *Config) loadEnv() error {
+	if value, ok := os.LookupEnv("APP_ENV"); ok {
+		cfg.Env = value
+	}
+	if value, ok := os.LookupEnv("PORT"); ok {
+		cfg.Server.Addr = ":" + value
+	}
+	if value, ok := os.LookupEnv("SERVER_ADDR"); ok {
+		cfg.Server.Addr = value
+	}
+	if value, ok := os.LookupEnv("DATABASE_DRIVER"); ok {
+		cfg.Database.Driver = value
+	}
+	if value, ok := os.LookupEnv("DATABASE_DSN"); ok {
+		cfg.Database.DSN = value
+	}
+	if err := lookupBool("DATABASE_AUTO_MIGRATE", &cfg.Database.AutoMigrate); err != nil {
+		return err
+	}
+	if value, ok := os.LookupEnv("JWT_SECRET"); ok {
+		cfg.Auth.JWTSecret = value
+	}
+	if err := lookupDuration("ACCESS_TOKEN_TTL", &cfg.Auth.AccessTokenTTL); err != nil {
+		return err
+	}
+	if err := lookupDuration("REFRESH_TOKEN_TTL", &cfg.Auth.RefreshTokenTTL); err != nil {
+		return err
+	}
+	if err := lookupDuration("LOAN_PERIOD", &cfg.Loans.LoanPeriod); err != nil {
+		return err
+	}
+	if err := lookupDuration("HOLD_PICKUP_DELAY", &cfg.Loans.HoldPickupDelay); err != nil {
+		return err
+	}
+	if err := lookupDuration("LOAN_RENEWAL_PERIOD", &cfg.Loans.RenewalPeriod); err != nil {
+		return err
+	}
+	if err := lookupInt("LOAN_MAX_RENEWALS", &cfg.Loans.MaxRenewals); err != nil {
+		return err
+	}
+	if err := lookupInt("FINE_DAILY_RATE", &cfg.Loans.FineDailyRate); err != nil {
+		return err
+	}
+	if err := lookupInt("FINE_CAP", &cfg.Loans.FineCap); err != nil {
+		return err
+	}
+	if err := lookupInt("FINE_BLOCK_THRESHOLD", &cfg.Loans.FineBlockThreshold); err != nil {
+		return err
+	}
+	if err := lookupDuration("OVERDUE_INTERVAL", &cfg.Loans.OverdueInterval); err != nil {
+		return err
+	}
+	if value, ok := os.LookupEnv("CORS_ALLOW_ORIGINS"); ok {
+		cfg.CORS.AllowOrigins = splitList(value)
+	}
+	if value, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		cfg.Log.Level = value
+	}
+	if value, ok := os.LookupEnv("LOG_FORMAT"); ok {
+		cfg.Log.Format = value
+	}
+	if value, ok := os.LookupEnv("LOG_EVENT_LEVEL"); ok {
+		cfg.Log.EventLevel = value
+	}
+	return nil
+}
+
+// Validate vérifie la cohérence de la configuration.
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	switch cfg.Env {
+	case EnvDevelopment, EnvProduction, EnvTest:
+	default:
+		errs = append(errs, fmt.Errorf("env: valeur inconnue %q", cfg.Env))
+	}
+
+	if cfg.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr: valeur requise"))
+	}
+	switch cfg.Database.Driver {
+	case DriverSQLite, DriverPostgres:
+	default:
+		errs = append(errs, fmt.Errorf("database.driver: valeur inconnue %q", cfg.Database.Driver))
+	}
+	if cfg.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn: valeur requise"))
+	}
+
+	if cfg.Auth.JWTSecret == "" {
+		errs = append(errs, errors.New("auth.jwt_secret: valeur requise"))
+	} else if cfg.IsProduction() {
+		if cfg.Auth.JWTSecret == DefaultJWTSecret {
+			errs = append(errs, errors.New("auth.jwt_secret: le secret par défaut est interdit en production"))
+		} else if len(cfg.Auth.JWTSecret) < 32 {
+			errs = append(errs, errors.New("auth.jwt_secret: au moins 32 caractères sont requis en production"))
+		}
+	}
+	if cfg.Auth.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("auth.access_token_ttl: doit être positif"))
+	}
+	if cfg.Auth.RefreshTokenTTL <= cfg.Auth.AccessTokenTTL {
+		errs = append(errs, errors.New("auth.refresh_token_ttl: doit être supérieur à auth.access_token_ttl"))
+	}
+
+	if cfg.Loans.LoanPeriod < 24*time.Hour {
+		errs = append(errs, errors.New("loans.loan_period: au moins un jour est requis"))
+	}
+	if cfg.Loans.HoldPickupDelay <= 0 {
+		errs = append(errs, errors.New("loans.hold_pickup_delay: doit être positif"))
+	}
+	if cfg.Loans.RenewalPeriod < 24*time.Hour {
+		errs = append(errs, errors.New("loans.renewal_period: au moins un jour est requis"))
+	}
+	if cfg.Loans.MaxRenewals < 0 {
+		errs = append(errs, errors.New("loans.max_renewals: ne peut pas être négatif"))
+	}
+	if cfg.Loans.FineDailyRate < 0 || cfg.Loans.FineCap < 0 || cfg.Loans.FineBlockThreshold < 0 {
+		errs = append(errs, errors.New("loans.fine_*: les montants ne peuvent pas être négatifs"))
+	}
+	if cfg.Loans.OverdueInterval <= 0 {
+		errs = append(errs, errors.New("loans.overdue_interval: doit être positif"))
+	}
+
+	if len(cfg.CORS.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allow_origins: au moins une origine est requise"))
+	}
+	for _, origin := range cfg.CORS.AllowOrigins {
+		// Les cookies et en-têtes d'authentification sont autorisés : "*" n'est pas accepté
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Errorf("cors.allow_origins: origine invalide %q", origin))
+		}
+	}
+
+	if _, err := ParseLevel(cfg.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	if _, err := ParseLevel(cfg.Log.EventLevel); err != nil {
+		errs = append(errs, fmt.Errorf("log.event_level: %w", err))
+	}
+	switch cfg.Log.Format {
+	case LogFormatJSON, LogFormatText:
+	default:
+		errs = append(errs, fmt.Errorf("log.format: valeur inconnue %q", cfg.Log.Format))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuration invalide: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// IsProduction indique si l'application tourne en production.
+func (cfg *Config) IsProduction() bool {
+	return cfg.Env == EnvProduction
+}
+
+// ParseLevel lit un niveau de journalisation : "debug", "info", "warn" ou "error".
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	switch strings.ToLower(value) {
+	case "debug", "info", "warn", "error":
+		return level, level.UnmarshalText([]byte(value))
+	}
+	return level, fmt.Errorf("niveau inconnu %q", value)
+}
+
+// lookupDuration lit une durée (par exemple "15m") depuis une variable d'environnement.
+func lookupDuration(name string, target *time.Duration) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: durée invalide %q", name, value)
+	}
+	*target = duration
+	return nil
+}
+
+// lookupInt lit un entier depuis une variable d'environnement.
+func lookupInt(name string, target *int) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: entier invalide %q", name, value)
+	}
+	*target = parsed
+	return nil
+}
+
+// lookupBool lit un booléen (par exemple "true" ou "0") depuis une variable d'environnement.
+func lookupBool(name string, target *bool) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s: booléen invalide %q", name, value)
+	}
+	*target = parsed
+	return nil
+}
+
+// splitList découpe une liste séparée par des virgules en ignorant les éléments vides.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
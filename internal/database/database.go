@@ -2,42 +2,64 @@ package database
 
 import (
 	"database/sql"
-	_ "database/sql"
+	"fmt"
+
+	"awesomeProject/internal/config"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"log"
+	gormlogger "gorm.io/gorm/logger"
 
 	// Import nécessaire pour enregistrer le driver modernc
 	_ "modernc.org/sqlite"
 )
 
-var DB *gorm.DB
-
-func InitDB() {
-	// On définit ici le DSN (Data Source Name). Le paramètre "_fk=1" permet d'activer les clés étrangères.
-	sqlDB, err := sql.Open("sqlite", "file:database.db?cache=shared&_fk=1")
-	if err != nil {
-		log.Fatalf("Erreur lors de l'ouverture de la connexion SQL: %v", err)
+// InitDB ouvre la connexion à la base de données décrite par la configuration.
+// Avec SQLite, le DSN (Data Source Name) désigne un fichier, par exemple "file:database.db?cache=shared&_fk=1",
+// où "_fk=1" permet d'activer les clés étrangères. Avec PostgreSQL, il suit le format de pgx,
+// par exemple "host=localhost user=bibliotheque dbname=bibliotheque sslmode=disable".
+// La connexion retournée est transmise aux handlers ; elle se ferme avec CloseDB.
+// Les messages de GORM (requêtes lentes, erreurs SQL) sont écrits dans logger.
+func InitDB(cfg config.DatabaseConfig, logger gormlogger.Interface) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	var sqlDB *sql.DB
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		var err error
+		sqlDB, err = sql.Open("sqlite", cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("ouverture de la connexion SQL: %w", err)
+		}
+		// Une seule connexion : les transactions s'exécutent l'une après l'autre. SQLite n'a pas de verrou
+		// de ligne (SELECT ... FOR UPDATE) ; sans cela, deux transactions pourraient lire le même état
+		// avant que l'une d'elles n'écrive, et les vérifications faites sous verrou seraient faussées.
+		sqlDB.SetMaxOpenConns(1)
+		// Initialisation de GORM en utilisant la connexion existante.
+		dialector = sqlite.Dialector{Conn: sqlDB}
+	case config.DriverPostgres:
+		dialector = postgres.Open(cfg.DSN)
+	default:
+		return nil, fmt.Errorf("moteur de base de données inconnu %q", cfg.Driver)
 	}
 
-	// Initialisation de GORM en utilisant la connexion existante.
-	DB, err = gorm.Open(sqlite.Dialector{
-		Conn: sqlDB,
-	}, &gorm.Config{})
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger})
 	if err != nil {
-		log.Fatalf("Erreur lors de l'initialisation de GORM: %v", err)
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+		return nil, fmt.Errorf("initialisation de GORM: %w", err)
 	}
-
-
+	return db, nil
 }
 
-// CloseDB permet de fermer proprement la connexion à la base de données.
-func CloseDB() {
-	sqlDB, err := DB.DB()
+// CloseDB ferme proprement la connexion à la base de données.
+func CloseDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatalf("Erreur lors de la récupération du sql.DB: %v", err)
+		return fmt.Errorf("récupération du sql.DB: %w", err)
 	}
 	if err := sqlDB.Close(); err != nil {
-		log.Fatalf("Erreur lors de la fermeture de la base de données: %v", err)
+		return fmt.Errorf("fermeture de la base de données: %w", err)
 	}
-}
\ No newline at end of file
+	return nil
+}
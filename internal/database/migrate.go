@@ -0,0 +1,183 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration décrit une évolution versionnée du schéma de la base de données.
+// Up applique l'évolution et Down l'annule ; chacune est exécutée dans une transaction.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// migrations contient les migrations connues, enregistrées par les fichiers migration_*.go.
+var migrations []Migration
+
+// register ajoute une migration à la liste des migrations connues.
+func register(migration Migration) {
+	migrations = append(migrations, migration)
+}
+
+// SchemaMigration enregistre une migration appliquée dans la table "schema_migrations".
+type SchemaMigration struct {
+	Version   uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+// TableName force le nom de la table de suivi des migrations.
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationState indique si une migration connue a été appliquée.
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// sortedMigrations retourne les migrations triées par version croissante.
+func sortedMigrations() []Migration {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+// appliedMigrations retourne les migrations déjà appliquées, indexées par version.
+func appliedMigrations(db *gorm.DB) (map[uint]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("création de la table schema_migrations: %w", err)
+	}
+
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("lecture de la table schema_migrations: %w", err)
+	}
+
+	applied := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Migrate applique, dans l'ordre, toutes les migrations qui ne l'ont pas encore été.
+// Elle retourne le nombre de migrations appliquées.
+func Migrate(db *gorm.DB) (int, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range sortedMigrations() {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Rollback annule les "steps" dernières migrations appliquées, de la plus récente à la plus ancienne.
+// Elle retourne le nombre de migrations annulées.
+func Rollback(db *gorm.DB, steps int) (int, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+
+	sorted := sortedMigrations()
+	count := 0
+	for i := len(sorted) - 1; i >= 0 && count < steps; i-- {
+		migration := sorted[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return count, fmt.Errorf("annulation de la migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status retourne l'état de toutes les migrations connues, triées par version.
+func Status(db *gorm.DB) ([]MigrationState, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []MigrationState
+	for _, migration := range sortedMigrations() {
+		state := MigrationState{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			state.AppliedAt = &appliedAt
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// addColumn ajoute une colonne si elle n'existe pas déjà (bases créées avant les migrations par AutoMigrate).
+func addColumn(tx *gorm.DB, model interface{}, field string) error {
+	if tx.Migrator().HasColumn(model, field) {
+		return nil
+	}
+	return tx.Migrator().AddColumn(model, field)
+}
+
+// ensureIndex crée l'index d'un champ s'il n'existe pas. SQLite recrée la table pour supprimer
+// une colonne ou modifier une contrainte, ce qui supprime ses index.
+func ensureIndex(tx *gorm.DB, model interface{}, field string) error {
+	if tx.Migrator().HasIndex(model, field) {
+		return nil
+	}
+	return tx.Migrator().CreateIndex(model, field)
+}
+
+// isPostgres indique si la migration s'exécute sur PostgreSQL plutôt que sur SQLite.
+func isPostgres(tx *gorm.DB) bool {
+	return tx.Dialector.Name() == "postgres"
+}
+
+// execAll exécute les instructions dans l'ordre.
+func execAll(tx *gorm.DB, statements []string) error {
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Les structures ci-dessous figent le schéma initial : les modèles de internal/models
+// évoluent, mais une migration doit toujours produire le même résultat.
+
+type userV1 struct {
+	ID       uint     `gorm:"primaryKey"`
+	Name     string   `gorm:"not null"`
+	Email    string   `gorm:"unique;not null"`
+	Password string   `gorm:"not null"`
+	Loans    []loanV1 `gorm:"foreignKey:UserID"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type resourceV1 struct {
+	ID     uint     `gorm:"primaryKey"`
+	Title  string   `gorm:"not null"`
+	Type   string   `gorm:"not null"`
+	Status string   `gorm:"default:disponible"`
+	Loans  []loanV1 `gorm:"foreignKey:ResourceID"`
+}
+
+func (resourceV1) TableName() string { return "resources" }
+
+type loanV1 struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"not null"`
+	ResourceID uint      `gorm:"not null"`
+	LoanDate   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	ReturnDate time.Time `gorm:"not null"`
+	Status     string    `gorm:"default:en_cours"`
+}
+
+func (loanV1) TableName() string { return "loans" }
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "create_initial_schema",
+		// Les bases existantes créées par AutoMigrate sont conservées telles quelles
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&userV1{}, &resourceV1{}, &loanV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&loanV1{}, &resourceV1{}, &userV1{})
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package database
+
+import "gorm.io/gorm"
+
+type userV2 struct {
+	Role string `gorm:"not null;default:member"`
+}
+
+func (userV2) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "add_user_roles",
+		// Les utilisateurs existants deviennent des adhérents
+		Up: func(tx *gorm.DB) error {
+			return addColumn(tx, &userV2{}, "Role")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&userV2{}, "Role")
+		},
+	})
+}
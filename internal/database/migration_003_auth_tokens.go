@@ -0,0 +1,51 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userV3 struct {
+	TokensRevokedAt *time.Time
+}
+
+func (userV3) TableName() string { return "users" }
+
+type refreshTokenV3 struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index"`
+	TokenHash    string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	RevokedAt    *time.Time
+	ReplacedByID *uint
+	CreatedAt    time.Time
+}
+
+func (refreshTokenV3) TableName() string { return "refresh_tokens" }
+
+type revokedTokenV3 struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (revokedTokenV3) TableName() string { return "revoked_tokens" }
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "create_auth_tokens",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumn(tx, &userV3{}, "TokensRevokedAt"); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&refreshTokenV3{}, &revokedTokenV3{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&revokedTokenV3{}, &refreshTokenV3{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&userV3{}, "TokensRevokedAt")
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package database
+
+import "gorm.io/gorm"
+
+type resourceV4 struct {
+	ID     uint
+	Status string `gorm:"default:disponible;check:chk_resources_status,status IN ('disponible','indisponible','emprunté')"`
+}
+
+func (resourceV4) TableName() string { return "resources" }
+
+type loanV4 struct {
+	ID     uint
+	Status string `gorm:"default:en_cours;check:chk_loans_status,status IN ('en_cours','retourné')"`
+}
+
+func (loanV4) TableName() string { return "loans" }
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "add_status_check_constraints",
+		// SQLite ne sait pas ajouter de contrainte à une table existante : GORM recrée la table
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateConstraint(&resourceV4{}, "chk_resources_status"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateConstraint(&loanV4{}, "chk_loans_status")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropConstraint(&loanV4{}, "chk_loans_status"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropConstraint(&resourceV4{}, "chk_resources_status")
+		},
+	})
+}
@@ -0,0 +1,67 @@
+package database
+
+import "gorm.io/gorm"
+
+type resourceRefV5 struct {
+	ID uint
+}
+
+func (resourceRefV5) TableName() string { return "resources" }
+
+type copyV5 struct {
+	ID         uint          `gorm:"primaryKey"`
+	ResourceID uint          `gorm:"not null;index"`
+	Resource   resourceRefV5 `gorm:"foreignKey:ResourceID"`
+	Barcode    string        `gorm:"uniqueIndex;not null"`
+	Location   string
+	Condition  string `gorm:"not null;default:bon"`
+	Status     string `gorm:"not null;default:disponible;check:chk_copies_status,status IN ('disponible','indisponible','emprunté')"`
+}
+
+func (copyV5) TableName() string { return "copies" }
+
+type loanV5 struct {
+	CopyID uint `gorm:"index"`
+}
+
+func (loanV5) TableName() string { return "loans" }
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "create_copies",
+		// Chaque ressource existante devient une œuvre avec un exemplaire unique,
+		// auquel sont rattachés ses prêts.
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&copyV5{}); err != nil {
+				return err
+			}
+			barcode := "printf('R%05d-1', id)"
+			if isPostgres(tx) {
+				barcode = "'R' || lpad(id::text, 5, '0') || '-1'"
+			}
+			if err := tx.Exec(`INSERT INTO copies (resource_id, barcode, condition, status)
+				SELECT id, ` + barcode + `, 'bon', COALESCE(status, 'disponible') FROM resources`).Error; err != nil {
+				return err
+			}
+
+			if err := addColumn(tx, &loanV5{}, "CopyID"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().CreateIndex(&loanV5{}, "CopyID"); err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE loans SET copy_id = (
+				SELECT MIN(copies.id) FROM copies WHERE copies.resource_id = loans.resource_id)`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&loanV5{}, "CopyID"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&loanV5{}, "CopyID"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&copyV5{})
+		},
+	})
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type resourceV6 struct {
+	ID     uint
+	Status string `gorm:"default:disponible;check:chk_resources_status,status IN ('disponible','indisponible','emprunté','réservé')"`
+}
+
+func (resourceV6) TableName() string { return "resources" }
+
+type copyV6 struct {
+	ID         uint   `gorm:"primaryKey"`
+	ResourceID uint   `gorm:"not null;index"`
+	Barcode    string `gorm:"uniqueIndex;not null"`
+	Status     string `gorm:"not null;default:disponible;check:chk_copies_status,status IN ('disponible','indisponible','emprunté','réservé')"`
+}
+
+func (copyV6) TableName() string { return "copies" }
+
+type holdV6 struct {
+	ID             uint          `gorm:"primaryKey"`
+	UserID         uint          `gorm:"not null;index"`
+	ResourceID     uint          `gorm:"not null;index"`
+	Resource       resourceRefV5 `gorm:"foreignKey:ResourceID"`
+	CopyID         *uint
+	Status         string `gorm:"not null;default:en_attente;check:chk_holds_status,status IN ('en_attente','prête','honorée','annulée','expirée')"`
+	CreatedAt      time.Time
+	ReadyAt        *time.Time
+	PickupDeadline *time.Time
+}
+
+func (holdV6) TableName() string { return "holds" }
+
+// replaceCheck remplace une contrainte CHECK. SQLite recrée alors la table sans ses index :
+// ceux déclarés par le modèle sont reconstruits.
+func replaceCheck(tx *gorm.DB, model interface{}, name string, indexes ...string) error {
+	if err := tx.Migrator().DropConstraint(model, name); err != nil {
+		return err
+	}
+	if err := tx.Migrator().CreateConstraint(model, name); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if err := ensureIndex(tx, model, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "create_holds",
+		// Un exemplaire rendu alors qu'une réservation attend passe au statut "réservé"
+		Up: func(tx *gorm.DB) error {
+			if err := replaceCheck(tx, &resourceV6{}, "chk_resources_status"); err != nil {
+				return err
+			}
+			if err := replaceCheck(tx, &copyV6{}, "chk_copies_status", "ResourceID", "Barcode"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&holdV6{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&holdV6{}); err != nil {
+				return err
+			}
+			// Les exemplaires mis de côté redeviennent disponibles
+			if err := tx.Exec("UPDATE copies SET status = 'disponible' WHERE status = 'réservé'").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE resources SET status = 'disponible' WHERE status = 'réservé'").Error; err != nil {
+				return err
+			}
+			if err := replaceCheck(tx, &copyV5{}, "chk_copies_status", "ResourceID", "Barcode"); err != nil {
+				return err
+			}
+			return replaceCheck(tx, &resourceV4{}, "chk_resources_status")
+		},
+	})
+}
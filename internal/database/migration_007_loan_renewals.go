@@ -0,0 +1,64 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type loanV7 struct {
+	BorrowType   string `gorm:"not null;default:a_emporter"`
+	RenewalCount int    `gorm:"not null;default:0"`
+}
+
+func (loanV7) TableName() string { return "loans" }
+
+type loanRefV7 struct {
+	ID uint
+}
+
+func (loanRefV7) TableName() string { return "loans" }
+
+type loanRenewalV7 struct {
+	ID                 uint      `gorm:"primaryKey"`
+	LoanID             uint      `gorm:"not null;index"`
+	Loan               loanRefV7 `gorm:"foreignKey:LoanID"`
+	RenewedAt          time.Time `gorm:"not null"`
+	PreviousReturnDate time.Time `gorm:"not null"`
+	NewReturnDate      time.Time `gorm:"not null"`
+	RenewedByID        uint
+}
+
+func (loanRenewalV7) TableName() string { return "loan_renewals" }
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "add_loan_renewals",
+		// Le type d'emprunt n'était pas enregistré : un prêt rendu le jour même était sur place
+		Up: func(tx *gorm.DB) error {
+			if err := addColumn(tx, &loanV7{}, "BorrowType"); err != nil {
+				return err
+			}
+			if err := addColumn(tx, &loanV7{}, "RenewalCount"); err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE loans SET borrow_type = 'sur_place' WHERE return_date = loan_date").Error; err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&loanRenewalV7{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&loanRenewalV7{}); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&loanV7{}, "RenewalCount"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&loanV7{}, "BorrowType"); err != nil {
+				return err
+			}
+			return ensureIndex(tx, &loanV5{}, "CopyID")
+		},
+	})
+}
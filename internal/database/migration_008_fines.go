@@ -0,0 +1,61 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type loanV8 struct {
+	ID     uint
+	CopyID uint   `gorm:"index"`
+	Status string `gorm:"default:en_cours;check:chk_loans_status,status IN ('en_cours','en_retard','retourné')"`
+}
+
+func (loanV8) TableName() string { return "loans" }
+
+type userRefV8 struct {
+	ID uint
+}
+
+func (userRefV8) TableName() string { return "users" }
+
+type fineEntryV8 struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"not null;index"`
+	User        userRefV8 `gorm:"foreignKey:UserID"`
+	LoanID      *uint     `gorm:"index"`
+	Kind        string    `gorm:"not null;check:chk_fine_entries_kind,kind IN ('amende','paiement','remise')"`
+	Amount      int       `gorm:"not null"`
+	Note        string
+	CreatedByID *uint
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+func (fineEntryV8) TableName() string { return "fine_entries" }
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "create_fines",
+		Up: func(tx *gorm.DB) error {
+			if err := replaceCheck(tx, &loanV8{}, "chk_loans_status", "CopyID"); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&fineEntryV8{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&fineEntryV8{}); err != nil {
+				return err
+			}
+			// Les prêts en retard redeviennent de simples prêts en cours
+			if err := tx.Exec("UPDATE loans SET status = 'en_cours' WHERE status = 'en_retard'").Error; err != nil {
+				return err
+			}
+			if err := replaceCheck(tx, &loanV4{}, "chk_loans_status"); err != nil {
+				return err
+			}
+			return ensureIndex(tx, &loanV8{}, "CopyID")
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package database
+
+import "gorm.io/gorm"
+
+type loanPolicyV9 struct {
+	ID                 uint   `gorm:"primaryKey"`
+	ResourceType       string `gorm:"not null;uniqueIndex:idx_loan_policies_type_borrow"`
+	BorrowType         string `gorm:"not null;uniqueIndex:idx_loan_policies_type_borrow;check:chk_loan_policies_borrow_type,borrow_type IN ('sur_place','a_emporter')"`
+	Allowed            bool   `gorm:"not null;default:true"`
+	LoanDays           int    `gorm:"not null;default:0"`
+	MaxConcurrentLoans int    `gorm:"not null;default:0"`
+	MaxRenewals        int    `gorm:"not null;default:0"`
+	RenewalDays        int    `gorm:"not null;default:0"`
+	FineDailyRate      int    `gorm:"not null;default:0"`
+	FineCap            int    `gorm:"not null;default:0"`
+}
+
+func (loanPolicyV9) TableName() string { return "loan_policies" }
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "create_loan_policies",
+		// Sans politique enregistrée, les règles de la configuration s'appliquent
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&loanPolicyV9{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&loanPolicyV9{})
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package database
+
+import "gorm.io/gorm"
+
+// resourcesFTSUp crée l'index plein texte du catalogue. Le tokenizer unicode61 ignore la casse et,
+// avec remove_diacritics, les accents : « etranger » trouve « L'Étranger ».
+// L'index ne stocke pas les titres (content=resources) ; les triggers le tiennent à jour.
+var resourcesFTSUp = []string{
+	`CREATE VIRTUAL TABLE resources_fts USING fts5(
+		title,
+		content='resources', content_rowid='id',
+		tokenize="unicode61 remove_diacritics 2"
+	)`,
+	`CREATE TRIGGER resources_fts_insert AFTER INSERT ON resources BEGIN
+		INSERT INTO resources_fts(rowid, title) VALUES (new.id, new.title);
+	END`,
+	`CREATE TRIGGER resources_fts_delete AFTER DELETE ON resources BEGIN
+		INSERT INTO resources_fts(resources_fts, rowid, title) VALUES ('delete', old.id, old.title);
+	END`,
+	`CREATE TRIGGER resources_fts_update AFTER UPDATE OF title ON resources BEGIN
+		INSERT INTO resources_fts(resources_fts, rowid, title) VALUES ('delete', old.id, old.title);
+		INSERT INTO resources_fts(rowid, title) VALUES (new.id, new.title);
+	END`,
+	// Indexer le catalogue existant
+	`INSERT INTO resources_fts(resources_fts) VALUES ('rebuild')`,
+}
+
+// resourcesSearchUp est l'équivalent PostgreSQL : la configuration resources_search découpe les titres
+// comme "simple" (sans racinisation ni mots vides) et retire les accents avec l'extension unaccent.
+// Un index GIN sur l'expression remplace la table virtuelle ; aucun trigger n'est nécessaire.
+var resourcesSearchUp = []string{
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`CREATE TEXT SEARCH CONFIGURATION resources_search (COPY = simple)`,
+	`ALTER TEXT SEARCH CONFIGURATION resources_search
+		ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple`,
+	`CREATE INDEX idx_resources_search ON resources USING GIN (to_tsvector('resources_search', title))`,
+}
+
+// L'extension unaccent est conservée : d'autres schémas de la base peuvent l'utiliser.
+var resourcesSearchDown = []string{
+	`DROP INDEX IF EXISTS idx_resources_search`,
+	`DROP TEXT SEARCH CONFIGURATION IF EXISTS resources_search`,
+}
+
+var resourcesFTSDown = []string{
+	`DROP TRIGGER IF EXISTS resources_fts_update`,
+	`DROP TRIGGER IF EXISTS resources_fts_delete`,
+	`DROP TRIGGER IF EXISTS resources_fts_insert`,
+	`DROP TABLE IF EXISTS resources_fts`,
+}
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "create_resources_fts",
+		Up: func(tx *gorm.DB) error {
+			if isPostgres(tx) {
+				return execAll(tx, resourcesSearchUp)
+			}
+			return execAll(tx, resourcesFTSUp)
+		},
+		Down: func(tx *gorm.DB) error {
+			if isPostgres(tx) {
+				return execAll(tx, resourcesSearchDown)
+			}
+			return execAll(tx, resourcesFTSDown)
+		},
+	})
+}
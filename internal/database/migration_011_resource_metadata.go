@@ -0,0 +1,49 @@
+package database
+
+import "gorm.io/gorm"
+
+type bookDetailsV11 struct {
+	ResourceID uint          `gorm:"primaryKey"`
+	Resource   resourceRefV5 `gorm:"foreignKey:ResourceID"`
+	Authors    string        `gorm:"type:text"` // Liste JSON
+	Publisher  string
+	Year       int     `gorm:"not null;default:0;check:chk_book_details_year,year BETWEEN 0 AND 9999"`
+	ISBN       *string `gorm:"uniqueIndex;check:chk_book_details_isbn,length(isbn) = 13"`
+	Language   string
+	Genres     string `gorm:"type:text"` // Liste JSON
+	CoverURL   string
+}
+
+func (bookDetailsV11) TableName() string { return "book_details" }
+
+type gameDetailsV11 struct {
+	ResourceID uint          `gorm:"primaryKey"`
+	Resource   resourceRefV5 `gorm:"foreignKey:ResourceID"`
+	Designer   string
+	MinPlayers int     `gorm:"not null;default:0;check:chk_game_details_players,min_players >= 0 AND (max_players = 0 OR max_players >= min_players)"`
+	MaxPlayers int     `gorm:"not null;default:0"`
+	PlayTime   int     `gorm:"not null;default:0;check:chk_game_details_play_time,play_time >= 0"`
+	MinAge     int     `gorm:"not null;default:0;check:chk_game_details_min_age,min_age >= 0"`
+	Complexity float64 `gorm:"not null;default:0;check:chk_game_details_complexity,complexity = 0 OR complexity BETWEEN 1 AND 5"`
+}
+
+func (gameDetailsV11) TableName() string { return "game_details" }
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "create_resource_metadata",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&bookDetailsV11{}); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&gameDetailsV11{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&gameDetailsV11{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&bookDetailsV11{})
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package database
+
+import "gorm.io/gorm"
+
+type resourceV12 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (resourceV12) TableName() string { return "resources" }
+
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "add_resources_deleted_at",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumn(tx, &resourceV12{}, "DeletedAt"); err != nil {
+				return err
+			}
+			return ensureIndex(tx, &resourceV12{}, "DeletedAt")
+		},
+		// Les ressources supprimées réapparaissent : les effacer priverait leurs prêts de leur ressource.
+		// La colonne est retirée par ALTER TABLE : DropColumn recréerait la table sous SQLite,
+		// ce qui supprimerait les triggers de l'index plein texte.
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&resourceV12{}, "DeletedAt"); err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE resources DROP COLUMN deleted_at").Error
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package database
+
+import "gorm.io/gorm"
+
+type userV13 struct {
+	Language string `gorm:"not null;default:''"`
+}
+
+func (userV13) TableName() string { return "users" }
+
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "add_users_language",
+		// Les utilisateurs existants n'ont pas de préférence : l'en-tête Accept-Language s'applique
+		Up: func(tx *gorm.DB) error {
+			return addColumn(tx, &userV13{}, "Language")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&userV13{}, "Language")
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"awesomeProject/internal/models"
+	"gorm.io/gorm"
+)
+
+type resourceV14 struct {
+	TitleKey string `gorm:"not null;default:'';index"`
+}
+
+func (resourceV14) TableName() string { return "resources" }
+
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "add_resources_title_key",
+		// La clé est calculée en Go pour les ressources existantes, supprimées comprises
+		Up: func(tx *gorm.DB) error {
+			if err := addColumn(tx, &resourceV14{}, "TitleKey"); err != nil {
+				return err
+			}
+			var rows []struct {
+				ID    uint
+				Title string
+			}
+			if err := tx.Table("resources").Select("id", "title").Find(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				if err := tx.Table("resources").Where("id = ?", row.ID).Update("title_key", models.TitleKey(row.Title)).Error; err != nil {
+					return err
+				}
+			}
+			return ensureIndex(tx, &resourceV14{}, "TitleKey")
+		},
+		// Comme pour deleted_at, ALTER TABLE préserve les triggers de l'index plein texte
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex(&resourceV14{}, "TitleKey"); err != nil {
+				return err
+			}
+			return tx.Exec("ALTER TABLE resources DROP COLUMN title_key").Error
+		},
+	})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UserHandler regroupe les handlers du profil et de la gestion des comptes.
+type UserHandler struct {
+	users *service.UserService
+}
+
+// NewUserHandler crée les handlers des comptes sur la base fournie.
+func NewUserHandler(db *gorm.DB) *UserHandler {
+	return &UserHandler{users: service.NewUserService(repository.NewGormStore(db))}
+}
+
+// UpdateRoleInput définit le format attendu pour la modification du rôle d'un utilisateur.
+type UpdateRoleInput struct {
+	Role string `json:"role" binding:"required,oneof=member librarian admin"`
+}
+
+// ListUsers récupère la liste des utilisateurs (réservé aux administrateurs).
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	users, err := h.users.List()
+	if err != nil {
+		respondError(c, err, "Erreur lors de la récupération des utilisateurs")
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// UpdateUserRole permet à un administrateur de promouvoir ou rétrograder un utilisateur.
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID d'utilisateur invalide"))
+		return
+	}
+
+	var input UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	user, err := h.users.ChangeRole(actor, uint(targetID), input.Role)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour du rôle")
+		return
+	}
+	logging.FromContext(c).Event(c, "user.role_changed", "target_id", user.ID, "role", user.Role)
+	c.JSON(http.StatusOK, user)
+}
+
+// RevokeUserTokens révoque immédiatement toutes les sessions d'un utilisateur
+// (par exemple en cas de vol d'appareil ou d'exclusion d'un adhérent).
+func (h *UserHandler) RevokeUserTokens(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID d'utilisateur invalide"))
+		return
+	}
+
+	if err := h.users.RevokeSessions(uint(targetID)); err != nil {
+		respondError(c, err, "Erreur lors de la révocation des sessions")
+		return
+	}
+
+	logging.FromContext(c).Event(c, "user.sessions_revoked", "target_id", uint(targetID))
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.FromContext(c), "Sessions révoquées")})
+}
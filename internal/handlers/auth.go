@@ -1,19 +1,16 @@
 package handlers
 
 import (
-	"awesomeProject/internal/database"
-	"awesomeProject/internal/models"
-	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
+	"errors"
 	"net/http"
-	"time"
 
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
-var JwtSecret = []byte("your_jwt_secret") // À remplacer par une valeur sécurisée, idéalement issue d'une variable d'environnement
-
 // RegisterInput définit les données attendues pour l'inscription.
 type RegisterInput struct {
 	Name     string `json:"name" binding:"required"`
@@ -22,44 +19,19 @@ type RegisterInput struct {
 }
 
 // RegisterUser gère l'inscription d'un nouvel utilisateur.
-func RegisterUser(c *gin.Context) {
+func (h *AuthHandler) RegisterUser(c *gin.Context) {
 	var input RegisterInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Vérifier si l'utilisateur existe déjà
-	var existingUser models.User
-	if err := database.DB.Where("email = ?", input.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Utilisateur existant"})
-		return
-	} else if err != nil && err != gorm.ErrRecordNotFound {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
+		c.Error(apierror.Binding(err))
 		return
 	}
 
-	// Hacher le mot de passe
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	user, err := h.users.Register(input.Name, input.Email, input.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors du hachage du mot de passe"})
-		return
-	}
-
-	// Créer l'utilisateur
-	user := models.User{
-		Name:     input.Name,
-		Email:    input.Email,
-		Password: string(hashedPassword),
-	}
-	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de l'utilisateur"})
+		respondError(c, err, "Erreur lors de la création de l'utilisateur")
 		return
 	}
-
-	// Optionnel : masquer le mot de passe dans la réponse
-	user.Password = ""
-
+	logging.FromContext(c).Event(c, "user.registered", "user_id", user.ID)
 	c.JSON(http.StatusCreated, gin.H{"user": user})
 }
 
@@ -70,122 +42,83 @@ type LoginInput struct {
 }
 
 // LoginUser gère la connexion d'un utilisateur.
-func LoginUser(c *gin.Context) {
+func (h *AuthHandler) LoginUser(c *gin.Context) {
 	var input LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.Binding(err))
 		return
 	}
 
-	// Rechercher l'utilisateur par email
-	var user models.User
-	if err := database.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Email ou mot de passe invalide"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
-		}
-		return
+	user, err := h.users.Authenticate(input.Email, input.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		logging.FromContext(c).Event(c, "auth.login_failed", "email", input.Email, "client_ip", c.ClientIP())
 	}
-
-	// Vérifier le mot de passe
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email ou mot de passe invalide"})
+	if err != nil {
+		respondError(c, err, "Erreur interne")
 		return
 	}
 
-	// Générer un token JWT
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(72 * time.Hour).Unix(), // expiration dans 72 heures
-	})
-
-	tokenString, err := token.SignedString(JwtSecret)
+	// Générer le token d'accès et le token de rafraîchissement
+	pair, err := h.sessions.Issue(*user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la génération du token"})
+		c.Error(apierror.Internal("Erreur lors de la génération du token", err))
 		return
 	}
 
+	logging.FromContext(c).Event(c, "auth.login", "user_id", user.ID)
+
 	// Masquer le mot de passe avant de renvoyer l'utilisateur
 	user.Password = ""
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Connexion réussie",
-		"user":    user,
-		"token":   tokenString,
+		"message":       i18n.T(i18n.FromContext(c), "Connexion réussie"),
+		"user":          user,
+		"token":         pair.Token,
+		"refresh_token": pair.RefreshToken,
+		"expires_in":    pair.ExpiresIn,
 	})
 }
 
 // GetProfile récupère le profil de l'utilisateur connecté.
 // On suppose que le middleware d'authentification stocke l'ID de l'utilisateur dans le contexte avec la clé "userID".
-func GetProfile(c *gin.Context) {
-	// Récupérer l'ID de l'utilisateur depuis le contexte
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non authentifié"})
-		return
-	}
-
-	// On s'attend à ce que l'ID soit de type uint
-	userID, ok := userIDInterface.(uint)
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
 		return
 	}
 
-	// Récupérer l'utilisateur dans la base de données
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Utilisateur non trouvé"})
+	user, err := h.users.Profile(actor.ID)
+	if err != nil {
+		respondError(c, err, "Erreur interne")
 		return
 	}
-
 	c.JSON(http.StatusOK, user)
 }
 
-// UpdateProfile permet de mettre à jour le profil de l'utilisateur connecté.
-func UpdateProfile(c *gin.Context) {
-	// Récupérer l'ID de l'utilisateur depuis le contexte
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non authentifié"})
-		return
-	}
+// UpdateProfileInput définit les données attendues pour la mise à jour du profil.
+type UpdateProfileInput struct {
+	Name     string  `json:"name" binding:"required"`
+	Email    string  `json:"email" binding:"required,email"`
+	Language *string `json:"language"` // "fr" ou "en" ; absente : inchangée ; vide : Accept-Language
+}
 
-	userID, ok := userIDInterface.(uint)
+// UpdateProfile permet de mettre à jour le profil de l'utilisateur connecté.
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
 		return
 	}
 
-	// Récupérer l'utilisateur existant
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Utilisateur non trouvé"})
-		return
-	}
-
-	// Définir un struct pour le binding des données envoyées par le client
-	var input struct {
-		Name  string `json:"name" binding:"required"`
-		Email string `json:"email" binding:"required,email"`
-	}
-
-	// Lier les données JSON à l'input
+	var input UpdateProfileInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apierror.Binding(err))
 		return
 	}
 
-	// Mettre à jour les champs souhaités
-	user.Name = input.Name
-	user.Email = input.Email
-
-	// Sauvegarder l'utilisateur mis à jour dans la base
-	if err := database.DB.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour du profil"})
+	user, err := h.users.UpdateProfile(actor.ID, input.Name, input.Email, input.Language)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour du profil")
 		return
 	}
-
 	c.JSON(http.StatusOK, user)
 }
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CopyInput définit les champs modifiables d'un exemplaire.
+type CopyInput struct {
+	Barcode   string `json:"barcode"`
+	Location  string `json:"location"`
+	Condition string `json:"condition"`
+	Status    string `json:"status"` // Uniquement à la création : disponible ou indisponible
+}
+
+// validateCopy vérifie l'état et le statut initial d'un exemplaire et répond 400 s'ils sont invalides.
+func validateCopy(c *gin.Context, copy models.Copy) bool {
+	if copy.Condition != "" && !models.IsValidCondition(copy.Condition) {
+		c.Error(apierror.Invalid("condition", "oneof", "État invalide : neuf, bon, usé ou abîmé attendu"))
+		return false
+	}
+	if copy.Status != "" && copy.Status != models.ResourceAvailable && copy.Status != models.ResourceUnavailable {
+		c.Error(apierror.Invalid("status", "oneof", "Statut invalide : disponible ou indisponible attendu"))
+		return false
+	}
+	return true
+}
+
+// GetCopies liste les exemplaires d'une ressource.
+func (h *CatalogHandler) GetCopies(c *gin.Context) {
+	resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	copies, err := h.catalog.Copies(uint(resourceID))
+	if err != nil {
+		respondError(c, err, "Impossible de récupérer les exemplaires")
+		return
+	}
+	c.JSON(http.StatusOK, copies)
+}
+
+// CreateCopy ajoute un exemplaire à une ressource existante.
+func (h *CatalogHandler) CreateCopy(c *gin.Context) {
+	resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	var input CopyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+	copy := models.Copy{
+		Barcode:   input.Barcode,
+		Location:  input.Location,
+		Condition: input.Condition,
+		Status:    models.ResourceStatus(input.Status),
+	}
+	if !validateCopy(c, copy) {
+		return
+	}
+
+	if err := h.catalog.AddCopy(uint(resourceID), &copy); err != nil {
+		respondError(c, err, "Erreur lors de la création de l'exemplaire")
+		return
+	}
+
+	c.JSON(http.StatusCreated, copy)
+}
+
+// UpdateCopy modifie le code-barres, l'emplacement ou l'état d'un exemplaire.
+// Le statut ne change que par les prêts ou les actions retirer / remettre en service.
+func (h *CatalogHandler) UpdateCopy(c *gin.Context) {
+	copyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID d'exemplaire invalide"))
+		return
+	}
+
+	var input CopyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+	if input.Status != "" {
+		c.Error(apierror.Invalid("status", "readonly", "Le statut se modifie via les actions disable / enable"))
+		return
+	}
+	if !validateCopy(c, models.Copy{Condition: input.Condition}) {
+		return
+	}
+
+	copy, err := h.catalog.UpdateCopy(uint(copyID), models.Copy{
+		Barcode:   input.Barcode,
+		Location:  input.Location,
+		Condition: input.Condition,
+	})
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour de l'exemplaire")
+		return
+	}
+
+	c.JSON(http.StatusOK, copy)
+}
+
+// DisableCopy retire un exemplaire disponible du prêt.
+func (h *CatalogHandler) DisableCopy(c *gin.Context) {
+	h.updateCopyStatus(c, models.ResourceDisable)
+}
+
+// EnableCopy remet en service un exemplaire retiré.
+func (h *CatalogHandler) EnableCopy(c *gin.Context) {
+	h.updateCopyStatus(c, models.ResourceEnable)
+}
+
+// updateCopyStatus applique une action du personnel à un exemplaire en respectant la table de transitions.
+func (h *CatalogHandler) updateCopyStatus(c *gin.Context, action models.ResourceAction) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID d'exemplaire invalide"))
+		return
+	}
+
+	copy, err := h.catalog.TransitionCopy(uint(id), action)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour de l'exemplaire")
+		return
+	}
+
+	c.JSON(http.StatusOK, copy)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// respondError transmet une erreur du domaine au middleware d'erreurs, qui la traduit en réponse.
+// Toute autre erreur produit une erreur 500 avec le message fallback.
+func respondError(c *gin.Context, err error, fallback string) {
+	var domainErr *service.Error
+	if !errors.As(err, &domainErr) {
+		c.Error(apierror.Internal(fallback, err))
+		return
+	}
+	c.Error(err)
+}
+
+// currentActor retourne l'utilisateur authentifié par le middleware. Sinon, la réponse d'erreur
+// est envoyée et false est retourné.
+func currentActor(c *gin.Context) (service.Actor, bool) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		c.Error(apierror.Unauthorized(apierror.CodeUnauthenticated, "Utilisateur non authentifié"))
+		return service.Actor{}, false
+	}
+	userID, ok := userIDInterface.(uint)
+	if !ok {
+		c.Error(apierror.Internal("Erreur interne", nil))
+		return service.Actor{}, false
+	}
+	return service.Actor{ID: userID, Role: c.GetString("userRole")}, true
+}
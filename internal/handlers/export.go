@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushRows est le nombre de lignes écrites entre deux envois au client.
+const exportFlushRows = 100
+
+// exportDateLayout est le format des dates dans les exports CSV, lisible par les tableurs.
+const exportDateLayout = "2006-01-02 15:04:05"
+
+// Colonnes des exports CSV, dans l'ordre des champs de service.ResourceExport et service.LoanExport
+var (
+	resourceExportColumns = []string{
+		"id", "title", "type", "status", "total_copies", "available_copies",
+		"authors", "publisher", "year", "isbn", "language", "genres", "cover_url",
+		"designer", "min_players", "max_players", "play_time", "min_age", "complexity",
+	}
+	loanExportColumns = []string{
+		"id", "loan_date", "return_date", "status", "borrow_type", "renewal_count",
+		"user_id", "user_name", "user_email", "resource_id", "resource_title", "resource_type", "copy_barcode",
+	}
+)
+
+// resourceRecord convertit une ligne de l'export du catalogue en enregistrement CSV.
+// Les listes sont séparées par "|", comme à l'import.
+func resourceRecord(r service.ResourceExport) []string {
+	return []string{
+		strconv.Itoa(int(r.ID)), r.Title, r.Type, r.Status, strconv.Itoa(r.TotalCopies), strconv.Itoa(r.AvailableCopies),
+		strings.Join(r.Authors, "|"), r.Publisher, optionalInt(r.Year), r.ISBN, r.Language, strings.Join(r.Genres, "|"), r.CoverURL,
+		r.Designer, optionalInt(r.MinPlayers), optionalInt(r.MaxPlayers), optionalInt(r.PlayTime), optionalInt(r.MinAge),
+		optionalFloat(r.Complexity),
+	}
+}
+
+// loanRecord convertit une ligne de l'export des prêts en enregistrement CSV.
+func loanRecord(l service.LoanExport) []string {
+	return []string{
+		strconv.Itoa(int(l.ID)), l.LoanDate.Format(exportDateLayout), l.ReturnDate.Format(exportDateLayout),
+		l.Status, l.BorrowType, strconv.Itoa(l.RenewalCount),
+		strconv.Itoa(int(l.UserID)), l.UserName, l.UserEmail,
+		strconv.Itoa(int(l.ResourceID)), l.ResourceTitle, l.ResourceType, l.CopyBarcode,
+	}
+}
+
+// optionalInt laisse vide une cellule numérique non renseignée.
+func optionalInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// optionalFloat laisse vide une cellule décimale non renseignée.
+func optionalFloat(n float64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// exportFormat lit le format demandé ("csv" par défaut ou "json") et, pour le CSV, le séparateur :
+// "delimiter=semicolon" produit le CSV attendu par les tableurs configurés en français.
+func exportFormat(c *gin.Context) (format string, delimiter rune, err error) {
+	format = c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		return "", 0, apierror.InvalidParam("format", "Format invalide : csv ou json attendu")
+	}
+	switch c.DefaultQuery("delimiter", "comma") {
+	case "comma":
+		delimiter = ','
+	case "semicolon":
+		delimiter = ';'
+	default:
+		return "", 0, apierror.InvalidParam("delimiter", "Séparateur invalide : comma ou semicolon attendu")
+	}
+	return format, delimiter, nil
+}
+
+// formulaPrefixes sont les premiers caractères qu'un tableur interprète comme le début d'une formule.
+const formulaPrefixes = "=+-@\t\r"
+
+// csvCell neutralise une cellule qu'un tableur exécuterait comme une formule (par exemple
+// un nom d'adhérent « =HYPERLINK(...) ») en la préfixant d'une apostrophe.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// streamExport envoie les lignes de l'export une à une, sans charger l'export en mémoire.
+// export passe chaque ligne à write, avec l'objet JSON et l'enregistrement CSV correspondants ;
+// write retourne l'erreur d'écriture, par exemple si le client s'est déconnecté, pour interrompre la lecture.
+// Une erreur survenue avant la première ligne produit une erreur 500 avec message ; une fois
+// l'envoi commencé, le code HTTP ne peut plus changer : l'export est alors tronqué.
+func streamExport(c *gin.Context, name, format string, delimiter rune, columns []string, message string, export func(write func(item any, record []string) error) error) {
+	var writer *csv.Writer
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("2006-01-02"), format)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		if format == "json" {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+		} else {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+		}
+		c.Status(http.StatusOK)
+
+		if format == "json" {
+			_, err := c.Writer.WriteString("[")
+			return err
+		}
+		// BOM : les tableurs reconnaissent ainsi l'encodage UTF-8 des accents
+		if _, err := c.Writer.WriteString("\xef\xbb\xbf"); err != nil {
+			return err
+		}
+		writer = csv.NewWriter(c.Writer)
+		writer.Comma = delimiter
+		return writer.Write(columns)
+	}
+	// flush envoie les lignes en attente et signale un client déconnecté.
+	flush := func() error {
+		if writer != nil {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return c.Request.Context().Err()
+	}
+
+	n := 0
+	err := export(func(item any, record []string) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if writer != nil {
+			cells := make([]string, len(record))
+			for i, value := range record {
+				cells[i] = csvCell(value)
+			}
+			if err := writer.Write(cells); err != nil {
+				return err
+			}
+		} else {
+			if n > 0 {
+				if _, err := c.Writer.WriteString(","); err != nil {
+					return err
+				}
+			}
+			data, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if _, err := c.Writer.Write(data); err != nil {
+				return err
+			}
+		}
+		n++
+		if n%exportFlushRows == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil && writer == nil {
+		_, err = c.Writer.WriteString("]")
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		if !started {
+			c.Error(apierror.Internal(message, err))
+		} else {
+			logging.FromContext(c).ErrorContext(c, "Export interrompu", "export", name, "error", err)
+		}
+	}
+}
+
+// ExportResources exporte le catalogue en CSV ou JSON. Filtres : type, status.
+func (h *CatalogHandler) ExportResources(c *gin.Context) {
+	format, delimiter, err := exportFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter := service.ResourceExportFilter{
+		Type:   c.Query("type"),
+		Status: models.ResourceStatus(c.Query("status")),
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		c.Error(apierror.InvalidParam("status", "Statut invalide"))
+		return
+	}
+
+	streamExport(c, "ressources", format, delimiter, resourceExportColumns, "Erreur lors de l'export du catalogue",
+		func(write func(any, []string) error) error {
+			return h.catalog.ExportResources(filter, func(r service.ResourceExport) error {
+				return write(r, resourceRecord(r))
+			})
+		})
+}
+
+// ExportLoans exporte les prêts en CSV ou JSON. Filtres : from et to (dates AAAA-MM-JJ incluses,
+// sur la date de prêt), status, type (de la ressource) et user (identifiant de l'adhérent).
+func (h *CatalogHandler) ExportLoans(c *gin.Context) {
+	format, delimiter, err := exportFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter := service.LoanExportFilter{
+		Status: models.LoanStatus(c.Query("status")),
+		Type:   c.Query("type"),
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		c.Error(apierror.InvalidParam("status", "Statut invalide"))
+		return
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(apierror.InvalidParam("from", "from doit être une date AAAA-MM-JJ"))
+			return
+		}
+		filter.From = from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(apierror.InvalidParam("to", "to doit être une date AAAA-MM-JJ"))
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1) // Jour de fin inclus
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		c.Error(apierror.InvalidParam("from", "La date de début doit précéder la date de fin"))
+		return
+	}
+	if value := c.Query("user"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || userID == 0 {
+			c.Error(apierror.InvalidParam("user", "ID d'utilisateur invalide"))
+			return
+		}
+		filter.UserID = uint(userID)
+	}
+
+	streamExport(c, "prets", format, delimiter, loanExportColumns, "Erreur lors de l'export des prêts",
+		func(write func(any, []string) error) error {
+			return h.catalog.ExportLoans(filter, func(l service.LoanExport) error {
+				return write(l, loanRecord(l))
+			})
+		})
+}
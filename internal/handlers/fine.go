@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// FineInput définit le format attendu pour un paiement ou une remise d'amende (montants en centimes).
+type FineInput struct {
+	Amount int    `json:"amount" binding:"gte=0"` // Obligatoire pour un paiement ; 0 annule tout le solde pour une remise
+	Note   string `json:"note"`
+}
+
+// GetFines retourne le solde dû et l'historique du compte d'amendes de l'utilisateur connecté.
+// Le personnel peut consulter le compte d'un autre utilisateur avec le paramètre "user_id".
+func (h *LoanHandler) GetFines(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var userID uint
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		otherID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil || otherID == 0 {
+			c.Error(apierror.InvalidParam("user_id", "ID d'utilisateur invalide"))
+			return
+		}
+		userID = uint(otherID)
+	}
+
+	balance, entries, err := h.fines.List(actor, userID)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la récupération des amendes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"balance": balance, "entries": entries})
+}
+
+// RecordFinePayment enregistre un paiement d'amende pour l'utilisateur désigné.
+func (h *LoanHandler) RecordFinePayment(c *gin.Context) {
+	h.creditFines(c, "fine.paid", h.fines.RecordPayment)
+}
+
+// WaiveFines accorde une remise sur les amendes de l'utilisateur désigné.
+func (h *LoanHandler) WaiveFines(c *gin.Context) {
+	h.creditFines(c, "fine.waived", h.fines.Waive)
+}
+
+// creditFines applique un paiement ou une remise saisi par le membre du personnel connecté,
+// journalisé sous le nom d'événement fourni.
+func (h *LoanHandler) creditFines(c *gin.Context, event string, credit func(actor service.Actor, userID uint, amount int, note string) (*models.FineEntry, int, error)) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID d'utilisateur invalide"))
+		return
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var input FineInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+
+	entry, balance, err := credit(actor, uint(userID), input.Amount, input.Note)
+	if err != nil {
+		respondError(c, err, "Erreur lors de l'enregistrement")
+		return
+	}
+	logging.FromContext(c).Event(c, event, "member_id", entry.UserID, "amount", entry.Amount, "balance", balance)
+	c.JSON(http.StatusCreated, gin.H{"entry": entry, "balance": balance})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// PlaceHoldInput définit le format attendu pour une réservation.
+type PlaceHoldInput struct {
+	ResourceID uint `json:"resource_id" binding:"required"`
+}
+
+// PlaceHold inscrit l'utilisateur connecté dans la file d'attente d'une ressource indisponible.
+func (h *LoanHandler) PlaceHold(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var input PlaceHoldInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+
+	hold, err := h.holds.Place(actor, input.ResourceID)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la réservation")
+		return
+	}
+	logging.FromContext(c).Event(c, "hold.placed", "hold_id", hold.ID, "resource_id", hold.ResourceID)
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHolds liste les réservations de l'utilisateur connecté, avec leur rang dans la file.
+// Le personnel peut consulter les réservations actives d'une ressource avec le paramètre "resource_id".
+func (h *LoanHandler) GetHolds(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var resourceID uint
+	if resourceIDStr := c.Query("resource_id"); resourceIDStr != "" {
+		id, err := strconv.ParseUint(resourceIDStr, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apierror.InvalidParam("resource_id", "ID de ressource invalide"))
+			return
+		}
+		resourceID = uint(id)
+	}
+
+	holds, err := h.holds.List(actor, resourceID)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la récupération des réservations")
+		return
+	}
+	c.JSON(http.StatusOK, holds)
+}
+
+// CancelHold annule une réservation de l'utilisateur connecté (ou de n'importe qui pour le personnel).
+// Un exemplaire mis de côté passe à la réservation suivante.
+func (h *LoanHandler) CancelHold(c *gin.Context) {
+	holdID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de réservation invalide"))
+		return
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	cancelled, err := h.holds.Cancel(actor, uint(holdID))
+	if err != nil {
+		respondError(c, err, "Erreur lors de l'annulation de la réservation")
+		return
+	}
+	logging.FromContext(c).Event(c, "hold.cancelled", "hold_id", cancelled.ID, "resource_id", cancelled.ResourceID,
+		"holder_id", cancelled.UserID)
+	c.JSON(http.StatusOK, cancelled)
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxImportSize = 10 << 20 // 10 Mo
+	maxImportRows = 5000
+	maxCopiesRow  = 50
+)
+
+// Erreurs de lecture d'une cellule CSV, rapportées avec le nom de la colonne
+var (
+	errIntExpected    = errors.New("nombre entier attendu")
+	errNumberExpected = errors.New("nombre attendu")
+)
+
+// ImportRow est une ligne d'import : les colonnes d'un fichier CSV ou les champs d'un objet JSON.
+// Dans un fichier CSV, les listes (auteurs, genres) sont séparées par "|".
+type ImportRow struct {
+	Title  string `json:"title"`
+	Type   string `json:"type"`
+	Status string `json:"status"` // "disponible" (par défaut) ou "indisponible"
+	Copies int    `json:"copies"` // Nombre d'exemplaires, 1 par défaut
+
+	// Livres
+	Authors   []string `json:"authors"`
+	Publisher string   `json:"publisher"`
+	Year      int      `json:"year"`
+	ISBN      string   `json:"isbn"`
+	Language  string   `json:"language"`
+	Genres    []string `json:"genres"`
+	CoverURL  string   `json:"cover_url"`
+
+	// Jeux
+	Designer   string  `json:"designer"`
+	MinPlayers int     `json:"min_players"`
+	MaxPlayers int     `json:"max_players"`
+	PlayTime   int     `json:"play_time"`
+	MinAge     int     `json:"min_age"`
+	Complexity float64 `json:"complexity"`
+}
+
+// ImportRowResult décrit le sort d'une ligne importée.
+type ImportRowResult struct {
+	Row    int    `json:"row"`    // Numéro de la ligne de données, à partir de 1
+	Status string `json:"status"` // "created", "skipped" ou "error"
+	Title  string `json:"title,omitempty"`
+	ID     uint   `json:"id,omitempty"` // Ressource créée, ou doublon existant
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport est le rapport renvoyé par l'import.
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Atomic    bool              `json:"atomic"`
+	Committed bool              `json:"committed"` // Faux pour un import à blanc ou un import atomique en erreur
+	Created   int               `json:"created"`
+	Skipped   int               `json:"skipped"`
+	Errors    int               `json:"errors"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// csvColumns associe chaque colonne CSV au champ correspondant d'une ligne d'import.
+var csvColumns = map[string]func(row *ImportRow, value string) error{
+	"title":       func(row *ImportRow, value string) error { row.Title = value; return nil },
+	"type":        func(row *ImportRow, value string) error { row.Type = value; return nil },
+	"status":      func(row *ImportRow, value string) error { row.Status = value; return nil },
+	"copies":      func(row *ImportRow, value string) error { return parseCSVInt(value, &row.Copies) },
+	"authors":     func(row *ImportRow, value string) error { row.Authors = splitCSVList(value); return nil },
+	"publisher":   func(row *ImportRow, value string) error { row.Publisher = value; return nil },
+	"year":        func(row *ImportRow, value string) error { return parseCSVInt(value, &row.Year) },
+	"isbn":        func(row *ImportRow, value string) error { row.ISBN = value; return nil },
+	"language":    func(row *ImportRow, value string) error { row.Language = value; return nil },
+	"genres":      func(row *ImportRow, value string) error { row.Genres = splitCSVList(value); return nil },
+	"cover_url":   func(row *ImportRow, value string) error { row.CoverURL = value; return nil },
+	"designer":    func(row *ImportRow, value string) error { row.Designer = value; return nil },
+	"min_players": func(row *ImportRow, value string) error { return parseCSVInt(value, &row.MinPlayers) },
+	"max_players": func(row *ImportRow, value string) error { return parseCSVInt(value, &row.MaxPlayers) },
+	"play_time":   func(row *ImportRow, value string) error { return parseCSVInt(value, &row.PlayTime) },
+	"min_age":     func(row *ImportRow, value string) error { return parseCSVInt(value, &row.MinAge) },
+	"complexity": func(row *ImportRow, value string) error {
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return errNumberExpected
+		}
+		row.Complexity = n
+		return nil
+	},
+}
+
+// parseCSVInt lit un entier facultatif d'une cellule CSV.
+func parseCSVInt(value string, target *int) error {
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errIntExpected
+	}
+	*target = n
+	return nil
+}
+
+// splitCSVList découpe une cellule CSV contenant une liste séparée par "|".
+func splitCSVList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "|")
+}
+
+// parsedRow est une ligne lue du fichier, ou l'erreur empêchant de la lire.
+// L'erreur porte un message du catalogue, traduit dans la langue du rapport.
+type parsedRow struct {
+	row ImportRow
+	err *apierror.Error
+}
+
+// cellError décrit une cellule CSV illisible de la colonne donnée.
+func cellError(column string, err error) *apierror.Error {
+	if errors.Is(err, errNumberExpected) {
+		return apierror.Invalid(column, "type", "%s : nombre attendu").WithArgs(column)
+	}
+	return apierror.Invalid(column, "type", "%s : nombre entier attendu").WithArgs(column)
+}
+
+// objectError décrit un objet JSON que le décodeur refuse : champ inconnu ou valeur du mauvais type.
+func objectError(err error) *apierror.Error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apierror.Invalid(typeErr.Field, "type", "%s : type de valeur invalide").WithArgs(typeErr.Field).Wrap(err)
+	}
+	if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field, _ := strconv.Unquote(quoted)
+		return apierror.Invalid(field, "unknown", "%s : champ inconnu").WithArgs(field).Wrap(err)
+	}
+	return apierror.BadRequest(apierror.CodeMalformedBody, "objet JSON illisible").Wrap(err)
+}
+
+// parseCSVImport lit un fichier CSV avec une ligne d'en-tête. Le séparateur est la virgule,
+// ou le point-virgule des tableurs configurés en français.
+func parseCSVImport(data []byte) ([]parsedRow, *apierror.Error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM des exports de tableur
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, apierror.BadRequest(apierror.CodeMalformedBody, "en-tête CSV illisible").Wrap(err)
+	}
+	setters := make([]func(*ImportRow, string) error, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		setter, ok := csvColumns[name]
+		if !ok {
+			return nil, apierror.BadRequest(apierror.CodeMalformedBody, "colonne inconnue : %q").WithArgs(name)
+		}
+		setters[i] = setter
+		header[i] = name
+	}
+
+	var rows []parsedRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, apierror.BadRequest(apierror.CodeMalformedBody, "CSV illisible").Wrap(err)
+			}
+			if parseErr.Err == csv.ErrQuote || parseErr.Err == csv.ErrBareQuote {
+				return nil, apierror.BadRequest(apierror.CodeMalformedBody, "CSV invalide : guillemet mal placé ligne %d").WithArgs(parseErr.StartLine).Wrap(err)
+			}
+			rows = append(rows, parsedRow{err: apierror.BadRequest(apierror.CodeMalformedBody, "ligne CSV illisible").Wrap(err)})
+			continue
+		}
+		var parsed parsedRow
+		for i, value := range record {
+			if i >= len(setters) {
+				parsed.err = apierror.BadRequest(apierror.CodeMalformedBody, "plus de valeurs que de colonnes")
+				break
+			}
+			if err := setters[i](&parsed.row, strings.TrimSpace(value)); err != nil {
+				parsed.err = cellError(header[i], err)
+				break
+			}
+		}
+		rows = append(rows, parsed)
+	}
+	return rows, nil
+}
+
+// parseJSONImport lit un tableau JSON d'objets. Un objet mal formé est une erreur de ligne.
+func parseJSONImport(data []byte) ([]parsedRow, *apierror.Error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, apierror.BadRequest(apierror.CodeMalformedBody, "JSON invalide : un tableau d'objets est attendu").Wrap(err)
+	}
+	rows := make([]parsedRow, len(items))
+	for i, item := range items {
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rows[i].row); err != nil {
+			rows[i].err = objectError(err)
+		}
+	}
+	return rows, nil
+}
+
+// resource construit la ressource à créer à partir d'une ligne d'import, après validation.
+// Retourne un message d'erreur dans la langue demandée, ou "" si la ligne est valide.
+func (row ImportRow) resource(lang i18n.Lang) (models.Resource, string) {
+	resource := models.Resource{
+		Title:  strings.TrimSpace(row.Title),
+		Type:   strings.TrimSpace(row.Type),
+		Status: models.ResourceStatus(strings.TrimSpace(row.Status)),
+	}
+	if resource.Title == "" {
+		return resource, i18n.T(lang, "title : obligatoire")
+	}
+	if resource.Type == "" {
+		return resource, i18n.T(lang, "type : obligatoire")
+	}
+	if resource.Status == "" {
+		resource.Status = models.ResourceAvailable
+	}
+	if resource.Status != models.ResourceAvailable && resource.Status != models.ResourceUnavailable {
+		return resource, i18n.T(lang, "status : disponible ou indisponible attendu")
+	}
+	copies := row.Copies
+	if copies == 0 {
+		copies = 1
+	}
+	if copies < 1 || copies > maxCopiesRow {
+		return resource, i18n.T(lang, "copies : entre 1 et %d attendu", maxCopiesRow)
+	}
+	resource.Copies = make([]models.Copy, copies)
+
+	if len(row.Authors) > 0 || row.Publisher != "" || row.Year != 0 || row.ISBN != "" ||
+		row.Language != "" || len(row.Genres) > 0 || row.CoverURL != "" {
+		resource.Book = &models.BookDetails{
+			Authors:   row.Authors,
+			Publisher: row.Publisher,
+			Year:      row.Year,
+			Language:  row.Language,
+			Genres:    row.Genres,
+			CoverURL:  row.CoverURL,
+		}
+		if row.ISBN != "" {
+			isbn := row.ISBN
+			resource.Book.ISBN = &isbn
+		}
+	}
+	if row.Designer != "" || row.MinPlayers != 0 || row.MaxPlayers != 0 || row.PlayTime != 0 ||
+		row.MinAge != 0 || row.Complexity != 0 {
+		resource.Game = &models.GameDetails{
+			Designer:   row.Designer,
+			MinPlayers: row.MinPlayers,
+			MaxPlayers: row.MaxPlayers,
+			PlayTime:   row.PlayTime,
+			MinAge:     row.MinAge,
+			Complexity: row.Complexity,
+		}
+	}
+	if err := validateMetadata(&resource); err != nil {
+		return resource, err.Localize(lang).Message
+	}
+	return resource, ""
+}
+
+// ImportResources importe un lot de ressources depuis un fichier CSV (text/csv) ou JSON (application/json).
+// Chaque ligne est validée ; une ressource déjà au catalogue (même ISBN, ou même titre et type) est ignorée.
+// Paramètres : "dry_run=true" pour un aperçu sans enregistrement, "atomic=true" pour tout annuler
+// si une ligne est en erreur. Le lot est traité dans une seule transaction et un rapport par ligne est renvoyé.
+func (h *CatalogHandler) ImportResources(c *gin.Context) {
+	report := ImportReport{
+		DryRun: c.Query("dry_run") == "true",
+		Atomic: c.Query("atomic") == "true",
+		Rows:   []ImportRowResult{},
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	if err != nil {
+		c.Error(apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Fichier trop volumineux"))
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = "csv"
+		case "application/json":
+			format = "json"
+		}
+	}
+	var rows []parsedRow
+	var parseErr *apierror.Error
+	switch format {
+	case "csv":
+		rows, parseErr = parseCSVImport(data)
+	case "json":
+		rows, parseErr = parseJSONImport(data)
+	default:
+		c.Error(apierror.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedMediaType, "Format non pris en charge : text/csv ou application/json attendu"))
+		return
+	}
+	if parseErr != nil {
+		c.Error(parseErr)
+		return
+	}
+	if len(rows) > maxImportRows {
+		c.Error(apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Au plus %d lignes par import").WithArgs(maxImportRows))
+		return
+	}
+
+	// Les lignes invalides sont écartées avant l'enregistrement, avec leur motif traduit
+	lang := i18n.FromContext(c)
+	resources := make([]*models.Resource, len(rows))
+	reasons := make([]string, len(rows))
+	invalid := 0
+	for i, parsed := range rows {
+		if parsed.err != nil {
+			reasons[i] = parsed.err.Localize(lang).Message
+			invalid++
+			continue
+		}
+		resource, msg := parsed.row.resource(lang)
+		if msg != "" {
+			reasons[i] = msg
+			invalid++
+			continue
+		}
+		resources[i] = &resource
+	}
+
+	results, committed, err := h.catalog.Import(resources, func(results []service.ImportResult) bool {
+		if report.DryRun {
+			return false
+		}
+		failed := invalid
+		for _, result := range results {
+			if result.Status == service.ImportFailed {
+				failed++
+			}
+		}
+		return !report.Atomic || failed == 0
+	})
+	if err != nil {
+		c.Error(apierror.Internal("Erreur lors de l'import", err))
+		return
+	}
+	report.Committed = committed
+
+	for i, parsed := range rows {
+		row := ImportRowResult{Row: i + 1, Title: strings.TrimSpace(parsed.row.Title)}
+		if resources[i] == nil {
+			row.Status, row.Reason = service.ImportFailed, reasons[i]
+		} else {
+			row.Status, row.ID = results[i].Status, results[i].ID
+			if results[i].Reason != "" {
+				row.Reason = i18n.T(lang, results[i].Reason)
+			}
+		}
+		switch row.Status {
+		case service.ImportCreated:
+			report.Created++
+			if report.DryRun {
+				row.ID = 0
+			}
+		case service.ImportSkipped:
+			report.Skipped++
+		case service.ImportFailed:
+			report.Errors++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	if report.Atomic && report.Errors > 0 && !report.DryRun {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
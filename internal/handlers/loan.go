@@ -3,173 +3,143 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	"time"
 
-	"awesomeProject/internal/database"
-	"awesomeProject/internal/models"
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// LoanHandler regroupe les handlers de prêt, de réservation et d'amende, qui dépendent
+// des règles de prêt et de l'heure courante.
+type LoanHandler struct {
+	loans    *service.LoanService
+	holds    *service.HoldService
+	fines    *service.FineService
+	policies *service.PolicyService
+}
+
+// NewLoanHandler crée les handlers de prêt sur la base fournie, avec la configuration et l'horloge fournies.
+func NewLoanHandler(db *gorm.DB, cfg config.LoanConfig, clk clock.Clock) *LoanHandler {
+	store := repository.NewGormStore(db)
+	return &LoanHandler{
+		loans:    service.NewLoanService(store, cfg, clk),
+		holds:    service.NewHoldService(store, cfg, clk),
+		fines:    service.NewFineService(store, clk),
+		policies: service.NewPolicyService(store),
+	}
+}
+
 // CreateLoanInput définit le format attendu pour la création d'un prêt.
 type CreateLoanInput struct {
 	ResourceID uint   `json:"resource_id" binding:"required"`
+	CopyID     uint   `json:"copy_id"` // Optionnel : exemplaire précis, sinon le premier disponible
 	BorrowType string `json:"borrow_type" binding:"required,oneof=sur_place a_emporter"`
 }
 
 // CreateLoan gère la création d'un nouveau prêt pour une ressource.
-func CreateLoan(c *gin.Context) {
-	// Récupérer l'ID de l'utilisateur depuis le contexte
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non authentifié"})
-		return
-	}
-	userID, ok := userIDInterface.(uint)
+// Un adhérent dont la réservation est prête emprunte l'exemplaire mis de côté pour lui.
+func (h *LoanHandler) CreateLoan(c *gin.Context) {
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
 		return
 	}
 
 	// Lier les données JSON à notre input
 	var input CreateLoanInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Vérifier que la ressource existe et est disponible
-	var resource models.Resource
-	if err := database.DB.First(&resource, input.ResourceID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Ressource non trouvée"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la recherche de la ressource"})
-		}
+		c.Error(apierror.Binding(err))
 		return
 	}
 
-	if resource.Status != "disponible" {
-		c.JSON(http.StatusConflict, gin.H{"error": "La ressource n'est pas disponible"})
-		return
-	}
-
-	// Définir la date de prêt et la date de retour en fonction du type d'emprunt
-	loanDate := time.Now()
-	var returnDate time.Time
-	if input.BorrowType == "a_emporter" {
-		returnDate = loanDate.Add(15 * 24 * time.Hour) // maximum de 15 jours
-	} else {
-		// Pour un prêt sur place, on peut définir la date de retour égale à la date d'emprunt (ou autre logique)
-		returnDate = loanDate
-	}
-
-	// Créer le prêt
-	loan := models.Loan{
-		UserID:     userID,
+	loan, err := h.loans.Borrow(actor, service.BorrowRequest{
 		ResourceID: input.ResourceID,
-		LoanDate:   loanDate,
-		ReturnDate: returnDate,
-		Status:     "en_cours",
-	}
-	if err := database.DB.Create(&loan).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création du prêt"})
-		return
-	}
-
-	// Mettre à jour le statut de la ressource en "emprunté"
-	resource.Status = "emprunté"
-	if err := database.DB.Save(&resource).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour de la ressource"})
+		CopyID:     input.CopyID,
+		BorrowType: input.BorrowType,
+	})
+	if err != nil {
+		respondError(c, err, "Erreur lors de la création du prêt")
 		return
 	}
-
+	logging.FromContext(c).Event(c, "loan.created", "loan_id", loan.ID, "resource_id", loan.ResourceID,
+		"copy_id", loan.CopyID, "borrower_id", loan.UserID, "borrow_type", loan.BorrowType, "due", loan.ReturnDate)
 	c.JSON(http.StatusCreated, loan)
 }
 
 // GetLoans récupère la liste des prêts de l'utilisateur connecté.
-func GetLoans(c *gin.Context) {
-	// Récupérer l'ID de l'utilisateur depuis le contexte
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non authentifié"})
-		return
-	}
-	userID, ok := userIDInterface.(uint)
+// Le personnel peut consulter les prêts d'un autre utilisateur avec le paramètre "user_id".
+func (h *LoanHandler) GetLoans(c *gin.Context) {
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
 		return
 	}
 
-	// Récupérer les prêts de l'utilisateur
-	var loans []models.Loan
-	if err := database.DB.Where("user_id = ?", userID).Find(&loans).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des prêts"})
-		return
+	var userID uint
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		otherID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil || otherID == 0 {
+			c.Error(apierror.InvalidParam("user_id", "ID d'utilisateur invalide"))
+			return
+		}
+		userID = uint(otherID)
 	}
 
+	loans, err := h.loans.List(actor, userID)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la récupération des prêts")
+		return
+	}
 	c.JSON(http.StatusOK, loans)
 }
 
 // ReturnLoan permet de marquer le retour d'une ressource empruntée.
-func ReturnLoan(c *gin.Context) {
+// Si des adhérents attendent la ressource, l'exemplaire rendu est mis de côté pour le premier de la file.
+func (h *LoanHandler) ReturnLoan(c *gin.Context) {
 	// Récupérer l'ID du prêt depuis les paramètres d'URL
-	loanIDStr := c.Param("id")
-	loanID, err := strconv.ParseUint(loanIDStr, 10, 32)
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de prêt invalide"})
+		c.Error(apierror.InvalidParam("id", "ID de prêt invalide"))
 		return
 	}
-
-	// Récupérer l'ID de l'utilisateur depuis le contexte (pour vérifier l'appartenance du prêt)
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non authentifié"})
-		return
-	}
-	userID, ok := userIDInterface.(uint)
+	actor, ok := currentActor(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne"})
 		return
 	}
 
-	// Récupérer le prêt
-	var loan models.Loan
-	if err := database.DB.First(&loan, uint(loanID)).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Prêt non trouvé"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la recherche du prêt"})
-		}
+	loan, err := h.loans.Return(actor, uint(loanID))
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour du prêt")
 		return
 	}
+	logging.FromContext(c).Event(c, "loan.returned", "loan_id", loan.ID, "resource_id", loan.ResourceID,
+		"copy_id", loan.CopyID, "borrower_id", loan.UserID, "status", loan.Status)
+	c.JSON(http.StatusOK, loan)
+}
 
-	// Vérifier que le prêt appartient bien à l'utilisateur (optionnel selon votre logique métier)
-	if loan.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Accès non autorisé"})
+// RenewLoan repousse la date de retour d'un prêt selon sa politique de prêt.
+// Le renouvellement est refusé si le prêt est en retard, si le nombre maximal de renouvellements
+// est atteint ou si un autre adhérent a réservé la ressource.
+func (h *LoanHandler) RenewLoan(c *gin.Context) {
+	loanID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de prêt invalide"))
 		return
 	}
-
-	// Vérifier que le prêt est en cours
-	if loan.Status != "en_cours" {
-		c.JSON(http.StatusConflict, gin.H{"error": "Le prêt est déjà retourné"})
+	actor, ok := currentActor(c)
+	if !ok {
 		return
 	}
 
-	// Marquer le prêt comme retourné
-	loan.Status = "retourné"
-	if err := database.DB.Save(&loan).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour du prêt"})
+	loan, err := h.loans.Renew(actor, uint(loanID))
+	if err != nil {
+		respondError(c, err, "Erreur lors du renouvellement du prêt")
 		return
 	}
-
-	// Mettre à jour le statut de la ressource associée en "disponible"
-	var resource models.Resource
-	if err := database.DB.First(&resource, loan.ResourceID).Error; err == nil {
-		resource.Status = "disponible"
-		_ = database.DB.Save(&resource)
-	}
-
+	logging.FromContext(c).Event(c, "loan.renewed", "loan_id", loan.ID, "renewals", loan.RenewalCount, "due", loan.ReturnDate)
 	c.JSON(http.StatusOK, loan)
 }
 
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/models"
+)
+
+// cleanList retire les espaces superflus, les valeurs vides et les doublons d'une liste saisie.
+func cleanList(values []string) []string {
+	var cleaned []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[strings.ToLower(value)] {
+			continue
+		}
+		seen[strings.ToLower(value)] = true
+		cleaned = append(cleaned, value)
+	}
+	return cleaned
+}
+
+// validateMetadata normalise et vérifie les métadonnées d'une ressource : une notice de livre
+// pour un livre, une fiche de jeu pour un jeu. Retourne l'erreur de validation du champ refusé, ou nil.
+func validateMetadata(resource *models.Resource) *apierror.Error {
+	if resource.Book != nil && resource.Type != models.ResourceBook {
+		return apierror.Invalid("Book", "excluded", "Book est réservé aux ressources de type Livre")
+	}
+	if resource.Game != nil && resource.Type != models.ResourceGame {
+		return apierror.Invalid("Game", "excluded", "Game est réservé aux ressources de type Jeu")
+	}
+	if resource.Book != nil {
+		return validateBook(resource.Book)
+	}
+	if resource.Game != nil {
+		return validateGame(resource.Game)
+	}
+	return nil
+}
+
+// validateBook normalise et vérifie la notice d'un livre.
+func validateBook(book *models.BookDetails) *apierror.Error {
+	book.Authors = cleanList(book.Authors)
+	book.Genres = cleanList(book.Genres)
+	book.Publisher = strings.TrimSpace(book.Publisher)
+	book.Language = strings.ToLower(strings.TrimSpace(book.Language))
+
+	if book.ISBN != nil {
+		isbn := models.NormalizeISBN(*book.ISBN)
+		if isbn == "" {
+			book.ISBN = nil
+		} else if !models.IsValidISBN13(isbn) {
+			return apierror.Invalid("Book.ISBN", "isbn13", "ISBN invalide : ISBN-13 avec clé de contrôle attendu")
+		} else {
+			book.ISBN = &isbn
+		}
+	}
+	if book.Year < 0 || book.Year > time.Now().Year()+1 {
+		return apierror.Invalid("Book.Year", "range", "Année de publication invalide")
+	}
+	if book.Language != "" && !isLanguageCode(book.Language) {
+		return apierror.Invalid("Book.Language", "iso639_1", "Langue invalide : code ISO 639-1 attendu, par exemple fr")
+	}
+	if book.CoverURL != "" {
+		u, err := url.Parse(book.CoverURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return apierror.Invalid("Book.CoverURL", "url", "URL de couverture invalide")
+		}
+	}
+	return nil
+}
+
+// isLanguageCode indique si le code a la forme d'un code de langue ISO 639-1 (deux lettres minuscules).
+func isLanguageCode(code string) bool {
+	return len(code) == 2 && code[0] >= 'a' && code[0] <= 'z' && code[1] >= 'a' && code[1] <= 'z'
+}
+
+// validateGame normalise et vérifie la fiche d'un jeu.
+func validateGame(game *models.GameDetails) *apierror.Error {
+	game.Designer = strings.TrimSpace(game.Designer)
+
+	if game.MinPlayers < 0 || game.MaxPlayers < 0 || (game.MaxPlayers > 0 && game.MaxPlayers < game.MinPlayers) {
+		return apierror.Invalid("Game.MaxPlayers", "range", "Nombre de joueurs invalide")
+	}
+	if game.PlayTime < 0 {
+		return apierror.Invalid("Game.PlayTime", "gte", "Durée de partie invalide")
+	}
+	if game.MinAge < 0 || game.MinAge > 99 {
+		return apierror.Invalid("Game.MinAge", "range", "Âge minimal invalide")
+	}
+	if game.Complexity != 0 && (game.Complexity < 1 || game.Complexity > 5) {
+		return apierror.Invalid("Game.Complexity", "range", "Complexité invalide : de 1 à 5 attendu")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"awesomeProject/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// parsePage lit les paramètres "page" et "per_page". Retourne une erreur désignant le paramètre invalide.
+func parsePage(c *gin.Context) (page, perPage int, err error) {
+	page, perPage = 1, defaultPerPage
+	if value := c.Query("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, 0, apierror.InvalidParam("page", "page doit être un entier positif")
+		}
+		page = n
+	}
+	if value := c.Query("per_page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPerPage {
+			return 0, 0, apierror.InvalidParam("per_page", "per_page doit être compris entre 1 et %d").WithArgs(maxPerPage)
+		}
+		perPage = n
+	}
+	return page, perPage, nil
+}
+
+// setPaginationHeaders renseigne le nombre total de résultats (X-Total-Count) et les liens
+// vers les pages voisines (Link, RFC 8288), en conservant les autres paramètres de la requête.
+func setPaginationHeaders(c *gin.Context, page, perPage int, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	last := int((total + int64(perPage) - 1) / int64(perPage))
+	if last < 1 {
+		last = 1
+	}
+	link := func(target int, rel string) string {
+		u := *c.Request.URL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(target))
+		query.Set("per_page", strconv.Itoa(perPage))
+		u.RawQuery = query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if page > 1 {
+		links = append(links, link(min(page-1, last), "prev"))
+	}
+	if page < last {
+		links = append(links, link(page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+	c.Header("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// LoanPolicyInput définit le format attendu pour la création ou la modification d'une politique de prêt.
+type LoanPolicyInput struct {
+	ResourceType       string `json:"resource_type" binding:"required"`
+	BorrowType         string `json:"borrow_type" binding:"required,oneof=sur_place a_emporter"`
+	Allowed            *bool  `json:"allowed"` // Optionnel : autorisé par défaut
+	LoanDays           int    `json:"loan_days" binding:"gte=0"`
+	MaxConcurrentLoans int    `json:"max_concurrent_loans" binding:"gte=0"`
+	MaxRenewals        int    `json:"max_renewals" binding:"gte=0"`
+	RenewalDays        int    `json:"renewal_days" binding:"gte=0"`
+	FineDailyRate      int    `json:"fine_daily_rate" binding:"gte=0"`
+	FineCap            int    `json:"fine_cap" binding:"gte=0"`
+}
+
+// apply recopie les valeurs saisies dans la politique.
+func (input LoanPolicyInput) apply(policy *models.LoanPolicy) {
+	policy.ResourceType = input.ResourceType
+	policy.BorrowType = input.BorrowType
+	policy.Allowed = input.Allowed == nil || *input.Allowed
+	policy.LoanDays = input.LoanDays
+	policy.MaxConcurrentLoans = input.MaxConcurrentLoans
+	policy.MaxRenewals = input.MaxRenewals
+	policy.RenewalDays = input.RenewalDays
+	policy.FineDailyRate = input.FineDailyRate
+	policy.FineCap = input.FineCap
+}
+
+// validateLoanPolicy vérifie la cohérence d'une politique. Retourne l'erreur de validation du champ refusé, ou nil.
+func validateLoanPolicy(policy models.LoanPolicy) *apierror.Error {
+	if policy.MaxRenewals > 0 && policy.RenewalDays == 0 {
+		return apierror.Invalid("renewal_days", "required_if", "renewal_days est requis quand max_renewals est positif")
+	}
+	return nil
+}
+
+// GetLoanPolicies liste les politiques de prêt enregistrées.
+// Les combinaisons absentes suivent les règles par défaut de la configuration.
+func (h *LoanHandler) GetLoanPolicies(c *gin.Context) {
+	policies, err := h.policies.List()
+	if err != nil {
+		c.Error(apierror.Internal("Erreur lors de la récupération des politiques de prêt", err))
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+// CreateLoanPolicy enregistre la politique de prêt d'un type de ressource et d'un type d'emprunt.
+func (h *LoanHandler) CreateLoanPolicy(c *gin.Context) {
+	var input LoanPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+	var policy models.LoanPolicy
+	input.apply(&policy)
+	h.saveLoanPolicy(c, &policy, http.StatusCreated, h.policies.Create)
+}
+
+// UpdateLoanPolicy remplace les règles d'une politique de prêt.
+func (h *LoanHandler) UpdateLoanPolicy(c *gin.Context) {
+	policyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de politique invalide"))
+		return
+	}
+
+	var input LoanPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+
+	var policy models.LoanPolicy
+	input.apply(&policy)
+	h.saveLoanPolicy(c, &policy, http.StatusOK, func(policy *models.LoanPolicy) error {
+		return h.policies.Update(uint(policyID), policy)
+	})
+}
+
+// saveLoanPolicy valide puis enregistre une politique avec save ; une seule politique par type de ressource et d'emprunt.
+func (h *LoanHandler) saveLoanPolicy(c *gin.Context, policy *models.LoanPolicy, status int, save func(*models.LoanPolicy) error) {
+	if err := validateLoanPolicy(*policy); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := save(policy); err != nil {
+		respondError(c, err, "Erreur lors de l'enregistrement de la politique de prêt")
+		return
+	}
+	c.JSON(status, policy)
+}
+
+// DeleteLoanPolicy supprime une politique de prêt : les règles par défaut s'appliquent de nouveau.
+func (h *LoanHandler) DeleteLoanPolicy(c *gin.Context) {
+	policyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de politique invalide"))
+		return
+	}
+
+	if err := h.policies.Delete(uint(policyID)); err != nil {
+		respondError(c, err, "Erreur lors de la suppression de la politique de prêt")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.FromContext(c), "Politique de prêt supprimée")})
+}
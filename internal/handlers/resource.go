@@ -1,55 +1,142 @@
 package handlers
 
 import (
-	"errors"
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"awesomeProject/internal/database"
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
 	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
 )
 
-// GetResources récupère la liste des ressources (livres et jeux).
-func GetResources(c *gin.Context) {
-	var resources []models.Resource
-	// Récupère toutes les ressources de la base de données.
-	if err := database.DB.Find(&resources).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Impossible de récupérer les ressources"})
+// CatalogHandler regroupe les handlers du catalogue : ressources, exemplaires, recherche, import et export.
+type CatalogHandler struct {
+	catalog *service.CatalogService
+}
+
+// NewCatalogHandler crée les handlers du catalogue sur la base fournie.
+func NewCatalogHandler(db *gorm.DB) *CatalogHandler {
+	return &CatalogHandler{catalog: service.NewCatalogService(repository.NewGormStore(db))}
+}
+
+// GetResources récupère une page de la liste des ressources (livres et jeux), avec leurs métadonnées.
+// Paramètres : "q" (mots du titre), "type", "status", "sort" (id, title, type, status),
+// "order" (asc ou desc), "page" et "per_page". Filtres sur les métadonnées : "author", "language",
+// "genre" et "year" pour les livres ; "players", "max_play_time" et "age" pour les jeux. Le nombre total de résultats est renvoyé
+// dans l'en-tête X-Total-Count et les liens vers les pages voisines dans l'en-tête Link.
+func (h *CatalogHandler) GetResources(c *gin.Context) {
+	query := service.ResourceQuery{
+		Search: c.Query("q"),
+		Type:   c.Query("type"),
+		Status: models.ResourceStatus(c.Query("status")),
+		Sort:   c.DefaultQuery("sort", "title"),
+
+		Author:   c.Query("author"),
+		Language: strings.ToLower(c.Query("language")),
+		Genre:    c.Query("genre"),
+	}
+	for param, target := range map[string]*int{
+		"year":          &query.Year,
+		"players":       &query.Players,
+		"max_play_time": &query.MaxPlayTime,
+		"age":           &query.Age,
+	} {
+		if value := c.Query(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				c.Error(apierror.InvalidParam(param, "%s doit être un entier positif").WithArgs(param))
+				return
+			}
+			*target = n
+		}
+	}
+	if query.Status != "" && !query.Status.IsValid() {
+		c.Error(apierror.InvalidParam("status", "Statut invalide"))
+		return
+	}
+	if _, ok := service.ResourceSortFields[query.Sort]; !ok {
+		c.Error(apierror.InvalidParam("sort", "Tri invalide : id, title, type ou status attendu"))
+		return
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		c.Error(apierror.InvalidParam("order", "Ordre invalide : asc ou desc attendu"))
 		return
 	}
+	var err error
+	if query.Page, query.PerPage, err = parsePage(c); err != nil {
+		c.Error(err)
+		return
+	}
+
+	resources, total, err := h.catalog.Search(query)
+	if err != nil {
+		c.Error(apierror.Internal("Impossible de récupérer les ressources", err))
+		return
+	}
+	setPaginationHeaders(c, query.Page, query.PerPage, total)
 	c.JSON(http.StatusOK, resources)
 }
 
 // GetResource récupère les détails d'une ressource spécifique à partir de son ID.
-func GetResource(c *gin.Context) {
-	id := c.Param("id")
-	var resource models.Resource
-	// Recherche la ressource par son ID.
-	if err := database.DB.First(&resource, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Ressource non trouvée"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Impossible de récupérer la ressource"})
-		}
+func (h *CatalogHandler) GetResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	resource, err := h.catalog.Get(uint(id))
+	if err != nil {
+		respondError(c, err, "Impossible de récupérer la ressource")
 		return
 	}
 	c.JSON(http.StatusOK, resource)
 }
 
 // CreateResource permet d'ajouter une nouvelle ressource (livre ou jeu).
-func CreateResource(c *gin.Context) {
+// Les exemplaires peuvent être fournis dans "Copies" ; à défaut, un exemplaire unique est créé.
+// La notice d'un livre est fournie dans "Book", la fiche d'un jeu dans "Game".
+func (h *CatalogHandler) CreateResource(c *gin.Context) {
 	var resource models.Resource
 
 	// On tente de lier le JSON de la requête à notre structure Resource.
 	if err := c.ShouldBindJSON(&resource); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Mauvaise requête, vérifiez le format des données"})
+		c.Error(apierror.BadRequest(apierror.CodeMalformedBody, "Mauvaise requête, vérifiez le format des données"))
+		return
+	}
+
+	// Une nouvelle ressource est disponible ou indisponible, jamais empruntée.
+	if resource.Status == "" {
+		resource.Status = models.ResourceAvailable
+	}
+	if resource.Status != models.ResourceAvailable && resource.Status != models.ResourceUnavailable {
+		c.Error(apierror.Invalid("Status", "oneof", "Statut invalide : disponible ou indisponible attendu"))
+		return
+	}
+	for _, copy := range resource.Copies {
+		if !validateCopy(c, copy) {
+			return
+		}
+	}
+	if err := validateMetadata(&resource); err != nil {
+		c.Error(err)
 		return
 	}
 
-	// On insère la ressource dans la base de données.
-	if err := database.DB.Create(&resource).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de la ressource"})
+	// On insère la ressource, ses métadonnées et ses exemplaires dans la base de données.
+	if err := h.catalog.Create(&resource); err != nil {
+		respondError(c, err, "Erreur lors de la création de la ressource")
 		return
 	}
 
@@ -57,26 +144,28 @@ func CreateResource(c *gin.Context) {
 	c.JSON(http.StatusCreated, resource)
 }
 
-// DisableResource met à jour une ressource en "indisponible".
-func DisableResource(c *gin.Context) {
-	var resource models.Resource
-	id := c.Param("id")
+// DisableResource retire du prêt tous les exemplaires disponibles d'une ressource.
+func (h *CatalogHandler) DisableResource(c *gin.Context) {
+	h.updateResourceStatus(c, models.ResourceDisable)
+}
 
-	// Récupérer la ressource dans la base de données
-	if err := database.DB.First(&resource, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Ressource introuvable"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération de la ressource"})
+// EnableResource remet en service tous les exemplaires retirés d'une ressource.
+// Un exemplaire emprunté ne peut pas être remis en disponible : seul le retour du prêt le permet.
+func (h *CatalogHandler) EnableResource(c *gin.Context) {
+	h.updateResourceStatus(c, models.ResourceEnable)
+}
+
+// updateResourceStatus applique une action du personnel en respectant la table de transitions.
+func (h *CatalogHandler) updateResourceStatus(c *gin.Context, action models.ResourceAction) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
 		return
 	}
 
-	// Mettre à jour le statut
-	resource.Status = "indisponible"
-
-	if err := database.DB.Save(&resource).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour de la ressource"})
+	resource, err := h.catalog.TransitionCopies(uint(id), action)
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour de la ressource")
 		return
 	}
 
@@ -84,31 +173,160 @@ func DisableResource(c *gin.Context) {
 	c.JSON(http.StatusOK, resource)
 }
 
-// EnableResource met à jour une ressource en "disponible".
-func EnableResource(c *gin.Context) {
-	var resource models.Resource
-	id := c.Param("id")
+// UpdateResourceInput définit les champs modifiables d'une ressource. Le statut se modifie
+// par les actions dédiées (retrait, remise en service) et les exemplaires par leurs propres routes.
+type UpdateResourceInput struct {
+	Title string              `json:"Title" binding:"required"`
+	Type  string              `json:"Type" binding:"required"`
+	Book  *models.BookDetails `json:"Book"` // Absente : la notice est supprimée
+	Game  *models.GameDetails `json:"Game"` // Absente : la fiche est supprimée
+}
 
-	// Récupérer la ressource dans la base de données
-	if err := database.DB.First(&resource, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Ressource introuvable"})
+// UpdateResource remplace le titre, le type et les métadonnées d'une ressource (PUT /resources/:id).
+func (h *CatalogHandler) UpdateResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	var input UpdateResourceInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+	h.saveResource(c, uint(id), input)
+}
+
+// PatchResource modifie une partie des champs d'une ressource (PATCH /resources/:id).
+// Le corps suit le format JSON Merge Patch (RFC 7386) : un champ absent est conservé,
+// un champ à null est supprimé et les métadonnées sont fusionnées champ par champ.
+func (h *CatalogHandler) PatchResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		c.Error(apierror.BadRequest(apierror.CodeMalformedBody, "Mauvaise requête : un objet JSON est attendu"))
+		return
+	}
+	for field := range patch {
+		if field != "Title" && field != "Type" && field != "Book" && field != "Game" {
+			c.Error(apierror.Invalid(field, "readonly", "Champ non modifiable : %s").WithArgs(field))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération de la ressource"})
+	}
+
+	current, err := h.catalog.Get(uint(id))
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour de la ressource")
 		return
 	}
 
-	// Mettre à jour le statut
-	resource.Status = "disponible"
+	// Appliquer le patch à l'état actuel, puis valider le résultat comme pour PUT
+	var document map[string]interface{}
+	encoded, _ := json.Marshal(UpdateResourceInput{Title: current.Title, Type: current.Type, Book: current.Book, Game: current.Game})
+	_ = json.Unmarshal(encoded, &document)
+	encoded, _ = json.Marshal(mergePatch(document, patch))
 
-	if err := database.DB.Save(&resource).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour de la ressource"})
+	var input UpdateResourceInput
+	if err := json.Unmarshal(encoded, &input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&input); err != nil {
+		c.Error(apierror.Binding(err))
 		return
 	}
+	h.saveResource(c, uint(id), input)
+}
 
-	// Retourner la ressource mise à jour
+// mergePatch applique un JSON Merge Patch (RFC 7386) à un document.
+func mergePatch(document map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	if document == nil {
+		document = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(document, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			documentObject, _ := document[key].(map[string]interface{})
+			document[key] = mergePatch(documentObject, patchObject)
+			continue
+		}
+		document[key] = value
+	}
+	return document
+}
+
+// saveResource valide puis enregistre les champs modifiables d'une ressource et répond avec la ressource à jour.
+func (h *CatalogHandler) saveResource(c *gin.Context, id uint, input UpdateResourceInput) {
+	input.Title = strings.TrimSpace(input.Title)
+	input.Type = strings.TrimSpace(input.Type)
+	if input.Title == "" {
+		c.Error(apierror.Invalid("Title", "required", "Le titre ne peut pas être vide"))
+		return
+	}
+	if input.Type == "" {
+		c.Error(apierror.Invalid("Type", "required", "Le type ne peut pas être vide"))
+		return
+	}
+	if err := validateMetadata(&models.Resource{Type: input.Type, Book: input.Book, Game: input.Game}); err != nil {
+		c.Error(err)
+		return
+	}
+
+	resource, err := h.catalog.Update(&models.Resource{ID: id, Title: input.Title, Type: input.Type, Book: input.Book, Game: input.Game})
+	if err != nil {
+		respondError(c, err, "Erreur lors de la mise à jour de la ressource")
+		return
+	}
 	c.JSON(http.StatusOK, resource)
+}
+
+// DeleteResource retire une ressource du catalogue. La suppression est logique : l'historique
+// des prêts est conservé et un administrateur peut la restaurer. Elle est refusée tant qu'un prêt est ouvert.
+func (h *CatalogHandler) DeleteResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	if err := h.catalog.Delete(uint(id)); err != nil {
+		respondError(c, err, "Erreur lors de la suppression de la ressource")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.FromContext(c), "Ressource supprimée")})
+}
 
+// GetDeletedResources liste les ressources supprimées (réservé aux administrateurs).
+func (h *CatalogHandler) GetDeletedResources(c *gin.Context) {
+	resources, err := h.catalog.Deleted()
+	if err != nil {
+		c.Error(apierror.Internal("Impossible de récupérer les ressources supprimées", err))
+		return
+	}
+	c.JSON(http.StatusOK, resources)
+}
 
+// RestoreResource remet au catalogue une ressource supprimée (réservé aux administrateurs).
+func (h *CatalogHandler) RestoreResource(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apierror.InvalidParam("id", "ID de ressource invalide"))
+		return
+	}
+
+	resource, err := h.catalog.Restore(uint(id))
+	if err != nil {
+		respondError(c, err, "Erreur lors de la restauration de la ressource")
+		return
+	}
+	c.JSON(http.StatusOK, resource)
 }
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"awesomeProject/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// Search recherche dans le catalogue par mots du titre, sans tenir compte des accents ni de la casse.
+// Paramètres : "q" (obligatoire), "type", "page" et "per_page". Les résultats sont classés par pertinence,
+// avec un extrait où les termes trouvés sont entourés de <mark>.
+func (h *CatalogHandler) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.Error(apierror.Invalid("q", "required", "Le paramètre q est requis"))
+		return
+	}
+	page, perPage, err := parsePage(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	hits, total, err := h.catalog.FullTextSearch(q, c.Query("type"), page, perPage)
+	if err != nil {
+		c.Error(apierror.Internal("Erreur lors de la recherche", err))
+		return
+	}
+	setPaginationHeaders(c, page, perPage, total)
+	c.JSON(http.StatusOK, hits)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/seed"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxSeedMembers borne le nombre d'adhérents générés par une requête.
+const maxSeedMembers = 500
+
+// SeedHandler remplit la base avec le jeu de données de développement.
+// La route n'est enregistrée qu'en dehors de la production.
+type SeedHandler struct {
+	db    *gorm.DB
+	cfg   config.LoanConfig
+	clock clock.Clock
+}
+
+// NewSeedHandler crée le handler du jeu de données sur la base fournie, avec les règles de prêt et l'horloge fournies.
+func NewSeedHandler(db *gorm.DB, cfg config.LoanConfig, clk clock.Clock) *SeedHandler {
+	return &SeedHandler{db: db, cfg: cfg, clock: clk}
+}
+
+// Seed crée le jeu de données décrit par les paramètres seed (1 par défaut) et members
+// (nombre d'adhérents, 20 par défaut), daté de l'heure courante.
+func (h *SeedHandler) Seed(c *gin.Context) {
+	opts := seed.Options{Seed: 1, Members: seed.DefaultMembers, Now: h.clock.Now(), Loans: h.cfg}
+	if value := c.Query("seed"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.Error(apierror.InvalidParam("seed", "seed doit être un entier"))
+			return
+		}
+		opts.Seed = n
+	}
+	if value := c.Query("members"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxSeedMembers {
+			c.Error(apierror.InvalidParam("members", "members doit être compris entre 1 et %d").WithArgs(maxSeedMembers))
+			return
+		}
+		opts.Members = n
+	}
+
+	summary, err := seed.Run(h.db, opts)
+	if err != nil {
+		c.Error(apierror.Internal("Erreur lors de la création du jeu de données", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.FromContext(c), "Jeu de données inséré"), "seed": opts.Seed, "created": summary})
+}
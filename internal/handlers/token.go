@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AuthHandler regroupe les handlers d'inscription, de connexion et de gestion des tokens.
+type AuthHandler struct {
+	users    *service.UserService
+	sessions *service.SessionService
+}
+
+// NewAuthHandler crée les handlers d'authentification à partir de la configuration, sur la base fournie.
+func NewAuthHandler(cfg config.AuthConfig, db *gorm.DB) *AuthHandler {
+	store := repository.NewGormStore(db)
+	return &AuthHandler{users: service.NewUserService(store), sessions: service.NewSessionService(store, cfg)}
+}
+
+// RefreshInput définit les données attendues pour le rafraîchissement et la déconnexion.
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshAccessToken échange un token de rafraîchissement contre une nouvelle paire de tokens.
+// Le token présenté est révoqué (rotation). S'il avait déjà été utilisé, toutes les sessions
+// de l'utilisateur sont révoquées, car le token a probablement été volé.
+func (h *AuthHandler) RefreshAccessToken(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apierror.Binding(err))
+		return
+	}
+
+	pair, err := h.sessions.Refresh(input.RefreshToken)
+	var reused *service.TokenReuseError
+	if errors.As(err, &reused) {
+		logging.FromContext(c).Event(c, "auth.refresh_token_reused", "target_id", reused.UserID)
+	}
+	if err != nil {
+		respondError(c, err, "Erreur lors du rafraîchissement du token")
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// Logout révoque le token d'accès courant et, s'il est fourni, le token de rafraîchissement associé.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	// Le token de rafraîchissement est optionnel
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.Error(apierror.Binding(err))
+			return
+		}
+	}
+
+	err := h.sessions.Logout(c.GetUint("userID"), c.GetString("tokenID"), c.GetTime("tokenExpiresAt"), input.RefreshToken)
+	if err != nil {
+		c.Error(apierror.Internal("Erreur lors de la déconnexion", err))
+		return
+	}
+
+	logging.FromContext(c).Event(c, "auth.logout")
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(i18n.FromContext(c), "Déconnexion réussie")})
+}
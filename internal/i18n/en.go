@@ -0,0 +1,175 @@
+package i18n
+
+// en est le catalogue anglais, indexé par le message français.
+var en = map[string]string{
+	// Authentification et comptes
+	"Accès non autorisé":                           "Access denied",
+	"Connexion réussie":                            "Signed in",
+	"Déconnexion réussie":                          "Signed out",
+	"Email déjà utilisé":                           "Email already in use",
+	"Email ou mot de passe invalide":               "Invalid email or password",
+	"Impossible de modifier son propre rôle":       "You cannot change your own role",
+	"Langue invalide":                              "Invalid language",
+	"Rôle invalide":                                "Invalid role",
+	"Sessions révoquées":                           "Sessions revoked",
+	"Token d'authentification manquant":            "Missing authentication token",
+	"Token de rafraîchissement invalide ou expiré": "Invalid or expired refresh token",
+	"Token invalide ou expiré":                     "Invalid or expired token",
+	"Token révoqué":                                "Token revoked",
+	"Utilisateur existant":                         "User already exists",
+	"Utilisateur non authentifié":                  "User not authenticated",
+	"Utilisateur non trouvé":                       "User not found",
+
+	// Requêtes et validation
+	"%s doit être un entier positif":                                   "%s must be a positive integer",
+	"Adresse email invalide":                                           "Invalid email address",
+	"Au moins %s caractères attendus":                                  "At least %s characters expected",
+	"Au plus %d lignes par import":                                     "At most %d rows per import",
+	"Au plus %s caractères attendus":                                   "At most %s characters expected",
+	"Champ non modifiable : %s":                                        "Field cannot be modified: %s",
+	"Champ requis":                                                     "Required field",
+	"Doit être inférieur ou égal à %s":                                 "Must be less than or equal to %s",
+	"Doit être inférieur à %s":                                         "Must be less than %s",
+	"Doit être supérieur ou égal à %s":                                 "Must be greater than or equal to %s",
+	"Doit être supérieur à %s":                                         "Must be greater than %s",
+	"Données invalides":                                                "Invalid data",
+	"Fichier trop volumineux":                                          "File too large",
+	"Format invalide : csv ou json attendu":                            "Invalid format: csv or json expected",
+	"Format non pris en charge : text/csv ou application/json attendu": "Unsupported format: text/csv or application/json expected",
+	"ID d'exemplaire invalide":                                         "Invalid copy ID",
+	"ID d'utilisateur invalide":                                        "Invalid user ID",
+	"ID de politique invalide":                                         "Invalid policy ID",
+	"ID de prêt invalide":                                              "Invalid loan ID",
+	"ID de ressource invalide":                                         "Invalid resource ID",
+	"ID de réservation invalide":                                       "Invalid hold ID",
+	"La date de début doit précéder la date de fin":                    "The start date must precede the end date",
+	"Le paramètre q est requis":                                        "The q parameter is required",
+	"Mauvaise requête : un objet JSON est attendu":                     "Bad request: a JSON object is expected",
+	"Mauvaise requête, vérifiez le format des données":                 "Bad request, check the data format",
+	"Montant invalide":                                                 "Invalid amount",
+	"Ordre invalide : asc ou desc attendu":                             "Invalid order: asc or desc expected",
+	"Route inconnue":                                                   "Unknown route",
+	"Statut invalide":                                                  "Invalid status",
+	"Statut invalide : disponible ou indisponible attendu":             "Invalid status: disponible or indisponible expected",
+	"Séparateur invalide : comma ou semicolon attendu":                 "Invalid delimiter: comma or semicolon expected",
+	"Tri invalide : id, title, type ou status attendu":                 "Invalid sort: id, title, type or status expected",
+	"Type de valeur invalide":                                          "Invalid value type",
+	"Valeur invalide":                                                  "Invalid value",
+	"Valeur invalide : %s attendu":                                     "Invalid value: %s expected",
+	"from doit être une date AAAA-MM-JJ":                               "from must be a YYYY-MM-DD date",
+	"members doit être compris entre 1 et %d":                          "members must be between 1 and %d",
+	"page doit être un entier positif":                                 "page must be a positive integer",
+	"per_page doit être compris entre 1 et %d":                         "per_page must be between 1 and %d",
+	"seed doit être un entier":                                         "seed must be an integer",
+	"to doit être une date AAAA-MM-JJ":                                 "to must be a YYYY-MM-DD date",
+
+	// Catalogue
+	"Année de publication invalide":                                                "Invalid publication year",
+	"Aucune ressource supprimée avec cet ID":                                       "No deleted resource with this ID",
+	"Book est réservé aux ressources de type Livre":                                "Book is reserved for resources of type Livre",
+	"Ce code-barres est déjà utilisé":                                              "This barcode is already in use",
+	"Cet ISBN est déjà attribué à une autre ressource":                             "This ISBN is already assigned to another resource",
+	"Changement de statut impossible":                                              "Status change not allowed",
+	"Complexité invalide : de 1 à 5 attendu":                                       "Invalid complexity: 1 to 5 expected",
+	"Des exemplaires sont encore prêtés : la ressource ne peut pas être supprimée": "Copies are still on loan: the resource cannot be deleted",
+	"Durée de partie invalide":                                                     "Invalid play time",
+	"Game est réservé aux ressources de type Jeu":                                  "Game is reserved for resources of type Jeu",
+	"ISBN invalide : ISBN-13 avec clé de contrôle attendu":                         "Invalid ISBN: ISBN-13 with check digit expected",
+	"Jeu de données inséré":                                                        "Dataset inserted",
+	"Langue invalide : code ISO 639-1 attendu, par exemple fr":                     "Invalid language: ISO 639-1 code expected, for example en",
+	"Le statut se modifie via les actions disable / enable":                        "The status is changed with the disable / enable actions",
+	"Le titre ne peut pas être vide":                                               "The title cannot be empty",
+	"Le type ne peut pas être vide":                                                "The type cannot be empty",
+	"Nombre de joueurs invalide":                                                   "Invalid number of players",
+	"Ressource introuvable":                                                        "Resource not found",
+	"Ressource non trouvée":                                                        "Resource not found",
+	"Ressource ou exemplaire non trouvé":                                           "Resource or copy not found",
+	"Ressource supprimée":                                                          "Resource deleted",
+	"URL de couverture invalide":                                                   "Invalid cover URL",
+	"Âge minimal invalide":                                                         "Invalid minimum age",
+	"État invalide : neuf, bon, usé ou abîmé attendu":                              "Invalid condition: neuf, bon, usé or abîmé expected",
+
+	// Import
+	"%s : champ inconnu":                              "%s: unknown field",
+	"%s : nombre attendu":                             "%s: number expected",
+	"%s : nombre entier attendu":                      "%s: integer expected",
+	"%s : type de valeur invalide":                    "%s: invalid value type",
+	"CSV illisible":                                   "unreadable CSV",
+	"CSV invalide : guillemet mal placé ligne %d":     "invalid CSV: misplaced quote on line %d",
+	"JSON invalide : un tableau d'objets est attendu": "Invalid JSON: an array of objects is expected",
+	"colonne inconnue : %q":                           "unknown column: %q",
+	"copies : entre 1 et %d attendu":                  "copies: between 1 and %d expected",
+	"doublon d'une ligne précédente":                  "duplicate of a previous row",
+	"déjà au catalogue":                               "already in the catalogue",
+	"en-tête CSV illisible":                           "unreadable CSV header",
+	"enregistrement impossible":                       "could not be saved",
+	"ligne CSV illisible":                             "unreadable CSV row",
+	"objet JSON illisible":                            "unreadable JSON object",
+	"plus de valeurs que de colonnes":                 "more values than columns",
+	"status : disponible ou indisponible attendu":     "status: disponible or indisponible expected",
+	"title : obligatoire":                             "title: required",
+	"type : obligatoire":                              "type: required",
+
+	// Prêts, réservations et amendes
+	"Amendes impayées : réglez votre solde avant d'emprunter":          "Unpaid fines: settle your balance before borrowing",
+	"Ce prêt ne peut pas être renouvelé":                               "This loan cannot be renewed",
+	"Ce type d'emprunt n'est pas autorisé pour cette ressource":        "This borrow type is not allowed for this resource",
+	"La ressource est réservée par un autre adhérent":                  "The resource is on hold for another member",
+	"La ressource n'est pas disponible":                                "The resource is not available",
+	"La réservation n'est plus active":                                 "The hold is no longer active",
+	"Le montant dépasse le solde dû":                                   "The amount exceeds the balance due",
+	"Le prêt est déjà retourné":                                        "The loan has already been returned",
+	"Le prêt est en retard et ne peut plus être renouvelé":             "The loan is overdue and can no longer be renewed",
+	"Le statut de la ressource ne permet pas le retour":                "The resource status does not allow a return",
+	"Nombre maximal de prêts simultanés atteint":                       "Maximum number of concurrent loans reached",
+	"Nombre maximal de renouvellements atteint":                        "Maximum number of renewals reached",
+	"Politique de prêt non trouvée":                                    "Loan policy not found",
+	"Politique de prêt supprimée":                                      "Loan policy deleted",
+	"Prêt non trouvé":                                                  "Loan not found",
+	"Réservation non trouvée":                                          "Hold not found",
+	"Seul un prêt en cours peut être renouvelé":                        "Only an active loan can be renewed",
+	"Un exemplaire est disponible : empruntez-le directement":          "A copy is available: borrow it directly",
+	"Une politique existe déjà pour ce type de ressource et d'emprunt": "A policy already exists for this resource and borrow type",
+	"Vous avez déjà une réservation pour cette ressource":              "You already have a hold on this resource",
+	"Vous empruntez déjà cette ressource":                              "You are already borrowing this resource",
+	"renewal_days est requis quand max_renewals est positif":           "renewal_days is required when max_renewals is positive",
+
+	// Erreurs internes
+	"Erreur interne": "Internal error",
+	"Erreur lors de l'annulation de la réservation":           "Error while cancelling the hold",
+	"Erreur lors de l'enregistrement":                         "Error while saving",
+	"Erreur lors de l'enregistrement de la politique de prêt": "Error while saving the loan policy",
+	"Erreur lors de l'export des prêts":                       "Error while exporting loans",
+	"Erreur lors de l'export du catalogue":                    "Error while exporting the catalogue",
+	"Erreur lors de l'import":                                 "Error while importing",
+	"Erreur lors de la création de l'exemplaire":              "Error while creating the copy",
+	"Erreur lors de la création de l'utilisateur":             "Error while creating the user",
+	"Erreur lors de la création de la ressource":              "Error while creating the resource",
+	"Erreur lors de la création du jeu de données":            "Error while creating the dataset",
+	"Erreur lors de la création du prêt":                      "Error while creating the loan",
+	"Erreur lors de la déconnexion":                           "Error while signing out",
+	"Erreur lors de la génération du token":                   "Error while generating the token",
+	"Erreur lors de la mise à jour de l'exemplaire":           "Error while updating the copy",
+	"Erreur lors de la mise à jour de la ressource":           "Error while updating the resource",
+	"Erreur lors de la mise à jour du profil":                 "Error while updating the profile",
+	"Erreur lors de la mise à jour du prêt":                   "Error while updating the loan",
+	"Erreur lors de la mise à jour du rôle":                   "Error while updating the role",
+	"Erreur lors de la recherche":                             "Error while searching",
+	"Erreur lors de la recherche de la réservation":           "Error while looking up the hold",
+	"Erreur lors de la restauration de la ressource":          "Error while restoring the resource",
+	"Erreur lors de la récupération des amendes":              "Error while retrieving fines",
+	"Erreur lors de la récupération des politiques de prêt":   "Error while retrieving loan policies",
+	"Erreur lors de la récupération des prêts":                "Error while retrieving loans",
+	"Erreur lors de la récupération des réservations":         "Error while retrieving holds",
+	"Erreur lors de la récupération des utilisateurs":         "Error while retrieving users",
+	"Erreur lors de la réservation":                           "Error while placing the hold",
+	"Erreur lors de la révocation des sessions":               "Error while revoking sessions",
+	"Erreur lors de la suppression de la politique de prêt":   "Error while deleting the loan policy",
+	"Erreur lors de la suppression de la ressource":           "Error while deleting the resource",
+	"Erreur lors du rafraîchissement du token":                "Error while refreshing the token",
+	"Erreur lors du renouvellement du prêt":                   "Error while renewing the loan",
+	"Impossible de récupérer la ressource":                    "Could not retrieve the resource",
+	"Impossible de récupérer les exemplaires":                 "Could not retrieve the copies",
+	"Impossible de récupérer les ressources":                  "Could not retrieve the resources",
+	"Impossible de récupérer les ressources supprimées":       "Could not retrieve the deleted resources",
+}
@@ -0,0 +1,92 @@
+// Package i18n traduit les messages destinés aux utilisateurs de l'API.
+// Le français est la langue source : les messages sont écrits en français dans le code et servent
+// de clé aux catalogues des autres langues. Un message absent d'un catalogue reste en français.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lang est une langue prise en charge, désignée par son code ISO 639-1.
+type Lang string
+
+const (
+	FR Lang = "fr"
+	EN Lang = "en"
+
+	// Default est la langue des requêtes qui n'en demandent aucune prise en charge.
+	Default = FR
+)
+
+// ContextKey est la clé de la langue de la requête dans le contexte gin.
+const ContextKey = "locale"
+
+// bundles associe chaque langue à son catalogue ; le français, langue source, n'en a pas.
+var bundles = map[Lang]map[string]string{
+	EN: en,
+}
+
+// Parse retourne la langue désignée par le code, par exemple "fr" ou "en-GB".
+func Parse(code string) (Lang, bool) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(code), "-")
+	lang := Lang(strings.ToLower(primary))
+	if lang != FR && bundles[lang] == nil {
+		return "", false
+	}
+	return lang, true
+}
+
+// Negotiate choisit la langue préférée de l'en-tête Accept-Language parmi les langues prises en charge,
+// selon les poids q. Retourne Default si aucune ne convient.
+func Negotiate(acceptLanguage string) Lang {
+	best, bestWeight := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		code, params, _ := strings.Cut(part, ";")
+		weight := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			weight = q
+		}
+		lang, ok := Parse(code)
+		if ok && weight > bestWeight {
+			best, bestWeight = lang, weight
+		}
+	}
+	return best
+}
+
+// FromContext retourne la langue de la requête, ou Default si elle n'a pas été choisie.
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(ContextKey).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// T traduit le message dans la langue demandée. Les valeurs args remplacent les verbes du message
+// (%s, %d) après traduction.
+func T(lang Lang, message string, args ...any) string {
+	format := message
+	if translated, ok := bundles[lang][message]; ok {
+		format = translated
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Has indique si le message est traduit dans la langue demandée.
+func Has(lang Lang, message string) bool {
+	if lang == FR {
+		return true
+	}
+	_, ok := bundles[lang][message]
+	return ok
+}
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"gorm.io/gorm"
+)
+
+// OverdueJob détecte périodiquement les prêts en retard, calcule les amendes
+// et fait expirer les réservations non retirées.
+type OverdueJob struct {
+	loans  *service.LoanService
+	holds  *service.HoldService
+	clock  clock.Clock
+	cfg    config.LoanConfig
+	logger *logging.Logger
+}
+
+// OverdueReport résume un passage de la tâche.
+type OverdueReport struct {
+	Overdue      int // Prêts passés en retard
+	Fined        int // Prêts dont l'amende a augmenté
+	ExpiredHolds int // Réservations expirées
+	ServedHolds  int // Réservations mises à disposition
+}
+
+// NewOverdueJob crée la tâche avec la base, l'horloge, les règles de prêt et le journal fournis.
+func NewOverdueJob(db *gorm.DB, clk clock.Clock, cfg config.LoanConfig, logger *logging.Logger) *OverdueJob {
+	store := repository.NewGormStore(db)
+	return &OverdueJob{
+		loans:  service.NewLoanService(store, cfg, clk),
+		holds:  service.NewHoldService(store, cfg, clk),
+		clock:  clk,
+		cfg:    cfg,
+		logger: logger.With("job", "overdue"),
+	}
+}
+
+// RunOnce effectue un passage complet à l'heure de l'horloge.
+func (j *OverdueJob) RunOnce() (OverdueReport, error) {
+	var report OverdueReport
+	now := j.clock.Now()
+
+	var err error
+	if report.Overdue, err = j.loans.MarkOverdue(now); err != nil {
+		return report, err
+	}
+	if report.Fined, err = j.loans.AccrueFines(now); err != nil {
+		return report, err
+	}
+	report.ExpiredHolds, report.ServedHolds, err = j.holds.Process(now)
+	return report, err
+}
+
+// Run exécute la tâche immédiatement puis à chaque intervalle configuré, jusqu'à l'annulation du contexte.
+func (j *OverdueJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.OverdueInterval)
+	defer ticker.Stop()
+
+	for {
+		report, err := j.RunOnce()
+		if err != nil {
+			j.logger.ErrorContext(ctx, "Détection des retards", "error", err)
+		} else if report != (OverdueReport{}) {
+			j.logger.Event(ctx, "loans.overdue_processed",
+				"overdue", report.Overdue, "fined", report.Fined,
+				"expired_holds", report.ExpiredHolds, "served_holds", report.ServedHolds)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
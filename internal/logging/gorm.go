@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"fmt"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold est la durée au-delà de laquelle une requête SQL est signalée comme lente.
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormWriter redirige les messages de GORM (requêtes lentes et erreurs SQL) vers le journal.
+type gormWriter struct {
+	logger *Logger
+}
+
+func (w gormWriter) Printf(format string, args ...interface{}) {
+	w.logger.Warn(fmt.Sprintf(format, args...), "component", "gorm")
+}
+
+// Gorm retourne un journal GORM qui écrit dans l'application les requêtes lentes et les erreurs SQL.
+// Les enregistrements introuvables ne sont pas signalés : ils font partie du fonctionnement normal.
+func (l *Logger) Gorm() gormlogger.Interface {
+	return gormlogger.New(gormWriter{logger: l}, gormlogger.Config{
+		SlowThreshold:             slowQueryThreshold,
+		LogLevel:                  gormlogger.Warn,
+		IgnoreRecordNotFoundError: true,
+		Colorful:                  false,
+	})
+}
@@ -0,0 +1,67 @@
+// Package logging construit les journaux structurés de l'application (log/slog).
+// Le journal est injecté comme la base et l'horloge : les tests fournissent le leur pour
+// capturer les messages et vérifier leur contenu.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"awesomeProject/internal/config"
+)
+
+// ContextKey est la clé du journal de la requête dans le contexte gin.
+const ContextKey = "logger"
+
+// Logger est un journal slog qui émet les événements du domaine à un niveau configurable.
+type Logger struct {
+	*slog.Logger
+	eventLevel slog.Level
+}
+
+// New crée un journal écrivant dans w selon la configuration (niveau, format et niveau des événements).
+func New(w io.Writer, cfg config.LogConfig) (*Logger, error) {
+	level, err := config.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	eventLevel, err := config.ParseLevel(cfg.EventLevel)
+	if err != nil {
+		return nil, err
+	}
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewJSONHandler(w, options)
+	if cfg.Format == config.LogFormatText {
+		handler = slog.NewTextHandler(w, options)
+	}
+	return NewWithHandler(handler, eventLevel), nil
+}
+
+// NewWithHandler crée un journal sur le handler slog fourni.
+func NewWithHandler(handler slog.Handler, eventLevel slog.Level) *Logger {
+	return &Logger{Logger: slog.New(handler), eventLevel: eventLevel}
+}
+
+// Discard retourne un journal qui n'écrit rien.
+func Discard() *Logger {
+	return NewWithHandler(slog.DiscardHandler, slog.LevelInfo)
+}
+
+// With retourne un journal qui ajoute les attributs fournis à chaque message.
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{Logger: l.Logger.With(args...), eventLevel: l.eventLevel}
+}
+
+// Event journalise un événement du domaine, par exemple "loan.created", avec ses attributs.
+func (l *Logger) Event(ctx context.Context, name string, args ...any) {
+	l.Log(ctx, l.eventLevel, name, append([]any{slog.String("event", name)}, args...)...)
+}
+
+// FromContext retourne le journal de la requête, ou un journal muet si le middleware ne l'a pas fourni.
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(ContextKey).(*Logger); ok {
+		return logger
+	}
+	return Discard()
+}
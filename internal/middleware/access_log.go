@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"awesomeProject/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog journalise chaque requête une fois la réponse écrite : méthode, chemin, route, statut,
+// durée, utilisateur et erreur éventuelle. Placé après RequestID, il injecte dans le contexte
+// (clé logging.ContextKey) un journal portant l'identifiant de la requête, que les handlers
+// récupèrent avec logging.FromContext ; AuthRequired y ajoute l'ID de l'utilisateur.
+func AccessLog(logger *logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Set(logging.ContextKey, logger.With("request_id", c.GetString("requestID")))
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if err := c.Errors.Last(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logging.FromContext(c).Log(c, level, "Requête HTTP", attrs...)
+	}
+}
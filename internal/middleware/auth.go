@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// AuthRequired vérifie le token JWT transmis dans l'en-tête "Authorization: Bearer <token>".
+// Le token doit être signé en HS256 avec le secret fourni et ne pas être expiré.
+// En cas de succès, l'ID de l'utilisateur est injecté dans le contexte avec la clé "userID" (type uint)
+// et son rôle avec la clé "userRole" (type string).
+// Les tokens révoqués (déconnexion, révocation des sessions, changement de rôle) sont refusés ;
+// la révocation est vérifiée par le service des sessions fourni. La langue préférée de l'utilisateur, s'il en a choisi une,
+// remplace celle de l'en-tête Accept-Language, et son ID est ajouté au journal de la requête.
+func AuthRequired(secret []byte, sessions *service.SessionService) gin.HandlerFunc {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	return func(c *gin.Context) {
+		// Extraire le token de l'en-tête Authorization
+		header := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found || strings.TrimSpace(tokenString) == "" {
+			abort(c, apierror.Unauthorized(apierror.CodeTokenMissing, "Token d'authentification manquant"))
+			return
+		}
+
+		// Vérifier la signature et l'algorithme du token
+		claims := jwt.MapClaims{}
+		token, err := parser.ParseWithClaims(strings.TrimSpace(tokenString), claims, func(token *jwt.Token) (interface{}, error) {
+			return secret, nil
+		})
+		if err != nil || !token.Valid {
+			abort(c, apierror.Unauthorized(apierror.CodeTokenInvalid, "Token invalide ou expiré"))
+			return
+		}
+
+		// La date d'expiration est obligatoire
+		if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+			abort(c, apierror.Unauthorized(apierror.CodeTokenInvalid, "Token invalide ou expiré"))
+			return
+		}
+
+		// Les nombres JSON sont décodés en float64
+		rawUserID, ok := claims["user_id"].(float64)
+		if !ok || rawUserID <= 0 || rawUserID != float64(uint(rawUserID)) {
+			abort(c, apierror.Unauthorized(apierror.CodeTokenInvalid, "Token invalide ou expiré"))
+			return
+		}
+
+		// Les tokens émis sans rôle sont considérés comme ceux d'un adhérent
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = models.RoleMember
+		}
+		if !models.IsValidRole(role) {
+			abort(c, apierror.Unauthorized(apierror.CodeTokenInvalid, "Token invalide ou expiré"))
+			return
+		}
+
+		// Vérifier que le token n'a pas été révoqué ; une panne du stockage produit une erreur 500
+		jti, _ := claims["jti"].(string)
+		issuedAt, _ := claims["iat"].(float64)
+		user, err := sessions.Verify(jti, uint(rawUserID), role, time.UnixMilli(int64(math.Round(issuedAt*1000))))
+		if err != nil {
+			abort(c, err)
+			return
+		}
+		if lang, ok := i18n.Parse(user.Language); ok {
+			setLocale(c, lang)
+		}
+
+		expiresAt, _ := claims["exp"].(float64)
+		c.Set("userID", uint(rawUserID))
+		c.Set("userRole", role)
+		c.Set("tokenID", jti)
+		c.Set("tokenExpiresAt", time.Unix(int64(expiresAt), 0))
+		c.Set(logging.ContextKey, logging.FromContext(c).With("user_id", user.ID))
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// kindStatus associe chaque catégorie d'erreur du domaine à son code HTTP.
+var kindStatus = map[service.Kind]int{
+	service.KindInvalid:      http.StatusBadRequest,
+	service.KindUnauthorized: http.StatusUnauthorized,
+	service.KindForbidden:    http.StatusForbidden,
+	service.KindNotFound:     http.StatusNotFound,
+	service.KindConflict:     http.StatusConflict,
+}
+
+// Errors produit la réponse des requêtes en erreur : les handlers et middlewares signalent l'erreur
+// avec c.Error, et la dernière erreur signalée est traduite en enveloppe JSON commune, avec le code HTTP
+// adapté, les messages traduits dans la langue de la requête et l'identifiant de la requête.
+// Une réponse déjà envoyée (export interrompu) n'est pas modifiée.
+// Il doit être placé après RequestID et avant les middlewares susceptibles d'échouer.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		apiErr := toAPIError(c.Errors.Last().Err).Localize(i18n.FromContext(c))
+		apiErr.RequestID = c.GetString("requestID")
+		c.JSON(apiErr.Status, gin.H{"error": apiErr})
+	}
+}
+
+// toAPIError traduit une erreur en erreur de l'API. Les erreurs du domaine conservent leur code et leur
+// message ; une erreur inattendue devient une erreur 500 dont la cause n'est pas exposée.
+func toAPIError(err error) *apierror.Error {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var domainErr *service.Error
+	if errors.As(err, &domainErr) {
+		status, ok := kindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return &apierror.Error{
+			Status:  status,
+			Code:    domainErr.Code,
+			Message: domainErr.Message,
+			Details: domainErr.Details,
+			Err:     domainErr,
+		}
+	}
+
+	var transitionErr *service.TransitionError
+	if errors.As(err, &transitionErr) {
+		return apierror.Conflict(apierror.CodeInvalidTransition, "Changement de statut impossible").
+			WithDetail("current_status", transitionErr.Current).
+			WithDetail("requested_status", transitionErr.Requested).
+			Wrap(err)
+	}
+
+	return apierror.Internal("Erreur interne", err)
+}
+
+// abort signale l'erreur à Errors et interrompt la chaîne des handlers.
+func abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// Recovery traduit une panique en erreur 500, produite par Errors comme toute autre erreur.
+// La pile d'appels est écrite dans le journal de la requête plutôt que sur la sortie d'erreur de gin.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logging.FromContext(c).ErrorContext(c, "Panique", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		c.Error(apierror.Internal("Erreur interne", fmt.Errorf("panique : %v", recovered)))
+		c.Abort()
+	})
+}
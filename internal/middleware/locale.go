@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"awesomeProject/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale choisit la langue des messages de la requête d'après l'en-tête Accept-Language (français par défaut).
+// AuthRequired la remplace par la langue préférée de l'utilisateur authentifié, s'il en a choisi une.
+// La langue retenue est stockée dans le contexte avec la clé i18n.ContextKey et annoncée par Content-Language.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// setLocale fixe la langue des messages de la requête.
+func setLocale(c *gin.Context, lang i18n.Lang) {
+	c.Set(i18n.ContextKey, lang)
+	c.Header("Content-Language", string(lang))
+}
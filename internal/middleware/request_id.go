@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader est l'en-tête qui transporte l'identifiant de la requête.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength borne la longueur d'un identifiant fourni par le client.
+const maxRequestIDLength = 128
+
+// RequestID attribue un identifiant à chaque requête, injecté dans le contexte avec la clé "requestID"
+// et renvoyé dans l'en-tête X-Request-ID. L'identifiant fourni par le client ou un proxy est conservé
+// s'il est raisonnable ; sinon un identifiant aléatoire est généré.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// validRequestID accepte les identifiants courts composés de caractères imprimables sans espace.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID génère un identifiant aléatoire de 32 caractères hexadécimaux.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"slices"
+
+	"awesomeProject/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole limite l'accès aux utilisateurs dont le rôle fait partie de la liste fournie.
+// Il doit être placé après AuthRequired, qui injecte le rôle dans le contexte avec la clé "userRole".
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("userRole")
+		if role == "" {
+			abort(c, apierror.Unauthorized(apierror.CodeUnauthenticated, "Utilisateur non authentifié"))
+			return
+		}
+		if !slices.Contains(roles, role) {
+			abort(c, apierror.Forbidden())
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import "strings"
+
+// Types de ressource
+const (
+	ResourceBook = "Livre"
+	ResourceGame = "Jeu"
+)
+
+// Notice bibliographique d'un livre
+type BookDetails struct {
+	ResourceID uint     `gorm:"primaryKey"`
+	Authors    []string `gorm:"serializer:json"` // Dans l'ordre de la couverture
+	Publisher  string
+	Year       int      // Année de publication ; 0 si inconnue
+	ISBN       *string  `gorm:"uniqueIndex"` // ISBN-13 sans tirets
+	Language   string   // Code ISO 639-1, par exemple "fr"
+	Genres     []string `gorm:"serializer:json"` // Genres et mots-clés
+	CoverURL   string
+}
+
+// Fiche d'un jeu de société
+type GameDetails struct {
+	ResourceID uint `gorm:"primaryKey"`
+	Designer   string
+	MinPlayers int     // 0 si inconnu
+	MaxPlayers int     // 0 si inconnu
+	PlayTime   int     // Durée d'une partie, en minutes
+	MinAge     int     // Âge minimal conseillé
+	Complexity float64 // De 1 (simple) à 5 (expert) ; 0 si inconnue
+}
+
+// NormalizeISBN retire les tirets et espaces d'un ISBN saisi.
+func NormalizeISBN(isbn string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(isbn)
+}
+
+// IsValidISBN13 vérifie le format et la clé de contrôle d'un ISBN-13 normalisé :
+// la somme des chiffres, pondérés alternativement par 1 et 3, doit être un multiple de 10.
+func IsValidISBN13(isbn string) bool {
+	if len(isbn) != 13 || !(strings.HasPrefix(isbn, "978") || strings.HasPrefix(isbn, "979")) {
+		return false
+	}
+	sum := 0
+	for i, r := range isbn {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digit := int(r - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
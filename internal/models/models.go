@@ -1,33 +1,206 @@
 package models
 
 import (
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rôles des utilisateurs
+const (
+	RoleMember    = "member"    // Adhérent : consulte et emprunte
+	RoleLibrarian = "librarian" // Bibliothécaire : gère le catalogue et les prêts
+	RoleAdmin     = "admin"     // Administrateur : gère aussi les rôles
 )
 
+// IsValidRole indique si le rôle fait partie des rôles connus.
+func IsValidRole(role string) bool {
+	return role == RoleMember || role == RoleLibrarian || role == RoleAdmin
+}
+
+// IsStaffRole indique si le rôle donne accès aux opérations du personnel.
+func IsStaffRole(role string) bool {
+	return role == RoleLibrarian || role == RoleAdmin
+}
+
 // Utilisateur
 type User struct {
 	ID       uint   `gorm:"primaryKey"`
 	Name     string `gorm:"not null"`
 	Email    string `gorm:"unique;not null"`
-	Password string `gorm:"not null"`          // À hacher avec bcrypt
-	Loans    []Loan `gorm:"foreignKey:UserID"` // Relation avec les prêts
+	Password string `gorm:"not null"`                // À hacher avec bcrypt
+	Role     string `gorm:"not null;default:member"` // "member", "librarian" ou "admin"
+	Loans    []Loan `gorm:"foreignKey:UserID"`       // Relation avec les prêts
+
+	// Langue des messages de l'API ("fr" ou "en") ; vide, celle de l'en-tête Accept-Language
+	Language string `gorm:"not null;default:''"`
+
+	// Les tokens émis avant cette date sont refusés (révocation globale des sessions)
+	TokensRevokedAt *time.Time `json:"-"`
+}
+
+// Token de rafraîchissement (seule son empreinte SHA-256 est stockée)
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey"`
+	UserID       uint       `gorm:"not null;index"`
+	TokenHash    string     `gorm:"uniqueIndex;not null"`
+	ExpiresAt    time.Time  `gorm:"not null"`
+	RevokedAt    *time.Time // Renseigné lors de la rotation, de la déconnexion ou d'une révocation
+	ReplacedByID *uint      // Token émis lors de la rotation
+	CreatedAt    time.Time
 }
 
-// Ressource (Livre ou Jeu)
+// Token d'accès révoqué avant son expiration (déconnexion)
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"` // Au-delà, l'entrée peut être supprimée
+}
+
+// Ressource (Livre ou Jeu) : l'œuvre du catalogue, dont la bibliothèque possède un ou plusieurs exemplaires
 type Resource struct {
-	ID     uint   `gorm:"primaryKey"`
-	Title  string `gorm:"not null"`
-	Type   string `gorm:"not null"`              // "Livre" ou "Jeu"
-	Status string `gorm:"default:disponible"`    // "disponible" ou "indisponible"
-	Loans  []Loan `gorm:"foreignKey:ResourceID"` // Historique des prêts
+	ID     uint           `gorm:"primaryKey"`
+	Title  string         `gorm:"not null"`
+	Type   string         `gorm:"not null"`              // "Livre" ou "Jeu"
+	Status ResourceStatus `gorm:"default:disponible"`    // Synthèse du statut des exemplaires (voir status.go)
+	Copies []Copy         `gorm:"foreignKey:ResourceID"` // Exemplaires physiques
+	Loans  []Loan         `gorm:"foreignKey:ResourceID"` // Historique des prêts
+
+	// Métadonnées propres au type de ressource (voir metadata.go)
+	Book *BookDetails `gorm:"foreignKey:ResourceID"`
+	Game *GameDetails `gorm:"foreignKey:ResourceID"`
+
+	// Suppression logique : l'historique des prêts reste intact et un administrateur peut restaurer la ressource
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// Titre normalisé par TitleKey, pour repérer les doublons ; renseigné à l'enregistrement
+	TitleKey string `gorm:"not null;default:'';index" json:"-"`
+
+	// Disponibilité calculée à partir des exemplaires, par exemple "2 sur 3 disponibles"
+	AvailableCopies int `gorm:"-"`
+	TotalCopies     int `gorm:"-"`
+}
+
+// État physique d'un exemplaire
+const (
+	ConditionNew     = "neuf"
+	ConditionGood    = "bon"
+	ConditionWorn    = "usé"
+	ConditionDamaged = "abîmé"
+)
+
+// TitleKey normalise un titre pour repérer les doublons sans tenir compte de la casse. La normalisation
+// est faite en Go et stockée : LOWER ne traite que l'ASCII sous SQLite, tous les caractères sous PostgreSQL.
+func TitleKey(title string) string {
+	return strings.ToLower(title)
 }
 
+// BeforeSave renseigne la clé de comparaison du titre.
+func (r *Resource) BeforeSave(*gorm.DB) error {
+	r.TitleKey = TitleKey(r.Title)
+	return nil
+}
+
+// IsValidCondition indique si l'état physique fait partie des états connus.
+func IsValidCondition(condition string) bool {
+	return condition == ConditionNew || condition == ConditionGood || condition == ConditionWorn || condition == ConditionDamaged
+}
+
+// Exemplaire physique d'une ressource
+type Copy struct {
+	ID         uint           `gorm:"primaryKey"`
+	ResourceID uint           `gorm:"not null;index"`
+	Barcode    string         `gorm:"uniqueIndex;not null"` // Code-barres collé sur l'exemplaire
+	Location   string         // Emplacement en rayon, par exemple "B2-14"
+	Condition  string         `gorm:"not null;default:bon"`        // "neuf", "bon", "usé" ou "abîmé"
+	Status     ResourceStatus `gorm:"not null;default:disponible"` // "disponible", "indisponible", "emprunté" ou "réservé"
+	Loans      []Loan         `gorm:"foreignKey:CopyID"`           // Historique des prêts de l'exemplaire
+}
+
+// Types d'emprunt
+const (
+	BorrowOnSite   = "sur_place"  // Consultation sur place, rendue le jour même
+	BorrowTakeAway = "a_emporter" // Emprunt à domicile
+)
+
 // Prêt d'un livre ou jeu
 type Loan struct {
-	ID         uint      `gorm:"primaryKey"`
-	UserID     uint      `gorm:"not null"`
-	ResourceID uint      `gorm:"not null"`
-	LoanDate   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
-	ReturnDate time.Time `gorm:"not null"`
-	Status     string    `gorm:"default:en_cours"` // "en_cours" ou "retourné"
+	ID           uint          `gorm:"primaryKey"`
+	UserID       uint          `gorm:"not null"`
+	ResourceID   uint          `gorm:"not null"`
+	CopyID       uint          `gorm:"index"`                       // Exemplaire emprunté
+	BorrowType   string        `gorm:"not null;default:a_emporter"` // "sur_place" ou "a_emporter"
+	LoanDate     time.Time     `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	ReturnDate   time.Time     `gorm:"not null"`
+	Status       LoanStatus    `gorm:"default:en_cours"` // "en_cours", "en_retard" ou "retourné" (voir status.go)
+	RenewalCount int           `gorm:"not null;default:0"`
+	Renewals     []LoanRenewal `gorm:"foreignKey:LoanID"` // Historique des renouvellements
+}
+
+// Renouvellement d'un prêt
+type LoanRenewal struct {
+	ID                 uint      `gorm:"primaryKey"`
+	LoanID             uint      `gorm:"not null;index"`
+	RenewedAt          time.Time `gorm:"not null"`
+	PreviousReturnDate time.Time `gorm:"not null"`
+	NewReturnDate      time.Time `gorm:"not null"`
+	RenewedByID        uint      // Utilisateur ayant demandé le renouvellement (adhérent ou personnel)
+}
+
+// Réservation d'une ressource dont aucun exemplaire n'est disponible
+type Hold struct {
+	ID             uint       `gorm:"primaryKey"`
+	UserID         uint       `gorm:"not null;index"`
+	ResourceID     uint       `gorm:"not null;index"`
+	CopyID         *uint      // Exemplaire mis de côté une fois la réservation prête
+	Status         HoldStatus `gorm:"not null;default:en_attente"` // Voir status.go
+	CreatedAt      time.Time  // Ordre d'arrivée dans la file d'attente
+	ReadyAt        *time.Time // Date de mise à disposition
+	PickupDeadline *time.Time // Date limite de retrait, après laquelle la réservation passe au suivant
+
+	// Rang dans la file d'attente (1 = prochain servi), 0 si la réservation n'attend plus
+	Position int `gorm:"-"`
+}
+
+// Types d'écriture du compte d'amendes
+const (
+	FineCharge  = "amende"   // Amende de retard, montant positif
+	FinePayment = "paiement" // Paiement enregistré par le personnel, montant négatif
+	FineWaiver  = "remise"   // Remise accordée par le personnel, montant négatif
+)
+
+// Écriture du compte d'amendes d'un adhérent. Le solde dû est la somme des montants.
+type FineEntry struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"not null;index"`
+	LoanID      *uint     `gorm:"index"`    // Prêt en retard à l'origine d'une amende
+	Kind        string    `gorm:"not null"` // "amende", "paiement" ou "remise"
+	Amount      int       `gorm:"not null"` // En centimes
+	Note        string    // Motif saisi par le personnel
+	CreatedByID *uint     // Membre du personnel ayant saisi un paiement ou une remise
+	CreatedAt   time.Time `gorm:"not null"`
+}
+
+// Politique de prêt d'un type de ressource ("Livre", "Jeu") pour un type d'emprunt
+type LoanPolicy struct {
+	ID                 uint   `gorm:"primaryKey"`
+	ResourceType       string `gorm:"not null;uniqueIndex:idx_loan_policies_type_borrow"`
+	BorrowType         string `gorm:"not null;uniqueIndex:idx_loan_policies_type_borrow"` // "sur_place" ou "a_emporter"
+	Allowed            bool   `gorm:"not null"`                                           // Un jeu coûteux peut être réservé à la consultation sur place
+	LoanDays           int    `gorm:"not null;default:0"`                                 // Durée du prêt ; 0 : rendu le jour même
+	MaxConcurrentLoans int    `gorm:"not null;default:0"`                                 // Prêts simultanés par adhérent ; 0 : sans limite
+	MaxRenewals        int    `gorm:"not null;default:0"`                                 // 0 : non renouvelable
+	RenewalDays        int    `gorm:"not null;default:0"`                                 // Prolongation à chaque renouvellement
+	FineDailyRate      int    `gorm:"not null;default:0"`                                 // En centimes par jour de retard entamé
+	FineCap            int    `gorm:"not null;default:0"`                                 // Plafond de l'amende d'un prêt, en centimes
+}
+
+// DueDate retourne la date de retour d'un prêt accordé à loanDate selon la politique.
+func (p LoanPolicy) DueDate(loanDate time.Time) time.Time {
+	if p.LoanDays > 0 {
+		return loanDate.AddDate(0, 0, p.LoanDays)
+	}
+	// Rendu le jour même, avant minuit
+	year, month, day := loanDate.Date()
+	return time.Date(year, month, day, 23, 59, 59, 0, loanDate.Location())
 }
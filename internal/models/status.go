@@ -0,0 +1,162 @@
+package models
+
+import "slices"
+
+// ResourceStatus représente l'état d'une ressource du catalogue.
+type ResourceStatus string
+
+const (
+	ResourceAvailable   ResourceStatus = "disponible"   // Peut être empruntée
+	ResourceUnavailable ResourceStatus = "indisponible" // Retirée du prêt par le personnel
+	ResourceBorrowed    ResourceStatus = "emprunté"     // Un prêt est en cours
+	ResourceReserved    ResourceStatus = "réservé"      // Mis de côté pour une réservation prête
+)
+
+// ResourceStatuses liste les statuts de ressource valides.
+var ResourceStatuses = []ResourceStatus{ResourceAvailable, ResourceUnavailable, ResourceBorrowed, ResourceReserved}
+
+// IsValid indique si le statut fait partie des statuts connus.
+func (s ResourceStatus) IsValid() bool {
+	return slices.Contains(ResourceStatuses, s)
+}
+
+// ResourceAction représente une opération qui modifie le statut d'une ressource.
+type ResourceAction string
+
+const (
+	ResourceBorrow  ResourceAction = "emprunter"
+	ResourceReturn  ResourceAction = "retourner"
+	ResourceDisable ResourceAction = "retirer"
+	ResourceEnable  ResourceAction = "remettre en service"
+	ResourceHold    ResourceAction = "mettre de côté"
+	ResourceRelease ResourceAction = "libérer"
+	ResourcePickup  ResourceAction = "retirer la réservation"
+)
+
+// Transition décrit les statuts de départ autorisés pour une action et le statut d'arrivée.
+type Transition[S ~string] struct {
+	From []S
+	To   S
+}
+
+// resourceTransitions est la table de transitions des ressources.
+// Une ressource empruntée ne redevient disponible que par le retour du prêt ; un exemplaire mis de côté
+// n'est emprunté que par le titulaire de la réservation.
+var resourceTransitions = map[ResourceAction]Transition[ResourceStatus]{
+	ResourceBorrow:  {From: []ResourceStatus{ResourceAvailable}, To: ResourceBorrowed},
+	ResourceReturn:  {From: []ResourceStatus{ResourceBorrowed}, To: ResourceAvailable},
+	ResourceDisable: {From: []ResourceStatus{ResourceAvailable}, To: ResourceUnavailable},
+	ResourceEnable:  {From: []ResourceStatus{ResourceUnavailable}, To: ResourceAvailable},
+	ResourceHold:    {From: []ResourceStatus{ResourceAvailable}, To: ResourceReserved},
+	ResourceRelease: {From: []ResourceStatus{ResourceReserved}, To: ResourceAvailable},
+	ResourcePickup:  {From: []ResourceStatus{ResourceReserved}, To: ResourceBorrowed},
+}
+
+// Target retourne le statut obtenu après l'action.
+func (a ResourceAction) Target() ResourceStatus {
+	return resourceTransitions[a].To
+}
+
+// Allows indique si l'action est autorisée depuis le statut courant.
+func (s ResourceStatus) Allows(action ResourceAction) bool {
+	return slices.Contains(resourceTransitions[action].From, s)
+}
+
+// LoanStatus représente l'état d'un prêt.
+type LoanStatus string
+
+const (
+	LoanActive   LoanStatus = "en_cours"  // La ressource n'a pas encore été rendue
+	LoanOverdue  LoanStatus = "en_retard" // La date de retour est dépassée
+	LoanReturned LoanStatus = "retourné"  // La ressource a été rendue
+)
+
+// LoanStatuses liste les statuts de prêt valides.
+var LoanStatuses = []LoanStatus{LoanActive, LoanOverdue, LoanReturned}
+
+// OpenLoanStatuses liste les statuts d'un prêt dont la ressource n'est pas rendue.
+var OpenLoanStatuses = []LoanStatus{LoanActive, LoanOverdue}
+
+// IsValid indique si le statut fait partie des statuts connus.
+func (s LoanStatus) IsValid() bool {
+	return slices.Contains(LoanStatuses, s)
+}
+
+// LoanAction représente une opération qui modifie le statut d'un prêt.
+type LoanAction string
+
+const (
+	LoanReturn      LoanAction = "retourner"
+	LoanRenew       LoanAction = "renouveler"
+	LoanMarkOverdue LoanAction = "signaler le retard"
+)
+
+// loanTransitions est la table de transitions des prêts.
+// Un renouvellement laisse le prêt en cours : seule la date de retour change.
+var loanTransitions = map[LoanAction]Transition[LoanStatus]{
+	LoanReturn:      {From: []LoanStatus{LoanActive, LoanOverdue}, To: LoanReturned},
+	LoanRenew:       {From: []LoanStatus{LoanActive}, To: LoanActive},
+	LoanMarkOverdue: {From: []LoanStatus{LoanActive}, To: LoanOverdue},
+}
+
+// Target retourne le statut obtenu après l'action.
+func (a LoanAction) Target() LoanStatus {
+	return loanTransitions[a].To
+}
+
+// Allows indique si l'action est autorisée depuis le statut courant.
+func (s LoanStatus) Allows(action LoanAction) bool {
+	return slices.Contains(loanTransitions[action].From, s)
+}
+
+// HoldStatus représente l'état d'une réservation.
+type HoldStatus string
+
+const (
+	HoldWaiting   HoldStatus = "en_attente" // Dans la file d'attente
+	HoldReady     HoldStatus = "prête"      // Un exemplaire attend l'adhérent jusqu'à la date limite de retrait
+	HoldFulfilled HoldStatus = "honorée"    // L'adhérent a emprunté l'exemplaire mis de côté
+	HoldCancelled HoldStatus = "annulée"    // Annulée par l'adhérent ou le personnel
+	HoldExpired   HoldStatus = "expirée"    // Non retirée avant la date limite
+)
+
+// HoldStatuses liste les statuts de réservation valides.
+var HoldStatuses = []HoldStatus{HoldWaiting, HoldReady, HoldFulfilled, HoldCancelled, HoldExpired}
+
+// IsValid indique si le statut fait partie des statuts connus.
+func (s HoldStatus) IsValid() bool {
+	return slices.Contains(HoldStatuses, s)
+}
+
+// IsActive indique si la réservation est encore dans la file ou en attente de retrait.
+func (s HoldStatus) IsActive() bool {
+	return s == HoldWaiting || s == HoldReady
+}
+
+// HoldAction représente une opération qui modifie le statut d'une réservation.
+type HoldAction string
+
+const (
+	HoldNotify  HoldAction = "mettre à disposition"
+	HoldFulfill HoldAction = "honorer"
+	HoldCancel  HoldAction = "annuler"
+	HoldExpire  HoldAction = "expirer"
+)
+
+// holdTransitions est la table de transitions des réservations.
+var holdTransitions = map[HoldAction]Transition[HoldStatus]{
+	HoldNotify:  {From: []HoldStatus{HoldWaiting}, To: HoldReady},
+	HoldFulfill: {From: []HoldStatus{HoldReady}, To: HoldFulfilled},
+	HoldCancel:  {From: []HoldStatus{HoldWaiting, HoldReady}, To: HoldCancelled},
+	HoldExpire:  {From: []HoldStatus{HoldReady}, To: HoldExpired},
+}
+
+// Target retourne le statut obtenu après l'action.
+func (a HoldAction) Target() HoldStatus {
+	return holdTransitions[a].To
+}
+
+// Allows indique si l'action est autorisée depuis le statut courant.
+func (s HoldStatus) Allows(action HoldAction) bool {
+	return slices.Contains(holdTransitions[action].From, s)
+}
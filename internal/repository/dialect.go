@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"awesomeProject/internal/service"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// isPostgres indique si la connexion utilise PostgreSQL plutôt que SQLite.
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// forUpdate verrouille les lignes lues par la requête jusqu'à la fin de la transaction (SELECT ... FOR UPDATE).
+// Sous PostgreSQL, deux transactions peuvent lire la même ligne avant que l'une d'elles n'écrive.
+// Sous SQLite, qui n'a pas de verrou de ligne, la requête est laissée telle quelle et ne verrouille rien :
+// les transactions y sont sérialisées par la connexion unique ouverte par database.InitDB.
+func forUpdate(tx *gorm.DB) *gorm.DB {
+	if isPostgres(tx) {
+		return tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+	}
+	return tx
+}
+
+// constraint traduit la violation d'une contrainte d'intégrité en service.ErrConstraint, en conservant
+// l'erreur du pilote. Les autres erreurs, par exemple une connexion perdue, sont retournées telles quelles.
+func constraint(err error) error {
+	var pgErr *pgconn.PgError
+	var sqliteErr *sqlite.Error
+	switch {
+	case errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, "23"): // Classe 23 : violation de contrainte
+	case errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT: // Code primaire du code étendu
+	default:
+		return err
+	}
+	return fmt.Errorf("%w : %w", service.ErrConstraint, err)
+}
@@ -0,0 +1,614 @@
+// Package repository implémente les dépôts du package service : sur la base SQL avec gorm,
+// et en mémoire pour les tests des règles métier. Les dépôts stockent et retrouvent les données ;
+// les règles métier (transitions, files d'attente, amendes) restent dans le package service.
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+	"gorm.io/gorm"
+)
+
+// GormStore stocke les données dans la base SQL.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore crée un stockage sur la connexion fournie.
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Users() service.UserRepository         { return gormUsers{s.db} }
+func (s *GormStore) Resources() service.ResourceRepository { return gormResources{s.db} }
+func (s *GormStore) Copies() service.CopyRepository        { return gormCopies{s.db} }
+func (s *GormStore) Holds() service.HoldRepository         { return gormHolds{s.db} }
+func (s *GormStore) Loans() service.LoanRepository         { return gormLoans{s.db} }
+func (s *GormStore) Fines() service.FineRepository         { return gormFines{s.db} }
+func (s *GormStore) Policies() service.PolicyRepository    { return gormPolicies{s.db} }
+func (s *GormStore) Tokens() service.TokenRepository       { return gormTokens{s.db} }
+
+// Transaction exécute fn dans une transaction SQL ; une transaction imbriquée crée un point de sauvegarde.
+func (s *GormStore) Transaction(fn func(service.Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormStore(tx))
+	})
+}
+
+// notFound traduit l'absence d'enregistrement de gorm en service.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return service.ErrNotFound
+	}
+	return err
+}
+
+// affected traduit une mise à jour sans effet en l'erreur fournie.
+func affected(result *gorm.DB, none error) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return none
+	}
+	return nil
+}
+
+// count retourne le nombre de lignes de la requête.
+func count(query *gorm.DB) (int, error) {
+	var n int64
+	err := query.Count(&n).Error
+	return int(n), err
+}
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r gormUsers) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+func (r gormUsers) List() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Omit("password").Find(&users).Error
+	return users, err
+}
+
+func (r gormUsers) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r gormUsers) Update(user *models.User) error {
+	return r.db.Save(user).Error
+}
+
+func (r gormUsers) SetRole(id uint, role string) error {
+	return affected(r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role), service.ErrNotFound)
+}
+
+func (r gormUsers) Lock(id uint) error {
+	var user models.User
+	return notFound(forUpdate(r.db).Select("id").First(&user, id).Error)
+}
+
+func (r gormUsers) RevokeSessions(id uint, at time.Time) error {
+	if err := r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("tokens_revoked_at", at).Error
+}
+
+type gormTokens struct {
+	db *gorm.DB
+}
+
+func (r gormTokens) FindRefresh(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &token, nil
+}
+
+func (r gormTokens) CreateRefresh(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r gormTokens) ConsumeRefresh(id uint, at time.Time) error {
+	return affected(r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at), service.ErrStale)
+}
+
+func (r gormTokens) SetReplacedBy(id, replacedByID uint) error {
+	return r.db.Model(&models.RefreshToken{}).Where("id = ?", id).Update("replaced_by_id", replacedByID).Error
+}
+
+func (r gormTokens) RevokeRefresh(userID uint, hash string, at time.Time) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("token_hash = ? AND user_id = ? AND revoked_at IS NULL", hash, userID).
+		Update("revoked_at", at).Error
+}
+
+func (r gormTokens) RevokeAccess(token models.RevokedToken, now time.Time) error {
+	if err := r.db.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(&token).Error
+}
+
+func (r gormTokens) AccessRevoked(jti string) (bool, error) {
+	n, err := count(r.db.Model(&models.RevokedToken{}).Where("jti = ?", jti))
+	return n > 0, err
+}
+
+type gormResources struct {
+	db *gorm.DB
+}
+
+func (r gormResources) FindByID(id uint) (*models.Resource, error) {
+	var resource models.Resource
+	if err := r.db.First(&resource, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &resource, nil
+}
+
+func (r gormResources) FindWithDetails(id uint) (*models.Resource, error) {
+	var resource models.Resource
+	if err := r.db.Preload("Copies").Preload("Book").Preload("Game").First(&resource, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &resource, nil
+}
+
+func (r gormResources) ListByIDs(ids []uint) ([]models.Resource, error) {
+	var resources []models.Resource
+	err := r.db.Preload("Book").Preload("Game").Where("id IN ?", ids).Find(&resources).Error
+	return resources, err
+}
+
+func (r gormResources) FindDuplicate(resource models.Resource) (uint, error) {
+	var ids []uint
+	var err error
+	if resource.Book != nil && resource.Book.ISBN != nil {
+		// La notice d'une ressource supprimée est conservée : son ISBN reste attribué
+		err = r.db.Model(&models.BookDetails{}).Where("isbn = ?", *resource.Book.ISBN).Limit(1).Pluck("resource_id", &ids).Error
+	} else {
+		err = r.db.Model(&models.Resource{}).
+			Where("title_key = ? AND type = ?", models.TitleKey(resource.Title), resource.Type).
+			Order("id").Limit(1).Pluck("id", &ids).Error
+	}
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r gormResources) ISBNTaken(isbn string, exceptID uint) (bool, error) {
+	n, err := count(r.db.Model(&models.BookDetails{}).Where("isbn = ? AND resource_id <> ?", isbn, exceptID))
+	return n > 0, err
+}
+
+func (r gormResources) Create(resource *models.Resource) error {
+	return constraint(r.db.Omit("Copies", "Loans").Create(resource).Error)
+}
+
+func (r gormResources) Update(resource *models.Resource) error {
+	if err := r.db.Model(&models.Resource{}).Where("id = ?", resource.ID).
+		Updates(map[string]interface{}{"title": resource.Title, "title_key": models.TitleKey(resource.Title), "type": resource.Type}).Error; err != nil {
+		return err
+	}
+
+	if resource.Book != nil {
+		resource.Book.ResourceID = resource.ID
+		if err := r.db.Save(resource.Book).Error; err != nil {
+			return err
+		}
+	} else if err := r.db.Where("resource_id = ?", resource.ID).Delete(&models.BookDetails{}).Error; err != nil {
+		return err
+	}
+
+	if resource.Game != nil {
+		resource.Game.ResourceID = resource.ID
+		return r.db.Save(resource.Game).Error
+	}
+	return r.db.Where("resource_id = ?", resource.ID).Delete(&models.GameDetails{}).Error
+}
+
+func (r gormResources) SetStatus(id uint, status models.ResourceStatus) error {
+	return r.db.Model(&models.Resource{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r gormResources) Lock(id uint) error {
+	var resource models.Resource
+	return notFound(forUpdate(r.db).Select("id").First(&resource, id).Error)
+}
+
+func (r gormResources) Delete(id uint) error {
+	return affected(r.db.Delete(&models.Resource{}, id), service.ErrNotFound)
+}
+
+func (r gormResources) ListDeleted() ([]models.Resource, error) {
+	resources := []models.Resource{}
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id").
+		Find(&resources).Error
+	return resources, err
+}
+
+func (r gormResources) Restore(id uint) error {
+	return affected(r.db.Unscoped().Model(&models.Resource{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil), service.ErrNotFound)
+}
+
+type gormCopies struct {
+	db *gorm.DB
+}
+
+func (r gormCopies) FindByID(id uint) (*models.Copy, error) {
+	var copy models.Copy
+	if err := r.db.First(&copy, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &copy, nil
+}
+
+func (r gormCopies) ListByResource(resourceID uint) ([]models.Copy, error) {
+	copies := []models.Copy{}
+	err := r.db.Where("resource_id = ?", resourceID).Order("id").Find(&copies).Error
+	return copies, err
+}
+
+func (r gormCopies) ListAvailable(resourceID uint) ([]models.Copy, error) {
+	var copies []models.Copy
+	err := r.db.Where("resource_id = ? AND status = ?", resourceID, models.ResourceAvailable).Order("id").Find(&copies).Error
+	return copies, err
+}
+
+func (r gormCopies) Counts(resourceIDs []uint) (map[uint]service.CopyCounts, error) {
+	var rows []service.CopyCounts
+	err := r.db.Model(&models.Copy{}).
+		Select("resource_id, COUNT(*) AS total, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS available, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS borrowed, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS reserved, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS unavailable",
+			models.ResourceAvailable, models.ResourceBorrowed, models.ResourceReserved, models.ResourceUnavailable).
+		Where("resource_id IN ?", resourceIDs).
+		Group("resource_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]service.CopyCounts, len(rows))
+	for _, row := range rows {
+		counts[row.ResourceID] = row
+	}
+	return counts, nil
+}
+
+func (r gormCopies) BarcodeTaken(barcode string, exceptID uint) (bool, error) {
+	n, err := count(r.db.Model(&models.Copy{}).Where("barcode = ? AND id <> ?", barcode, exceptID))
+	return n > 0, err
+}
+
+func (r gormCopies) Create(copy *models.Copy) error {
+	return constraint(r.db.Omit("Loans").Create(copy).Error)
+}
+
+func (r gormCopies) Update(copy *models.Copy) error {
+	return r.db.Model(copy).Select("barcode", "location", "condition").Updates(copy).Error
+}
+
+func (r gormCopies) SetStatus(id uint, from, to models.ResourceStatus) error {
+	return affected(r.db.Model(&models.Copy{}).Where("id = ? AND status = ?", id, from).Update("status", to), service.ErrStale)
+}
+
+type gormHolds struct {
+	db *gorm.DB
+}
+
+// activeHoldStatuses sont les statuts d'une réservation encore dans la file ou en attente de retrait.
+var activeHoldStatuses = []models.HoldStatus{models.HoldWaiting, models.HoldReady}
+
+func (r gormHolds) FindByID(id uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := r.db.First(&hold, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &hold, nil
+}
+
+// find retourne les réservations de la requête dans l'ordre de la file.
+func (r gormHolds) find(query *gorm.DB) ([]models.Hold, error) {
+	holds := []models.Hold{}
+	err := query.Order("created_at, id").Find(&holds).Error
+	return holds, err
+}
+
+func (r gormHolds) ListByUser(userID uint) ([]models.Hold, error) {
+	return r.find(r.db.Where("user_id = ?", userID))
+}
+
+func (r gormHolds) ListActive(resourceID uint) ([]models.Hold, error) {
+	return r.find(r.db.Where("resource_id = ? AND status IN ?", resourceID, activeHoldStatuses))
+}
+
+func (r gormHolds) Queue(resourceID uint) ([]models.Hold, error) {
+	return r.find(r.db.Where("resource_id = ? AND status = ?", resourceID, models.HoldWaiting))
+}
+
+func (r gormHolds) FindReady(userID, resourceID uint) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.db.Where("user_id = ? AND resource_id = ? AND status = ?", userID, resourceID, models.HoldReady).
+		First(&hold).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &hold, nil
+}
+
+func (r gormHolds) CountActive(userID, resourceID uint) (int, error) {
+	return count(r.db.Model(&models.Hold{}).
+		Where("user_id = ? AND resource_id = ? AND status IN ?", userID, resourceID, activeHoldStatuses))
+}
+
+func (r gormHolds) ListExpired(resourceID uint, now time.Time) ([]models.Hold, error) {
+	var holds []models.Hold
+	query := r.db.Where("status = ? AND pickup_deadline < ?", models.HoldReady, now)
+	if resourceID != 0 {
+		query = query.Where("resource_id = ?", resourceID)
+	}
+	err := query.Order("pickup_deadline, id").Find(&holds).Error
+	return holds, err
+}
+
+func (r gormHolds) ResourcesToServe() ([]uint, error) {
+	var resourceIDs []uint
+	err := r.db.Model(&models.Hold{}).
+		Distinct("resource_id").
+		Where("status = ?", models.HoldWaiting).
+		Where("EXISTS (SELECT 1 FROM copies WHERE copies.resource_id = holds.resource_id AND copies.status = ?)", models.ResourceAvailable).
+		Order("resource_id").
+		Pluck("resource_id", &resourceIDs).Error
+	return resourceIDs, err
+}
+
+func (r gormHolds) Create(hold *models.Hold) error {
+	return r.db.Create(hold).Error
+}
+
+func (r gormHolds) Update(hold *models.Hold, from models.HoldStatus) error {
+	return affected(r.db.Model(&models.Hold{}).
+		Where("id = ? AND status = ?", hold.ID, from).
+		Updates(map[string]interface{}{
+			"status":          hold.Status,
+			"copy_id":         hold.CopyID,
+			"ready_at":        hold.ReadyAt,
+			"pickup_deadline": hold.PickupDeadline,
+		}), service.ErrStale)
+}
+
+type gormLoans struct {
+	db *gorm.DB
+}
+
+// withRenewals charge l'historique des renouvellements dans l'ordre.
+func withRenewals(db *gorm.DB) *gorm.DB {
+	return db.Preload("Renewals", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+}
+
+func (r gormLoans) FindByID(id uint) (*models.Loan, error) {
+	var loan models.Loan
+	if err := withRenewals(r.db).First(&loan, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &loan, nil
+}
+
+func (r gormLoans) ListByUser(userID uint) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := withRenewals(r.db).Where("user_id = ?", userID).Find(&loans).Error
+	return loans, err
+}
+
+func (r gormLoans) ListDue(now time.Time) ([]models.Loan, error) {
+	var loans []models.Loan
+	err := r.db.Where("status = ? AND return_date < ?", models.LoanActive, now).Order("id").Find(&loans).Error
+	return loans, err
+}
+
+func (r gormLoans) ListOverdue() ([]models.Loan, error) {
+	var loans []models.Loan
+	err := r.db.Where("status = ?", models.LoanOverdue).Order("id").Find(&loans).Error
+	return loans, err
+}
+
+func (r gormLoans) Create(loan *models.Loan) error {
+	return r.db.Create(loan).Error
+}
+
+func (r gormLoans) CountOpen(userID uint, resourceType, borrowType string) (int, error) {
+	return count(r.db.Model(&models.Loan{}).
+		Joins("JOIN resources ON resources.id = loans.resource_id").
+		Where("loans.user_id = ? AND loans.borrow_type = ? AND loans.status IN ?", userID, borrowType, models.OpenLoanStatuses).
+		Where("resources.type = ?", resourceType))
+}
+
+func (r gormLoans) CountOpenByResource(resourceID, userID uint) (int, error) {
+	query := r.db.Model(&models.Loan{}).Where("resource_id = ? AND status IN ?", resourceID, models.OpenLoanStatuses)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	return count(query)
+}
+
+func (r gormLoans) SetStatus(id uint, from, to models.LoanStatus) error {
+	return affected(r.db.Model(&models.Loan{}).Where("id = ? AND status = ?", id, from).Update("status", to), service.ErrStale)
+}
+
+func (r gormLoans) Renew(loan *models.Loan, renewal models.LoanRenewal) error {
+	// Mise à jour conditionnelle : deux renouvellements simultanés ne comptent pas pour un
+	err := affected(r.db.Model(&models.Loan{}).
+		Where("id = ? AND status = ? AND renewal_count = ?", loan.ID, loan.Status, loan.RenewalCount).
+		Updates(map[string]interface{}{
+			"return_date":   renewal.NewReturnDate,
+			"renewal_count": loan.RenewalCount + 1,
+		}), service.ErrStale)
+	if err != nil {
+		return err
+	}
+	return r.db.Create(&renewal).Error
+}
+
+type gormFines struct {
+	db *gorm.DB
+}
+
+func (r gormFines) ListByUser(userID uint) ([]models.FineEntry, error) {
+	entries := []models.FineEntry{}
+	err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&entries).Error
+	return entries, err
+}
+
+func (r gormFines) Balance(userID uint) (int, error) {
+	var balance int
+	err := r.db.Model(&models.FineEntry{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// Charge inscrit l'écart sous SQLite en une seule requête, de sorte que deux calculs simultanés
+// n'inscrivent pas deux fois la même amende ; sous PostgreSQL, le prêt est verrouillé le temps du calcul.
+func (r gormFines) Charge(loan *models.Loan, due int, now time.Time) (int, error) {
+	if isPostgres(r.db) {
+		return r.chargeLocked(loan, due, now)
+	}
+
+	charged, err := r.charged(loan.ID)
+	if err != nil {
+		return 0, err
+	}
+	if charged >= due {
+		return 0, nil
+	}
+
+	result := r.db.Exec(`INSERT INTO fine_entries (user_id, loan_id, kind, amount, note, created_at)
+		SELECT ?, ?, ?, ? - COALESCE(SUM(amount), 0), '', ?
+		FROM fine_entries WHERE loan_id = ? AND kind = ?
+		HAVING ? - COALESCE(SUM(amount), 0) > 0`,
+		loan.UserID, loan.ID, models.FineCharge, due, now, loan.ID, models.FineCharge, due)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, nil
+	}
+	return due - charged, nil
+}
+
+// chargeLocked inscrit l'écart entre due et l'amende déjà inscrite, prêt verrouillé :
+// un calcul simultané attend la fin de la transaction puis lit le nouveau total.
+func (r gormFines) chargeLocked(loan *models.Loan, due int, now time.Time) (int, error) {
+	added := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := forUpdate(tx).Select("id").First(&models.Loan{}, loan.ID).Error; err != nil {
+			return notFound(err)
+		}
+		charged, err := gormFines{tx}.charged(loan.ID)
+		if err != nil || charged >= due {
+			return err
+		}
+		entry := models.FineEntry{
+			UserID:    loan.UserID,
+			LoanID:    &loan.ID,
+			Kind:      models.FineCharge,
+			Amount:    due - charged,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		added = entry.Amount
+		return nil
+	})
+	return added, err
+}
+
+// charged retourne le total des amendes déjà inscrites pour un prêt.
+func (r gormFines) charged(loanID uint) (int, error) {
+	var charged int
+	err := r.db.Model(&models.FineEntry{}).
+		Where("loan_id = ? AND kind = ?", loanID, models.FineCharge).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&charged).Error
+	return charged, err
+}
+
+func (r gormFines) Create(entry *models.FineEntry) error {
+	return r.db.Create(entry).Error
+}
+
+type gormPolicies struct {
+	db *gorm.DB
+}
+
+func (r gormPolicies) FindByID(id uint) (*models.LoanPolicy, error) {
+	var policy models.LoanPolicy
+	if err := r.db.First(&policy, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &policy, nil
+}
+
+func (r gormPolicies) Find(resourceType, borrowType string) (*models.LoanPolicy, error) {
+	var policy models.LoanPolicy
+	if err := r.db.Where("resource_type = ? AND borrow_type = ?", resourceType, borrowType).First(&policy).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &policy, nil
+}
+
+func (r gormPolicies) List() ([]models.LoanPolicy, error) {
+	var policies []models.LoanPolicy
+	err := r.db.Order("resource_type, borrow_type").Find(&policies).Error
+	return policies, err
+}
+
+func (r gormPolicies) Exists(resourceType, borrowType string, exceptID uint) (bool, error) {
+	n, err := count(r.db.Model(&models.LoanPolicy{}).
+		Where("resource_type = ? AND borrow_type = ? AND id <> ?", resourceType, borrowType, exceptID))
+	return n > 0, err
+}
+
+func (r gormPolicies) Save(policy *models.LoanPolicy) error {
+	return r.db.Save(policy).Error
+}
+
+func (r gormPolicies) Delete(id uint) error {
+	return affected(r.db.Delete(&models.LoanPolicy{}, id), service.ErrNotFound)
+}
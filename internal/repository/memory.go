@@ -0,0 +1,1075 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+)
+
+// errDuplicateEmail reproduit la contrainte d'unicité de l'email de la base SQL.
+var errDuplicateEmail = errors.New("email déjà enregistré")
+
+// MemoryStore stocke les données en mémoire, pour tester les règles métier sans base SQL.
+// Les transactions sont sérialisées : une transaction qui échoue restaure l'état initial.
+type MemoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	inTx bool // Le verrou est déjà tenu par la transaction en cours
+}
+
+// memoryData regroupe les tables. Les enregistrements sont stockés par valeur ;
+// les champs pointeurs sont remplacés, jamais modifiés, pour que les copies restent indépendantes.
+type memoryData struct {
+	ids       map[string]uint // Dernier identifiant attribué par table
+	users     map[uint]models.User
+	resources map[uint]models.Resource // Avec la notice et la fiche, sans les exemplaires
+	copies    map[uint]models.Copy
+	holds     map[uint]models.Hold
+	policies  map[uint]models.LoanPolicy
+	loans     map[uint]models.Loan
+	renewals  []models.LoanRenewal
+	fines     []models.FineEntry
+	refresh   map[uint]models.RefreshToken
+	revoked   map[string]models.RevokedToken // Par identifiant de token d'accès
+}
+
+// NewMemoryStore crée un stockage en mémoire vide.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			ids:       map[string]uint{},
+			users:     map[uint]models.User{},
+			resources: map[uint]models.Resource{},
+			copies:    map[uint]models.Copy{},
+			holds:     map[uint]models.Hold{},
+			policies:  map[uint]models.LoanPolicy{},
+			loans:     map[uint]models.Loan{},
+			refresh:   map[uint]models.RefreshToken{},
+			revoked:   map[string]models.RevokedToken{},
+		},
+	}
+}
+
+// clone copie les tables pour pouvoir les restaurer.
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		ids:       maps.Clone(d.ids),
+		users:     maps.Clone(d.users),
+		resources: maps.Clone(d.resources),
+		copies:    maps.Clone(d.copies),
+		holds:     maps.Clone(d.holds),
+		policies:  maps.Clone(d.policies),
+		loans:     maps.Clone(d.loans),
+		renewals:  slices.Clone(d.renewals),
+		fines:     slices.Clone(d.fines),
+		refresh:   maps.Clone(d.refresh),
+		revoked:   maps.Clone(d.revoked),
+	}
+}
+
+// next attribue l'identifiant suivant de la table.
+func (d *memoryData) next(table string) uint {
+	d.ids[table]++
+	return d.ids[table]
+}
+
+// lock prend le verrou hors transaction et retourne la fonction qui le libère.
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *MemoryStore) Users() service.UserRepository         { return memoryUsers{s} }
+func (s *MemoryStore) Resources() service.ResourceRepository { return memoryResources{s} }
+func (s *MemoryStore) Copies() service.CopyRepository        { return memoryCopies{s} }
+func (s *MemoryStore) Holds() service.HoldRepository         { return memoryHolds{s} }
+func (s *MemoryStore) Loans() service.LoanRepository         { return memoryLoans{s} }
+func (s *MemoryStore) Fines() service.FineRepository         { return memoryFines{s} }
+func (s *MemoryStore) Policies() service.PolicyRepository    { return memoryPolicies{s} }
+func (s *MemoryStore) Tokens() service.TokenRepository       { return memoryTokens{s} }
+
+// Transaction exécute fn en tenant le verrou ; si fn retourne une erreur, les tables sont restaurées.
+func (s *MemoryStore) Transaction(fn func(service.Store) error) error {
+	defer s.lock()()
+	snapshot := s.data.clone()
+	if err := fn(&MemoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+// AddResource enregistre une ressource et ses exemplaires, disponibles par défaut.
+// Sans exemplaire fourni, un exemplaire disponible est créé.
+func (s *MemoryStore) AddResource(resource *models.Resource) {
+	defer s.lock()()
+	copies := resource.Copies
+	if len(copies) == 0 {
+		copies = []models.Copy{{}}
+	}
+	resource.ID = s.data.next("resources")
+	if resource.Status == "" {
+		resource.Status = models.ResourceAvailable
+	}
+	for i := range copies {
+		copies[i].ID = s.data.next("copies")
+		copies[i].ResourceID = resource.ID
+		if copies[i].Status == "" {
+			copies[i].Status = models.ResourceAvailable
+		}
+		s.data.copies[copies[i].ID] = copies[i]
+	}
+	resource.Copies = nil
+	s.data.resources[resource.ID] = *resource
+	resource.Copies = copies
+}
+
+// AddPolicy enregistre une politique de prêt.
+func (s *MemoryStore) AddPolicy(policy models.LoanPolicy) {
+	defer s.lock()()
+	policy.ID = s.data.next("loan_policies")
+	s.data.policies[policy.ID] = policy
+}
+
+// AddHold enregistre une réservation, en attente par défaut.
+func (s *MemoryStore) AddHold(hold *models.Hold) {
+	defer s.lock()()
+	hold.ID = s.data.next("holds")
+	if hold.Status == "" {
+		hold.Status = models.HoldWaiting
+	}
+	s.data.holds[hold.ID] = *hold
+}
+
+// AddFine inscrit une écriture au compte d'amendes.
+func (s *MemoryStore) AddFine(entry models.FineEntry) {
+	defer s.lock()()
+	entry.ID = s.data.next("fine_entries")
+	s.data.fines = append(s.data.fines, entry)
+}
+
+// Copy retourne l'exemplaire enregistré.
+func (s *MemoryStore) Copy(id uint) (models.Copy, bool) {
+	defer s.lock()()
+	copy, ok := s.data.copies[id]
+	return copy, ok
+}
+
+// Hold retourne la réservation enregistrée.
+func (s *MemoryStore) Hold(id uint) (models.Hold, bool) {
+	defer s.lock()()
+	hold, ok := s.data.holds[id]
+	return hold, ok
+}
+
+// sorted retourne les enregistrements de la table retenus par keep, par identifiant croissant.
+func sorted[T any](table map[uint]T, keep func(T) bool) []T {
+	var rows []T
+	for _, id := range slices.Sorted(maps.Keys(table)) {
+		if keep(table[id]) {
+			rows = append(rows, table[id])
+		}
+	}
+	return rows
+}
+
+type memoryUsers struct {
+	s *MemoryStore
+}
+
+func (r memoryUsers) FindByID(id uint) (*models.User, error) {
+	defer r.s.lock()()
+	user, ok := r.s.data.users[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r memoryUsers) FindByEmail(email string) (*models.User, error) {
+	defer r.s.lock()()
+	for _, user := range r.s.data.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, service.ErrNotFound
+}
+
+func (r memoryUsers) List() ([]models.User, error) {
+	defer r.s.lock()()
+	users := sorted(r.s.data.users, func(models.User) bool { return true })
+	for i := range users {
+		users[i].Password = ""
+	}
+	return users, nil
+}
+
+func (r memoryUsers) Create(user *models.User) error {
+	defer r.s.lock()()
+	for _, other := range r.s.data.users {
+		if other.Email == user.Email {
+			return errDuplicateEmail
+		}
+	}
+	if user.Role == "" {
+		user.Role = models.RoleMember
+	}
+	user.ID = r.s.data.next("users")
+	r.s.data.users[user.ID] = *user
+	return nil
+}
+
+func (r memoryUsers) Update(user *models.User) error {
+	defer r.s.lock()()
+	if _, ok := r.s.data.users[user.ID]; !ok {
+		return service.ErrNotFound
+	}
+	for _, other := range r.s.data.users {
+		if other.Email == user.Email && other.ID != user.ID {
+			return errDuplicateEmail
+		}
+	}
+	r.s.data.users[user.ID] = *user
+	return nil
+}
+
+func (r memoryUsers) SetRole(id uint, role string) error {
+	defer r.s.lock()()
+	user, ok := r.s.data.users[id]
+	if !ok {
+		return service.ErrNotFound
+	}
+	user.Role = role
+	r.s.data.users[id] = user
+	return nil
+}
+
+// Lock vérifie seulement que l'utilisateur existe : les transactions en mémoire sont déjà sérialisées.
+func (r memoryUsers) Lock(id uint) error {
+	defer r.s.lock()()
+	if _, ok := r.s.data.users[id]; !ok {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (r memoryUsers) RevokeSessions(id uint, at time.Time) error {
+	defer r.s.lock()()
+	user, ok := r.s.data.users[id]
+	if !ok {
+		return service.ErrNotFound
+	}
+	for tokenID, token := range r.s.data.refresh {
+		if token.UserID == id && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.s.data.refresh[tokenID] = token
+		}
+	}
+	user.TokensRevokedAt = &at
+	r.s.data.users[id] = user
+	return nil
+}
+
+type memoryTokens struct {
+	s *MemoryStore
+}
+
+func (r memoryTokens) FindRefresh(hash string) (*models.RefreshToken, error) {
+	defer r.s.lock()()
+	for _, token := range r.s.data.refresh {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, service.ErrNotFound
+}
+
+func (r memoryTokens) CreateRefresh(token *models.RefreshToken) error {
+	defer r.s.lock()()
+	token.ID = r.s.data.next("refresh_tokens")
+	r.s.data.refresh[token.ID] = *token
+	return nil
+}
+
+func (r memoryTokens) ConsumeRefresh(id uint, at time.Time) error {
+	defer r.s.lock()()
+	token, ok := r.s.data.refresh[id]
+	if !ok || token.RevokedAt != nil {
+		return service.ErrStale
+	}
+	token.RevokedAt = &at
+	r.s.data.refresh[id] = token
+	return nil
+}
+
+func (r memoryTokens) SetReplacedBy(id, replacedByID uint) error {
+	defer r.s.lock()()
+	if token, ok := r.s.data.refresh[id]; ok {
+		token.ReplacedByID = &replacedByID
+		r.s.data.refresh[id] = token
+	}
+	return nil
+}
+
+func (r memoryTokens) RevokeRefresh(userID uint, hash string, at time.Time) error {
+	defer r.s.lock()()
+	for id, token := range r.s.data.refresh {
+		if token.TokenHash == hash && token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.s.data.refresh[id] = token
+		}
+	}
+	return nil
+}
+
+func (r memoryTokens) RevokeAccess(token models.RevokedToken, now time.Time) error {
+	defer r.s.lock()()
+	maps.DeleteFunc(r.s.data.revoked, func(_ string, t models.RevokedToken) bool {
+		return t.ExpiresAt.Before(now)
+	})
+	r.s.data.revoked[token.JTI] = token
+	return nil
+}
+
+func (r memoryTokens) AccessRevoked(jti string) (bool, error) {
+	defer r.s.lock()()
+	_, ok := r.s.data.revoked[jti]
+	return ok, nil
+}
+
+type memoryResources struct {
+	s *MemoryStore
+}
+
+// resource retourne la ressource si elle n'est pas supprimée.
+func (r memoryResources) resource(id uint) (models.Resource, bool) {
+	resource, ok := r.s.data.resources[id]
+	return resource, ok && !resource.DeletedAt.Valid
+}
+
+// active retourne les ressources non supprimées retenues par keep, par identifiant.
+func (r memoryResources) active(keep func(models.Resource) bool) []models.Resource {
+	return sorted(r.s.data.resources, func(res models.Resource) bool {
+		return !res.DeletedAt.Valid && keep(res)
+	})
+}
+
+func (r memoryResources) FindByID(id uint) (*models.Resource, error) {
+	defer r.s.lock()()
+	resource, ok := r.resource(id)
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	return &resource, nil
+}
+
+func (r memoryResources) FindWithDetails(id uint) (*models.Resource, error) {
+	defer r.s.lock()()
+	resource, ok := r.resource(id)
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	resource.Copies = sorted(r.s.data.copies, func(c models.Copy) bool { return c.ResourceID == id })
+	return &resource, nil
+}
+
+func (r memoryResources) ListByIDs(ids []uint) ([]models.Resource, error) {
+	defer r.s.lock()()
+	return r.active(func(res models.Resource) bool { return slices.Contains(ids, res.ID) }), nil
+}
+
+// memoryQuery applique une recherche du catalogue aux ressources en mémoire.
+type memoryQuery struct {
+	service.ResourceQuery
+}
+
+// matches indique si la ressource correspond aux critères de recherche.
+func (q memoryQuery) matches(res models.Resource) bool {
+	title := strings.ToLower(res.Title)
+	for _, word := range strings.Fields(strings.ToLower(q.Search)) {
+		if !strings.Contains(title, word) {
+			return false
+		}
+	}
+	if (q.Type != "" && res.Type != q.Type) || (q.Status != "" && res.Status != q.Status) {
+		return false
+	}
+
+	if q.Author != "" || q.Language != "" || q.Genre != "" || q.Year != 0 {
+		book := res.Book
+		if book == nil ||
+			(q.Author != "" && !slices.ContainsFunc(book.Authors, func(a string) bool {
+				return strings.Contains(strings.ToLower(a), strings.ToLower(q.Author))
+			})) ||
+			(q.Language != "" && book.Language != q.Language) ||
+			(q.Genre != "" && !slices.ContainsFunc(book.Genres, func(g string) bool { return strings.EqualFold(g, q.Genre) })) ||
+			(q.Year != 0 && book.Year != q.Year) {
+			return false
+		}
+	}
+
+	if q.Players != 0 || q.MaxPlayTime != 0 || q.Age != 0 {
+		game := res.Game
+		if game == nil ||
+			(q.Players != 0 && (game.MinPlayers > q.Players || (game.MaxPlayers != 0 && game.MaxPlayers < q.Players))) ||
+			(q.MaxPlayTime != 0 && (game.PlayTime == 0 || game.PlayTime > q.MaxPlayTime)) ||
+			(q.Age != 0 && game.MinAge > q.Age) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r memoryResources) Search(q service.ResourceQuery) ([]models.Resource, int64, error) {
+	defer r.s.lock()()
+	resources := r.active(memoryQuery{q}.matches)
+
+	compare := func(a, b models.Resource) int {
+		switch q.Sort {
+		case "id":
+			return 0
+		case "type":
+			return cmp.Compare(a.Type, b.Type)
+		case "status":
+			return cmp.Compare(a.Status, b.Status)
+		default:
+			return cmp.Compare(a.Title, b.Title)
+		}
+	}
+	slices.SortStableFunc(resources, func(a, b models.Resource) int {
+		c := cmp.Or(compare(a, b), cmp.Compare(a.ID, b.ID))
+		if q.Descending {
+			return -c
+		}
+		return c
+	})
+
+	total := int64(len(resources))
+	start := min((q.Page-1)*q.PerPage, len(resources))
+	end := min(start+q.PerPage, len(resources))
+	return append([]models.Resource{}, resources[start:end]...), total, nil
+}
+
+// FullText retient les titres dont chaque mot de la saisie commence un mot, sans tenir compte de la casse.
+// Tous les résultats ont la même pertinence ; l'extrait est le titre entier.
+func (r memoryResources) FullText(input, resourceType string, page, perPage int) ([]service.TextMatch, int64, error) {
+	defer r.s.lock()()
+	words := strings.Fields(strings.ToLower(input))
+	if len(words) == 0 {
+		return nil, 0, nil
+	}
+
+	var matches []service.TextMatch
+	for _, res := range r.active(func(res models.Resource) bool { return resourceType == "" || res.Type == resourceType }) {
+		titleWords := strings.Fields(res.Title)
+		marked := false
+		all := true
+		for _, word := range words {
+			found := false
+			for i, titleWord := range titleWords {
+				if strings.HasPrefix(strings.ToLower(strings.Trim(titleWord, service.MarkStart+service.MarkEnd)), word) {
+					if !strings.HasPrefix(titleWord, service.MarkStart) {
+						titleWords[i] = service.MarkStart + titleWord + service.MarkEnd
+					}
+					found, marked = true, true
+				}
+			}
+			all = all && found
+		}
+		if all && marked {
+			matches = append(matches, service.TextMatch{ID: res.ID, Snippet: strings.Join(titleWords, " "), Score: 1})
+		}
+	}
+
+	total := int64(len(matches))
+	start := min((page-1)*perPage, len(matches))
+	end := min(start+perPage, len(matches))
+	return matches[start:end], total, nil
+}
+
+func (r memoryResources) FindDuplicate(resource models.Resource) (uint, error) {
+	defer r.s.lock()()
+	for _, other := range sorted(r.s.data.resources, func(models.Resource) bool { return true }) {
+		if resource.Book != nil && resource.Book.ISBN != nil {
+			// La notice d'une ressource supprimée est conservée : son ISBN reste attribué
+			if other.Book != nil && other.Book.ISBN != nil && *other.Book.ISBN == *resource.Book.ISBN {
+				return other.ID, nil
+			}
+		} else if !other.DeletedAt.Valid && models.TitleKey(other.Title) == models.TitleKey(resource.Title) && other.Type == resource.Type {
+			return other.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r memoryResources) ISBNTaken(isbn string, exceptID uint) (bool, error) {
+	defer r.s.lock()()
+	for _, other := range r.s.data.resources {
+		if other.ID != exceptID && other.Book != nil && other.Book.ISBN != nil && *other.Book.ISBN == isbn {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryResources) Create(resource *models.Resource) error {
+	defer r.s.lock()()
+	resource.ID = r.s.data.next("resources")
+	if resource.Status == "" {
+		resource.Status = models.ResourceAvailable
+	}
+	stored := *resource
+	stored.Copies = nil
+	stored.Book, stored.Game = detailsOf(resource)
+	r.s.data.resources[resource.ID] = stored
+	return nil
+}
+
+// detailsOf retourne des copies de la notice et de la fiche de la ressource, rattachées à celle-ci.
+func detailsOf(resource *models.Resource) (*models.BookDetails, *models.GameDetails) {
+	var book *models.BookDetails
+	var game *models.GameDetails
+	if resource.Book != nil {
+		resource.Book.ResourceID = resource.ID
+		copied := *resource.Book
+		book = &copied
+	}
+	if resource.Game != nil {
+		resource.Game.ResourceID = resource.ID
+		copied := *resource.Game
+		game = &copied
+	}
+	return book, game
+}
+
+func (r memoryResources) Update(resource *models.Resource) error {
+	defer r.s.lock()()
+	stored, ok := r.resource(resource.ID)
+	if !ok {
+		return service.ErrNotFound
+	}
+	stored.Title = resource.Title
+	stored.Type = resource.Type
+	stored.Book, stored.Game = detailsOf(resource)
+	r.s.data.resources[resource.ID] = stored
+	return nil
+}
+
+func (r memoryResources) SetStatus(id uint, status models.ResourceStatus) error {
+	defer r.s.lock()()
+	resource, ok := r.s.data.resources[id]
+	if !ok {
+		return nil
+	}
+	resource.Status = status
+	r.s.data.resources[id] = resource
+	return nil
+}
+
+// Lock vérifie seulement que la ressource existe : les transactions en mémoire sont déjà sérialisées.
+func (r memoryResources) Lock(id uint) error {
+	defer r.s.lock()()
+	if _, ok := r.resource(id); !ok {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (r memoryResources) Delete(id uint) error {
+	defer r.s.lock()()
+	resource, ok := r.resource(id)
+	if !ok {
+		return service.ErrNotFound
+	}
+	resource.DeletedAt.Time, resource.DeletedAt.Valid = time.Now(), true
+	r.s.data.resources[id] = resource
+	return nil
+}
+
+func (r memoryResources) ListDeleted() ([]models.Resource, error) {
+	defer r.s.lock()()
+	resources := sorted(r.s.data.resources, func(res models.Resource) bool { return res.DeletedAt.Valid })
+	slices.SortStableFunc(resources, func(a, b models.Resource) int { return b.DeletedAt.Time.Compare(a.DeletedAt.Time) })
+	return append([]models.Resource{}, resources...), nil
+}
+
+func (r memoryResources) Restore(id uint) error {
+	defer r.s.lock()()
+	resource, ok := r.s.data.resources[id]
+	if !ok || !resource.DeletedAt.Valid {
+		return service.ErrNotFound
+	}
+	resource.DeletedAt.Valid = false
+	r.s.data.resources[id] = resource
+	return nil
+}
+
+func (r memoryResources) Export(f service.ResourceExportFilter, fn func(service.ResourceExport) error) error {
+	defer r.s.lock()()
+	for _, res := range r.active(func(res models.Resource) bool {
+		return (f.Type == "" || res.Type == f.Type) && (f.Status == "" || res.Status == f.Status)
+	}) {
+		var counts service.CopyCounts
+		for _, copy := range r.s.data.copies {
+			if copy.ResourceID == res.ID {
+				counts.Add(copy.Status)
+			}
+		}
+		row := service.ResourceExport{
+			ID: res.ID, Title: res.Title, Type: res.Type, Status: string(res.Status),
+			TotalCopies: counts.Total, AvailableCopies: counts.Available,
+		}
+		if book := res.Book; book != nil {
+			row.Authors, row.Publisher, row.Year, row.Language, row.Genres, row.CoverURL =
+				book.Authors, book.Publisher, book.Year, book.Language, book.Genres, book.CoverURL
+			if book.ISBN != nil {
+				row.ISBN = *book.ISBN
+			}
+		}
+		if game := res.Game; game != nil {
+			row.Designer, row.MinPlayers, row.MaxPlayers, row.PlayTime, row.MinAge, row.Complexity =
+				game.Designer, game.MinPlayers, game.MaxPlayers, game.PlayTime, game.MinAge, game.Complexity
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memoryCopies struct {
+	s *MemoryStore
+}
+
+func (r memoryCopies) FindByID(id uint) (*models.Copy, error) {
+	defer r.s.lock()()
+	copy, ok := r.s.data.copies[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	return &copy, nil
+}
+
+func (r memoryCopies) ListByResource(resourceID uint) ([]models.Copy, error) {
+	defer r.s.lock()()
+	copies := sorted(r.s.data.copies, func(c models.Copy) bool { return c.ResourceID == resourceID })
+	return append([]models.Copy{}, copies...), nil
+}
+
+func (r memoryCopies) ListAvailable(resourceID uint) ([]models.Copy, error) {
+	defer r.s.lock()()
+	return sorted(r.s.data.copies, func(c models.Copy) bool {
+		return c.ResourceID == resourceID && c.Status == models.ResourceAvailable
+	}), nil
+}
+
+func (r memoryCopies) Counts(resourceIDs []uint) (map[uint]service.CopyCounts, error) {
+	defer r.s.lock()()
+	counts := map[uint]service.CopyCounts{}
+	for _, copy := range r.s.data.copies {
+		if !slices.Contains(resourceIDs, copy.ResourceID) {
+			continue
+		}
+		c := counts[copy.ResourceID]
+		c.ResourceID = copy.ResourceID
+		c.Add(copy.Status)
+		counts[copy.ResourceID] = c
+	}
+	return counts, nil
+}
+
+func (r memoryCopies) BarcodeTaken(barcode string, exceptID uint) (bool, error) {
+	defer r.s.lock()()
+	for _, copy := range r.s.data.copies {
+		if copy.Barcode == barcode && copy.ID != exceptID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryCopies) Create(copy *models.Copy) error {
+	defer r.s.lock()()
+	copy.ID = r.s.data.next("copies")
+	r.s.data.copies[copy.ID] = *copy
+	return nil
+}
+
+func (r memoryCopies) Update(copy *models.Copy) error {
+	defer r.s.lock()()
+	stored, ok := r.s.data.copies[copy.ID]
+	if !ok {
+		return service.ErrNotFound
+	}
+	stored.Barcode, stored.Location, stored.Condition = copy.Barcode, copy.Location, copy.Condition
+	r.s.data.copies[copy.ID] = stored
+	return nil
+}
+
+func (r memoryCopies) SetStatus(id uint, from, to models.ResourceStatus) error {
+	defer r.s.lock()()
+	copy, ok := r.s.data.copies[id]
+	if !ok || copy.Status != from {
+		return service.ErrStale
+	}
+	copy.Status = to
+	r.s.data.copies[id] = copy
+	return nil
+}
+
+type memoryHolds struct {
+	s *MemoryStore
+}
+
+// queued retourne les réservations retenues par keep dans l'ordre de la file : date de réservation, puis identifiant.
+func (r memoryHolds) queued(keep func(models.Hold) bool) []models.Hold {
+	holds := sorted(r.s.data.holds, keep)
+	slices.SortStableFunc(holds, func(a, b models.Hold) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return append([]models.Hold{}, holds...)
+}
+
+// active indique si la réservation est encore dans la file ou en attente de retrait.
+func active(h models.Hold) bool {
+	return h.Status == models.HoldWaiting || h.Status == models.HoldReady
+}
+
+func (r memoryHolds) FindByID(id uint) (*models.Hold, error) {
+	defer r.s.lock()()
+	hold, ok := r.s.data.holds[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	return &hold, nil
+}
+
+func (r memoryHolds) ListByUser(userID uint) ([]models.Hold, error) {
+	defer r.s.lock()()
+	return r.queued(func(h models.Hold) bool { return h.UserID == userID }), nil
+}
+
+func (r memoryHolds) ListActive(resourceID uint) ([]models.Hold, error) {
+	defer r.s.lock()()
+	return r.queued(func(h models.Hold) bool { return h.ResourceID == resourceID && active(h) }), nil
+}
+
+func (r memoryHolds) Queue(resourceID uint) ([]models.Hold, error) {
+	defer r.s.lock()()
+	return r.queued(func(h models.Hold) bool { return h.ResourceID == resourceID && h.Status == models.HoldWaiting }), nil
+}
+
+func (r memoryHolds) FindReady(userID, resourceID uint) (*models.Hold, error) {
+	defer r.s.lock()()
+	for _, hold := range r.queued(func(h models.Hold) bool {
+		return h.UserID == userID && h.ResourceID == resourceID && h.Status == models.HoldReady
+	}) {
+		return &hold, nil
+	}
+	return nil, service.ErrNotFound
+}
+
+func (r memoryHolds) CountActive(userID, resourceID uint) (int, error) {
+	defer r.s.lock()()
+	return len(sorted(r.s.data.holds, func(h models.Hold) bool {
+		return h.UserID == userID && h.ResourceID == resourceID && active(h)
+	})), nil
+}
+
+func (r memoryHolds) ListExpired(resourceID uint, now time.Time) ([]models.Hold, error) {
+	defer r.s.lock()()
+	holds := sorted(r.s.data.holds, func(h models.Hold) bool {
+		return h.Status == models.HoldReady && h.PickupDeadline != nil && h.PickupDeadline.Before(now) &&
+			(resourceID == 0 || h.ResourceID == resourceID)
+	})
+	slices.SortStableFunc(holds, func(a, b models.Hold) int { return a.PickupDeadline.Compare(*b.PickupDeadline) })
+	return holds, nil
+}
+
+func (r memoryHolds) ResourcesToServe() ([]uint, error) {
+	defer r.s.lock()()
+	var resourceIDs []uint
+	for _, hold := range r.s.data.holds {
+		if hold.Status != models.HoldWaiting || slices.Contains(resourceIDs, hold.ResourceID) {
+			continue
+		}
+		for _, copy := range r.s.data.copies {
+			if copy.ResourceID == hold.ResourceID && copy.Status == models.ResourceAvailable {
+				resourceIDs = append(resourceIDs, hold.ResourceID)
+				break
+			}
+		}
+	}
+	slices.Sort(resourceIDs)
+	return resourceIDs, nil
+}
+
+func (r memoryHolds) Create(hold *models.Hold) error {
+	defer r.s.lock()()
+	hold.ID = r.s.data.next("holds")
+	r.s.data.holds[hold.ID] = *hold
+	return nil
+}
+
+func (r memoryHolds) Update(hold *models.Hold, from models.HoldStatus) error {
+	defer r.s.lock()()
+	stored, ok := r.s.data.holds[hold.ID]
+	if !ok || stored.Status != from {
+		return service.ErrStale
+	}
+	stored.Status, stored.CopyID, stored.ReadyAt, stored.PickupDeadline = hold.Status, hold.CopyID, hold.ReadyAt, hold.PickupDeadline
+	r.s.data.holds[hold.ID] = stored
+	return nil
+}
+
+type memoryLoans struct {
+	s *MemoryStore
+}
+
+// withRenewals joint au prêt l'historique de ses renouvellements.
+func (r memoryLoans) withRenewals(loan models.Loan) models.Loan {
+	loan.Renewals = []models.LoanRenewal{}
+	for _, renewal := range r.s.data.renewals {
+		if renewal.LoanID == loan.ID {
+			loan.Renewals = append(loan.Renewals, renewal)
+		}
+	}
+	return loan
+}
+
+func (r memoryLoans) FindByID(id uint) (*models.Loan, error) {
+	defer r.s.lock()()
+	loan, ok := r.s.data.loans[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	loan = r.withRenewals(loan)
+	return &loan, nil
+}
+
+func (r memoryLoans) ListByUser(userID uint) ([]models.Loan, error) {
+	defer r.s.lock()()
+	loans := sorted(r.s.data.loans, func(l models.Loan) bool { return l.UserID == userID })
+	for i := range loans {
+		loans[i] = r.withRenewals(loans[i])
+	}
+	return loans, nil
+}
+
+func (r memoryLoans) ListDue(now time.Time) ([]models.Loan, error) {
+	defer r.s.lock()()
+	return sorted(r.s.data.loans, func(l models.Loan) bool {
+		return l.Status == models.LoanActive && l.ReturnDate.Before(now)
+	}), nil
+}
+
+func (r memoryLoans) ListOverdue() ([]models.Loan, error) {
+	defer r.s.lock()()
+	return sorted(r.s.data.loans, func(l models.Loan) bool { return l.Status == models.LoanOverdue }), nil
+}
+
+func (r memoryLoans) Create(loan *models.Loan) error {
+	defer r.s.lock()()
+	loan.ID = r.s.data.next("loans")
+	stored := *loan
+	stored.Renewals = nil
+	r.s.data.loans[loan.ID] = stored
+	return nil
+}
+
+func (r memoryLoans) CountOpen(userID uint, resourceType, borrowType string) (int, error) {
+	defer r.s.lock()()
+	return len(sorted(r.s.data.loans, func(l models.Loan) bool {
+		return l.UserID == userID && l.BorrowType == borrowType &&
+			slices.Contains(models.OpenLoanStatuses, l.Status) &&
+			r.s.data.resources[l.ResourceID].Type == resourceType
+	})), nil
+}
+
+func (r memoryLoans) CountOpenByResource(resourceID, userID uint) (int, error) {
+	defer r.s.lock()()
+	return len(sorted(r.s.data.loans, func(l models.Loan) bool {
+		return l.ResourceID == resourceID && (userID == 0 || l.UserID == userID) &&
+			slices.Contains(models.OpenLoanStatuses, l.Status)
+	})), nil
+}
+
+func (r memoryLoans) SetStatus(id uint, from, to models.LoanStatus) error {
+	defer r.s.lock()()
+	loan, ok := r.s.data.loans[id]
+	if !ok || loan.Status != from {
+		return service.ErrStale
+	}
+	loan.Status = to
+	r.s.data.loans[id] = loan
+	return nil
+}
+
+func (r memoryLoans) Renew(loan *models.Loan, renewal models.LoanRenewal) error {
+	defer r.s.lock()()
+	current, ok := r.s.data.loans[loan.ID]
+	if !ok || current.Status != loan.Status || current.RenewalCount != loan.RenewalCount {
+		return service.ErrStale
+	}
+	current.ReturnDate = renewal.NewReturnDate
+	current.RenewalCount++
+	r.s.data.loans[loan.ID] = current
+	renewal.ID = r.s.data.next("loan_renewals")
+	r.s.data.renewals = append(r.s.data.renewals, renewal)
+	return nil
+}
+
+func (r memoryLoans) Export(f service.LoanExportFilter, fn func(service.LoanExport) error) error {
+	defer r.s.lock()()
+	loans := sorted(r.s.data.loans, func(l models.Loan) bool {
+		return (f.From.IsZero() || !l.LoanDate.Before(f.From)) && (f.To.IsZero() || l.LoanDate.Before(f.To)) &&
+			(f.Status == "" || l.Status == f.Status) && (f.UserID == 0 || l.UserID == f.UserID) &&
+			(f.Type == "" || r.s.data.resources[l.ResourceID].Type == f.Type)
+	})
+	slices.SortStableFunc(loans, func(a, b models.Loan) int { return a.LoanDate.Compare(b.LoanDate) })
+	for _, loan := range loans {
+		user, resource, copy := r.s.data.users[loan.UserID], r.s.data.resources[loan.ResourceID], r.s.data.copies[loan.CopyID]
+		err := fn(service.LoanExport{
+			ID: loan.ID, LoanDate: loan.LoanDate, ReturnDate: loan.ReturnDate, Status: string(loan.Status),
+			BorrowType: loan.BorrowType, RenewalCount: loan.RenewalCount,
+			UserID: loan.UserID, UserName: user.Name, UserEmail: user.Email,
+			ResourceID: loan.ResourceID, ResourceTitle: resource.Title, ResourceType: resource.Type,
+			CopyBarcode: copy.Barcode,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memoryFines struct {
+	s *MemoryStore
+}
+
+// sum retourne la somme des écritures retenues par keep.
+func (r memoryFines) sum(keep func(models.FineEntry) bool) int {
+	total := 0
+	for _, entry := range r.s.data.fines {
+		if keep(entry) {
+			total += entry.Amount
+		}
+	}
+	return total
+}
+
+func (r memoryFines) ListByUser(userID uint) ([]models.FineEntry, error) {
+	defer r.s.lock()()
+	entries := []models.FineEntry{}
+	for _, entry := range r.s.data.fines {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b models.FineEntry) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return entries, nil
+}
+
+func (r memoryFines) Balance(userID uint) (int, error) {
+	defer r.s.lock()()
+	return r.sum(func(e models.FineEntry) bool { return e.UserID == userID }), nil
+}
+
+func (r memoryFines) Charge(loan *models.Loan, due int, now time.Time) (int, error) {
+	defer r.s.lock()()
+	charged := r.sum(func(e models.FineEntry) bool {
+		return e.LoanID != nil && *e.LoanID == loan.ID && e.Kind == models.FineCharge
+	})
+	if due <= charged {
+		return 0, nil
+	}
+	loanID := loan.ID
+	r.s.data.fines = append(r.s.data.fines, models.FineEntry{
+		ID:        r.s.data.next("fine_entries"),
+		UserID:    loan.UserID,
+		LoanID:    &loanID,
+		Kind:      models.FineCharge,
+		Amount:    due - charged,
+		CreatedAt: now,
+	})
+	return due - charged, nil
+}
+
+func (r memoryFines) Create(entry *models.FineEntry) error {
+	defer r.s.lock()()
+	entry.ID = r.s.data.next("fine_entries")
+	r.s.data.fines = append(r.s.data.fines, *entry)
+	return nil
+}
+
+type memoryPolicies struct {
+	s *MemoryStore
+}
+
+func (r memoryPolicies) FindByID(id uint) (*models.LoanPolicy, error) {
+	defer r.s.lock()()
+	policy, ok := r.s.data.policies[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	return &policy, nil
+}
+
+func (r memoryPolicies) Find(resourceType, borrowType string) (*models.LoanPolicy, error) {
+	defer r.s.lock()()
+	for _, policy := range r.s.data.policies {
+		if policy.ResourceType == resourceType && policy.BorrowType == borrowType {
+			return &policy, nil
+		}
+	}
+	return nil, service.ErrNotFound
+}
+
+func (r memoryPolicies) List() ([]models.LoanPolicy, error) {
+	defer r.s.lock()()
+	policies := sorted(r.s.data.policies, func(models.LoanPolicy) bool { return true })
+	slices.SortStableFunc(policies, func(a, b models.LoanPolicy) int {
+		return cmp.Or(cmp.Compare(a.ResourceType, b.ResourceType), cmp.Compare(a.BorrowType, b.BorrowType))
+	})
+	return policies, nil
+}
+
+func (r memoryPolicies) Exists(resourceType, borrowType string, exceptID uint) (bool, error) {
+	defer r.s.lock()()
+	for _, policy := range r.s.data.policies {
+		if policy.ResourceType == resourceType && policy.BorrowType == borrowType && policy.ID != exceptID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r memoryPolicies) Save(policy *models.LoanPolicy) error {
+	defer r.s.lock()()
+	if policy.ID == 0 {
+		policy.ID = r.s.data.next("loan_policies")
+	}
+	r.s.data.policies[policy.ID] = *policy
+	return nil
+}
+
+func (r memoryPolicies) Delete(id uint) error {
+	defer r.s.lock()()
+	if _, ok := r.s.data.policies[id]; !ok {
+		return service.ErrNotFound
+	}
+	delete(r.s.data.policies, id)
+	return nil
+}
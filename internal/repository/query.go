@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/service"
+	"gorm.io/gorm"
+)
+
+// likeEscaper échappe les caractères spéciaux d'un motif LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterResources applique les critères de recherche à la requête.
+func filterResources(db *gorm.DB, q service.ResourceQuery) *gorm.DB {
+	for _, word := range strings.Fields(q.Search) {
+		db = db.Where(`LOWER(title) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(strings.ToLower(word))+"%")
+	}
+	if q.Type != "" {
+		db = db.Where("type = ?", q.Type)
+	}
+	if q.Status != "" {
+		db = db.Where("status = ?", q.Status)
+	}
+
+	book := db.Session(&gorm.Session{NewDB: true}).Model(&models.BookDetails{}).Select("resource_id")
+	filterBooks := false
+	if q.Author != "" {
+		// Les auteurs sont stockés en liste JSON : la recherche porte sur une partie d'un nom
+		book = book.Where(`LOWER(authors) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(strings.ToLower(q.Author))+"%")
+		filterBooks = true
+	}
+	if q.Language != "" {
+		book = book.Where("language = ?", q.Language)
+		filterBooks = true
+	}
+	if q.Genre != "" {
+		// Correspondance avec un élément entier de la liste JSON des genres
+		genre, _ := json.Marshal(strings.ToLower(q.Genre))
+		book = book.Where(`LOWER(genres) LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(string(genre))+"%")
+		filterBooks = true
+	}
+	if q.Year != 0 {
+		book = book.Where("year = ?", q.Year)
+		filterBooks = true
+	}
+	if filterBooks {
+		db = db.Where("id IN (?)", book)
+	}
+
+	game := db.Session(&gorm.Session{NewDB: true}).Model(&models.GameDetails{}).Select("resource_id")
+	filterGames := false
+	if q.Players != 0 {
+		game = game.Where("min_players <= ? AND (max_players = 0 OR max_players >= ?)", q.Players, q.Players)
+		filterGames = true
+	}
+	if q.MaxPlayTime != 0 {
+		game = game.Where("play_time > 0 AND play_time <= ?", q.MaxPlayTime)
+		filterGames = true
+	}
+	if q.Age != 0 {
+		game = game.Where("min_age <= ?", q.Age)
+		filterGames = true
+	}
+	if filterGames {
+		db = db.Where("id IN (?)", game)
+	}
+	return db
+}
+
+func (r gormResources) Search(q service.ResourceQuery) ([]models.Resource, int64, error) {
+	var total int64
+	if err := filterResources(r.db.Model(&models.Resource{}), q).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := service.ResourceSortFields[q.Sort]
+	if !ok {
+		column = "title"
+	}
+	direction := " ASC"
+	if q.Descending {
+		direction = " DESC"
+	}
+	order := column + direction
+	if column != "id" {
+		order += ", id" + direction
+	}
+
+	resources := []models.Resource{}
+	err := filterResources(r.db, q).
+		Preload("Book").
+		Preload("Game").
+		Order(order).
+		Limit(q.PerPage).
+		Offset((q.Page - 1) * q.PerPage).
+		Find(&resources).Error
+	return resources, total, err
+}
+
+// searchWords découpe la saisie d'un adhérent en mots. La ponctuation est ignorée,
+// de sorte que la syntaxe de recherche du moteur ne peut pas être injectée.
+func searchWords(input string) []string {
+	return strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// ftsQuery traduit la saisie d'un adhérent en requête FTS5 : chaque mot est requis
+// et peut être le début d'un mot du titre (« seign anneaux » trouve « Le Seigneur des Anneaux »).
+func ftsQuery(input string) string {
+	words := searchWords(input)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// tsQuery traduit la saisie en requête PostgreSQL selon les mêmes règles (« seign:* & anneaux:* »).
+func tsQuery(input string) string {
+	words := searchWords(input)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// searchRows retourne la requête des correspondances, filtrée par type de ressource si resourceType n'est pas vide.
+// Sous SQLite, elle interroge la table FTS5 resources_fts ; sous PostgreSQL, l'index GIN des titres
+// via la configuration resources_search, la requête analysée étant nommée "query".
+func searchRows(db *gorm.DB, match, resourceType string) *gorm.DB {
+	if isPostgres(db) {
+		db = db.Table("resources, to_tsquery('resources_search', ?) AS query", match).
+			Where("to_tsvector('resources_search', resources.title) @@ query")
+	} else {
+		db = db.Table("resources_fts").
+			Joins("JOIN resources ON resources.id = resources_fts.rowid").
+			Where("resources_fts MATCH ?", match)
+	}
+	db = db.Where("resources.deleted_at IS NULL")
+	if resourceType != "" {
+		db = db.Where("resources.type = ?", resourceType)
+	}
+	return db
+}
+
+// rankedRows sélectionne l'identifiant, l'extrait et la pertinence des correspondances, les meilleures en premier.
+func rankedRows(db *gorm.DB) *gorm.DB {
+	if isPostgres(db) {
+		return db.Select("resources.id AS id, ts_headline('resources_search', resources.title, query, ?) AS snippet, "+
+			"ts_rank(to_tsvector('resources_search', resources.title), query) AS score",
+			"StartSel="+service.MarkStart+", StopSel="+service.MarkEnd+", MaxWords=12, MinWords=3").
+			Order("score DESC, resources.id")
+	}
+	return db.Select("resources.id AS id, snippet(resources_fts, -1, ?, ?, '…', 12) AS snippet, -bm25(resources_fts) AS score",
+		service.MarkStart, service.MarkEnd).
+		Order("bm25(resources_fts), resources.id")
+}
+
+func (r gormResources) FullText(input, resourceType string, page, perPage int) ([]service.TextMatch, int64, error) {
+	match := ftsQuery(input)
+	if isPostgres(r.db) {
+		match = tsQuery(input)
+	}
+	if match == "" {
+		return nil, 0, nil
+	}
+
+	var total int64
+	if err := searchRows(r.db, match, resourceType).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var matches []service.TextMatch
+	err := rankedRows(searchRows(r.db, match, resourceType)).
+		Limit(perPage).
+		Offset((page - 1) * perPage).
+		Scan(&matches).Error
+	return matches, total, err
+}
+
+// scanRows lit les lignes de la requête une à une dans un T et les passe à fn, sans charger le résultat en mémoire.
+func scanRows[T any](db, query *gorm.DB, fn func(T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r gormResources) Export(f service.ResourceExportFilter, fn func(service.ResourceExport) error) error {
+	query := r.db.Model(&models.Resource{}).
+		Select(`resources.id, resources.title, resources.type, resources.status,
+			(SELECT COUNT(*) FROM copies WHERE copies.resource_id = resources.id) AS total_copies,
+			(SELECT COUNT(*) FROM copies WHERE copies.resource_id = resources.id AND copies.status = ?) AS available_copies,
+			COALESCE(book_details.authors, 'null') AS authors, COALESCE(book_details.publisher, '') AS publisher,
+			COALESCE(book_details.year, 0) AS year, COALESCE(book_details.isbn, '') AS isbn,
+			COALESCE(book_details.language, '') AS language, COALESCE(book_details.genres, 'null') AS genres,
+			COALESCE(book_details.cover_url, '') AS cover_url, COALESCE(game_details.designer, '') AS designer,
+			COALESCE(game_details.min_players, 0) AS min_players, COALESCE(game_details.max_players, 0) AS max_players,
+			COALESCE(game_details.play_time, 0) AS play_time, COALESCE(game_details.min_age, 0) AS min_age,
+			COALESCE(game_details.complexity, 0) AS complexity`, models.ResourceAvailable).
+		Joins("LEFT JOIN book_details ON book_details.resource_id = resources.id").
+		Joins("LEFT JOIN game_details ON game_details.resource_id = resources.id")
+	if f.Type != "" {
+		query = query.Where("resources.type = ?", f.Type)
+	}
+	if f.Status != "" {
+		query = query.Where("resources.status = ?", f.Status)
+	}
+	return scanRows(r.db, query.Order("resources.id"), fn)
+}
+
+func (r gormLoans) Export(f service.LoanExportFilter, fn func(service.LoanExport) error) error {
+	query := r.db.Model(&models.Loan{}).
+		Select(`loans.id, loans.loan_date, loans.return_date, loans.status, loans.borrow_type, loans.renewal_count,
+			loans.user_id, COALESCE(users.name, '') AS user_name, COALESCE(users.email, '') AS user_email,
+			loans.resource_id, COALESCE(resources.title, '') AS resource_title, COALESCE(resources.type, '') AS resource_type,
+			COALESCE(copies.barcode, '') AS copy_barcode`).
+		Joins("LEFT JOIN users ON users.id = loans.user_id").
+		Joins("LEFT JOIN resources ON resources.id = loans.resource_id").
+		Joins("LEFT JOIN copies ON copies.id = loans.copy_id")
+	if !f.From.IsZero() {
+		query = query.Where("loans.loan_date >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		query = query.Where("loans.loan_date < ?", f.To)
+	}
+	if f.Status != "" {
+		query = query.Where("loans.status = ?", f.Status)
+	}
+	if f.Type != "" {
+		query = query.Where("resources.type = ?", f.Type)
+	}
+	if f.UserID != 0 {
+		query = query.Where("loans.user_id = ?", f.UserID)
+	}
+	return scanRows(r.db, query.Order("loans.loan_date, loans.id"), fn)
+}
@@ -1,48 +1,141 @@
 package routes
 
 import (
+	"strings"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
 	"awesomeProject/internal/handlers"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/middleware"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"time"
+	"gorm.io/gorm"
 )
 
-func SetupRouter() *gin.Engine {
-	router := gin.Default()
+// SetupRouter construit le routeur HTTP à partir de la configuration de l'application, sur la base fournie,
+// sans journal.
+func SetupRouter(cfg *config.Config, db *gorm.DB) *gin.Engine {
+	return NewRouter(cfg, db, clock.Real(), logging.Discard())
+}
+
+// NewRouter construit le routeur HTTP sur la base fournie, avec l'horloge et le journal fournis
+// (les tests injectent une base isolée, une horloge contrôlée et un journal qu'ils peuvent lire).
+func NewRouter(cfg *config.Config, db *gorm.DB, clk clock.Clock, logger *logging.Logger) *gin.Engine {
+	// Chaque requête reçoit un identifiant, un journal et une langue ; les erreurs signalées par les handlers
+	// et les paniques sont traduites en enveloppe JSON commune par middleware.Errors.
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.AccessLog(logger), middleware.Locale(), middleware.Errors(), middleware.Recovery())
+	authHandler := handlers.NewAuthHandler(cfg.Auth, db)
+	userHandler := handlers.NewUserHandler(db)
+	catalogHandler := handlers.NewCatalogHandler(db)
+	loanHandler := handlers.NewLoanHandler(db, cfg.Loans, clk)
 
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173"}, // Autorise le frontend en dev
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowOrigins:     cfg.CORS.AllowOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Link", "X-Total-Count", middleware.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	api := router.Group("/api")
 	{
-		// Routes d'authentification et gestion d'utilisateur
-		api.POST("/register", handlers.RegisterUser)
-		api.POST("/login", handlers.LoginUser)
-		api.GET("/profile", handlers.GetProfile)
-		api.PUT("/profile", handlers.UpdateProfile)
+		// Routes d'authentification (publiques)
+		api.POST("/register", authHandler.RegisterUser)
+		api.POST("/login", authHandler.LoginUser)
+		api.POST("/token/refresh", authHandler.RefreshAccessToken)
 
-		// Routes de gestion des ressources (livres et jeux)
-		//fakedata http://localhost:8080/api/resources
-		api.GET("/resources", handlers.GetResources)
-		api.GET("/resources/:id", handlers.GetResource)
-		api.POST("/resources", handlers.CreateResource)
-		api.PUT("/resources/:id/disable", handlers.DisableResource)  // Passer en indisponible
-		api.PUT("/resources/:id/enable", handlers.EnableResource)    // Passer en disponible
-		//fakedata http://localhost:8080/api/resources/fill
-		api.GET("/resources/fill", handlers.FillWithFakeData)
+		// Consultation du catalogue (publique)
+		api.GET("/resources", catalogHandler.GetResources)
+		api.GET("/resources/:id", catalogHandler.GetResource)
+		api.GET("/resources/:id/copies", catalogHandler.GetCopies)
+		api.GET("/search", catalogHandler.Search) // Recherche plein texte
+	}
+
+	// Routes protégées : un token JWT valide est requis
+	protected := api.Group("")
+	sessions := service.NewSessionService(repository.NewGormStore(db), cfg.Auth)
+	protected.Use(middleware.AuthRequired([]byte(cfg.Auth.JWTSecret), sessions))
+	{
+		// Déconnexion (révocation du token courant)
+		protected.POST("/logout", authHandler.Logout)
+
+		// Gestion du profil utilisateur
+		protected.GET("/profile", userHandler.GetProfile)
+		protected.PUT("/profile", userHandler.UpdateProfile)
 
 		// Routes de gestion des prêts
-		api.POST("/loans", handlers.CreateLoan)
-		api.GET("/loans", handlers.GetLoans)
-		api.PUT("/loans/:id/return", handlers.ReturnLoan)
+		protected.POST("/loans", loanHandler.CreateLoan)
+		protected.GET("/loans", loanHandler.GetLoans)
+		protected.PUT("/loans/:id/return", loanHandler.ReturnLoan)
+		protected.PUT("/loans/:id/renew", loanHandler.RenewLoan)
 		// Optionnel : Suppression d'un prêt en attente
-		// api.DELETE("/loans/:id", handlers.DeleteLoan)
+		// protected.DELETE("/loans/:id", handlers.DeleteLoan)
+
+		// Réservations des ressources indisponibles
+		protected.POST("/holds", loanHandler.PlaceHold)
+		protected.GET("/holds", loanHandler.GetHolds)
+		protected.DELETE("/holds/:id", loanHandler.CancelHold)
+
+		// Compte d'amendes de l'utilisateur connecté
+		protected.GET("/fines", loanHandler.GetFines)
+	}
+
+	// Routes réservées au personnel (bibliothécaires et administrateurs)
+	staff := protected.Group("")
+	staff.Use(middleware.RequireRole(models.RoleLibrarian, models.RoleAdmin))
+	{
+		// Routes de gestion des ressources (livres et jeux)
+		staff.POST("/resources", catalogHandler.CreateResource)
+		staff.POST("/resources/import", catalogHandler.ImportResources) // Import CSV ou JSON, ?dry_run=true&atomic=true
+		staff.PUT("/resources/:id", catalogHandler.UpdateResource)
+		staff.PATCH("/resources/:id", catalogHandler.PatchResource)
+		staff.DELETE("/resources/:id", catalogHandler.DeleteResource)       // Suppression logique
+		staff.PUT("/resources/:id/disable", catalogHandler.DisableResource) // Passer en indisponible
+		staff.PUT("/resources/:id/enable", catalogHandler.EnableResource)   // Passer en disponible
+		staff.POST("/resources/:id/copies", catalogHandler.CreateCopy)
+		staff.PUT("/copies/:id", catalogHandler.UpdateCopy)
+		staff.PUT("/copies/:id/disable", catalogHandler.DisableCopy)
+		staff.PUT("/copies/:id/enable", catalogHandler.EnableCopy)
+
+		// Exports pour les rapports : CSV (par défaut) ou JSON, envoyés ligne par ligne
+		staff.GET("/export/resources", catalogHandler.ExportResources)
+		staff.GET("/export/loans", catalogHandler.ExportLoans)
+
+		// Amendes : paiements et remises saisis au guichet
+		staff.POST("/users/:id/fines/payments", loanHandler.RecordFinePayment)
+		staff.POST("/users/:id/fines/waive", loanHandler.WaiveFines)
+	}
+
+	// Routes réservées aux administrateurs
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/users", userHandler.ListUsers)
+		admin.PUT("/users/:id/role", userHandler.UpdateUserRole)             // Promouvoir ou rétrograder
+		admin.POST("/users/:id/revoke-tokens", userHandler.RevokeUserTokens) // Révoquer toutes les sessions
+
+		// Ressources supprimées
+		admin.GET("/resources/deleted", catalogHandler.GetDeletedResources)
+		admin.PUT("/resources/:id/restore", catalogHandler.RestoreResource)
+
+		// Politiques de prêt par type de ressource et type d'emprunt
+		admin.GET("/loan-policies", loanHandler.GetLoanPolicies)
+		admin.POST("/loan-policies", loanHandler.CreateLoanPolicy)
+		admin.PUT("/loan-policies/:id", loanHandler.UpdateLoanPolicy)
+		admin.DELETE("/loan-policies/:id", loanHandler.DeleteLoanPolicy)
+
+		// Jeu de données de développement (voir aussi la commande "seed"), jamais exposé en production
+		if !cfg.IsProduction() {
+			admin.POST("/seed", handlers.NewSeedHandler(db, cfg.Loans, clk).Seed)
+		}
 	}
 
 	// Déclaration du dossier des assets
@@ -50,7 +143,12 @@ func SetupRouter() *gin.Engine {
 	//router.Static("/assets", "./assets")
 	router.Static("/static", "./awsome_front/dist")
 
+	// Une route d'API inconnue répond 404 en JSON ; les autres chemins servent l'application Vue
 	router.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			c.Error(apierror.NotFound(apierror.CodeRouteNotFound, "Route inconnue"))
+			return
+		}
 		c.File("./awsome_front/dist/index.html")
 	})
 
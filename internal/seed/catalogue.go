@@ -0,0 +1,121 @@
+package seed
+
+import "awesomeProject/internal/models"
+
+// book décrit un livre du jeu de données.
+func book(title, author string, year int, publisher string, genres ...string) models.Resource {
+	return models.Resource{
+		Title:  title,
+		Type:   models.ResourceBook,
+		Status: models.ResourceAvailable,
+		Book: &models.BookDetails{
+			Authors:   []string{author},
+			Publisher: publisher,
+			Year:      year,
+			Language:  "fr",
+			Genres:    genres,
+		},
+	}
+}
+
+// game décrit un jeu de société du jeu de données.
+func game(title, designer string, minPlayers, maxPlayers, playTime, minAge int, complexity float64) models.Resource {
+	return models.Resource{
+		Title:  title,
+		Type:   models.ResourceGame,
+		Status: models.ResourceAvailable,
+		Game: &models.GameDetails{
+			Designer:   designer,
+			MinPlayers: minPlayers,
+			MaxPlayers: maxPlayers,
+			PlayTime:   playTime,
+			MinAge:     minAge,
+			Complexity: complexity,
+		},
+	}
+}
+
+// unavailable retire le titre du prêt, comme un exemplaire en réparation ou en commande.
+func unavailable(resource models.Resource) models.Resource {
+	resource.Status = models.ResourceUnavailable
+	return resource
+}
+
+// catalogue est le fonds de la bibliothèque créé par le jeu de données.
+var catalogue = []models.Resource{
+	// Livres
+	book("1984", "George Orwell", 1949, "Gallimard", "Science-fiction", "Dystopie"),
+	book("Le Petit Prince", "Antoine de Saint-Exupéry", 1943, "Gallimard", "Conte"),
+	book("Harry Potter à l'école des sorciers", "J. K. Rowling", 1997, "Gallimard Jeunesse", "Fantasy", "Jeunesse"),
+	book("Les Misérables", "Victor Hugo", 1862, "Le Livre de Poche", "Roman historique"),
+	book("L'Étranger", "Albert Camus", 1942, "Gallimard", "Roman"),
+	book("Don Quichotte", "Miguel de Cervantès", 1605, "Points", "Roman", "Classique"),
+	book("Moby Dick", "Herman Melville", 1851, "Folio", "Aventure"),
+	book("Crime et Châtiment", "Fiodor Dostoïevski", 1866, "Actes Sud", "Roman"),
+	book("Gatsby le Magnifique", "F. Scott Fitzgerald", 1925, "Folio", "Roman"),
+	book("Orgueil et Préjugés", "Jane Austen", 1813, "10/18", "Roman", "Romance"),
+	book("Le Comte de Monte-Cristo", "Alexandre Dumas", 1844, "Le Livre de Poche", "Aventure"),
+	book("La Peste", "Albert Camus", 1947, "Gallimard", "Roman"),
+	book("Les Fleurs du mal", "Charles Baudelaire", 1857, "Le Livre de Poche", "Poésie"),
+	book("Le Rouge et le Noir", "Stendhal", 1830, "Folio", "Roman"),
+	book("Voyage au centre de la Terre", "Jules Verne", 1864, "Hetzel", "Aventure", "Science-fiction"),
+	book("Vingt mille lieues sous les mers", "Jules Verne", 1870, "Hetzel", "Aventure", "Science-fiction"),
+	book("La Métamorphose", "Franz Kafka", 1915, "Folio", "Nouvelle"),
+	book("Les Trois Mousquetaires", "Alexandre Dumas", 1844, "Le Livre de Poche", "Aventure", "Roman historique"),
+	book("Le Seigneur des Anneaux", "J. R. R. Tolkien", 1954, "Christian Bourgois", "Fantasy"),
+	book("Hunger Games", "Suzanne Collins", 2008, "Pocket Jeunesse", "Science-fiction", "Jeunesse"),
+	book("Dune", "Frank Herbert", 1965, "Robert Laffont", "Science-fiction"),
+	book("Sherlock Holmes : Une étude en rouge", "Arthur Conan Doyle", 1887, "Le Livre de Poche", "Policier"),
+	unavailable(book("L'Île mystérieuse", "Jules Verne", 1875, "Hetzel", "Aventure")),
+	book("Frankenstein", "Mary Shelley", 1818, "Folio", "Fantastique"),
+	unavailable(book("Dracula", "Bram Stoker", 1897, "J'ai lu", "Fantastique")),
+	book("Le Parfum", "Patrick Süskind", 1985, "Le Livre de Poche", "Roman"),
+	book("Le Nom de la Rose", "Umberto Eco", 1980, "Le Livre de Poche", "Policier", "Roman historique"),
+	book("La Nuit des temps", "René Barjavel", 1968, "Pocket", "Science-fiction"),
+	unavailable(book("L'Alchimiste", "Paulo Coelho", 1988, "J'ai lu", "Conte")),
+	book("Les Hauts de Hurlevent", "Emily Brontë", 1847, "Folio", "Roman"),
+
+	// Jeux de plateau
+	unavailable(game("Catan", "Klaus Teuber", 3, 4, 75, 10, 2.3)),
+	game("Risk", "Albert Lamorisse", 2, 6, 120, 10, 2.1),
+	game("Carcassonne", "Klaus-Jürgen Wrede", 2, 5, 35, 7, 1.9),
+	game("Les Aventuriers du Rail", "Alan R. Moon", 2, 5, 45, 8, 1.8),
+	game("Splendor", "Marc André", 2, 4, 30, 10, 1.8),
+	game("Dixit", "Jean-Louis Roubira", 3, 6, 30, 8, 1.2),
+	game("7 Wonders", "Antoine Bauza", 2, 7, 30, 10, 2.3),
+	unavailable(game("Terraforming Mars", "Jacob Fryxelius", 1, 5, 120, 12, 3.3)),
+	game("Azul", "Michael Kiesling", 2, 4, 45, 8, 1.8),
+	unavailable(game("Pandemic", "Matt Leacock", 2, 4, 45, 8, 2.4)),
+	game("Kingdomino", "Bruno Cathala", 2, 4, 15, 8, 1.2),
+	game("Codenames", "Vlaada Chvátil", 2, 8, 15, 14, 1.3),
+	game("Small World", "Philippe Keyaerts", 2, 5, 80, 8, 2.4),
+	game("Scythe", "Jamey Stegmaier", 1, 5, 115, 14, 3.4),
+	game("Agricola", "Uwe Rosenberg", 1, 4, 120, 12, 3.6),
+	unavailable(game("Everdell", "James A. Wilson", 1, 4, 80, 13, 2.8)),
+	unavailable(game("Root", "Cole Wehrle", 2, 4, 90, 10, 3.8)),
+	game("Wingspan", "Elizabeth Hargrave", 1, 5, 70, 10, 2.5),
+	unavailable(game("Architectes du Royaume de l'Ouest", "Shem Phillips", 1, 5, 80, 12, 2.8)),
+	game("Brass: Birmingham", "Martin Wallace", 2, 4, 120, 14, 3.9),
+	game("Spirit Island", "R. Eric Reuss", 1, 4, 120, 13, 4.0),
+	unavailable(game("Gloomhaven", "Isaac Childres", 1, 4, 120, 14, 3.9)),
+	unavailable(game("Clank!", "Paul Dennen", 2, 4, 60, 12, 2.2)),
+	game("Paladins du Royaume de l'Ouest", "Shem Phillips", 1, 4, 90, 12, 3.7),
+	game("The Crew", "Thomas Sing", 2, 5, 20, 10, 2.0),
+	unavailable(game("The Mind", "Wolfgang Warsch", 2, 4, 20, 8, 1.1)),
+	game("Tapestry", "Jamey Stegmaier", 1, 5, 120, 12, 2.9),
+	unavailable(game("Anachrony", "Viktor Péter", 1, 4, 120, 14, 4.0)),
+	unavailable(game("Project Gaia", "Jens Drögemüller", 1, 4, 150, 14, 4.4)),
+	game("Barrage", "Simone Luciani", 1, 4, 120, 14, 4.0),
+}
+
+// Prénoms et noms des adhérents générés
+var (
+	firstNames = []string{
+		"Camille", "Léa", "Hugo", "Louis", "Chloé", "Emma", "Lucas", "Manon", "Jules", "Inès",
+		"Nathan", "Sarah", "Théo", "Zoé", "Arthur", "Jade", "Paul", "Alice", "Gabriel", "Louise",
+	}
+	lastNames = []string{
+		"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau",
+		"Simon", "Laurent", "Lefèvre", "Michel", "Garcia", "David", "Bertrand", "Roux", "Vincent", "Fournier",
+	}
+)
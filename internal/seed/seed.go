@@ -0,0 +1,328 @@
+// Package seed remplit la base avec un jeu de données réaliste et reproductible : comptes du personnel
+// et adhérents, catalogue avec métadonnées, historique de prêts avec des prêts en cours et en retard.
+// La même graine et la même date de référence produisent le même jeu de données.
+package seed
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Password est le mot de passe de tous les comptes créés par le jeu de données.
+const Password = "motdepasse"
+
+// Comptes du personnel créés par le jeu de données
+const (
+	AdminEmail     = "admin@bibliotheque.test"
+	LibrarianEmail = "bibliothecaire@bibliotheque.test"
+)
+
+// DefaultMembers est le nombre d'adhérents générés par défaut.
+const DefaultMembers = 20
+
+// maxOpenLoans est le nombre maximal de prêts ouverts attribués à un même adhérent.
+const maxOpenLoans = 3
+
+// Options paramètre le jeu de données.
+type Options struct {
+	Seed    int64             // Graine du générateur : même graine, même jeu de données
+	Now     time.Time         // Date de référence : l'historique des prêts la précède
+	Members int               // Nombre d'adhérents générés
+	Loans   config.LoanConfig // Règles de prêt utilisées sans politique enregistrée
+}
+
+// Summary compte les éléments créés par le jeu de données.
+type Summary struct {
+	Users     int `json:"users"`
+	Resources int `json:"resources"`
+	Loans     int `json:"loans"`
+	Overdue   int `json:"overdue"` // Prêts en retard, parmi les prêts créés
+}
+
+// generator crée le jeu de données dans une transaction.
+type generator struct {
+	tx       *gorm.DB
+	store    service.Store
+	catalog  *service.CatalogService
+	loanSvc  *service.LoanService // Règles de prêt à la date de référence
+	rng      *rand.Rand
+	opts     Options
+	password string // Empreinte bcrypt de Password
+	policies map[string]models.LoanPolicy
+	summary  Summary
+}
+
+// Run crée le jeu de données dans une seule transaction. Les comptes (même email) et les titres
+// (même titre et même type) déjà présents ne sont pas recréés ; les prêts ne sont générés que pour
+// les titres créés, de sorte qu'une seconde exécution ne modifie rien.
+func Run(db *gorm.DB, opts Options) (Summary, error) {
+	if opts.Members <= 0 {
+		opts.Members = DefaultMembers
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.DefaultCost)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	g := &generator{
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		opts:     opts,
+		password: string(hash),
+		policies: map[string]models.LoanPolicy{},
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		g.tx = tx
+		g.store = repository.NewGormStore(tx)
+		g.catalog = service.NewCatalogService(g.store)
+		g.loanSvc = service.NewLoanService(g.store, opts.Loans, clock.NewFake(opts.Now))
+		members, err := g.users()
+		if err != nil {
+			return err
+		}
+		resources, err := g.resources()
+		if err != nil {
+			return err
+		}
+		return g.loans(members, resources)
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+	return g.summary, nil
+}
+
+// user retourne le compte de l'email donné, en le créant s'il n'existe pas.
+func (g *generator) user(name, email, role string) (models.User, error) {
+	var user models.User
+	err := g.tx.Where("email = ?", email).First(&user).Error
+	if err == nil || err != gorm.ErrRecordNotFound {
+		return user, err
+	}
+	user = models.User{Name: name, Email: email, Password: g.password, Role: role}
+	if err := g.tx.Create(&user).Error; err != nil {
+		return user, err
+	}
+	g.summary.Users++
+	return user, nil
+}
+
+// emailSlug transcrit un prénom ou un nom pour une adresse email.
+var emailSlug = strings.NewReplacer("é", "e", "è", "e", "ë", "e", "ê", "e", "ï", "i", "ô", "o", "ç", "c", " ", "-")
+
+// users crée les comptes du personnel et retourne les adhérents.
+func (g *generator) users() ([]models.User, error) {
+	if _, err := g.user("Administrateur", AdminEmail, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if _, err := g.user("Bibliothécaire", LibrarianEmail, models.RoleLibrarian); err != nil {
+		return nil, err
+	}
+
+	members := make([]models.User, 0, g.opts.Members)
+	seen := map[string]int{}
+	for range g.opts.Members {
+		first := firstNames[g.rng.Intn(len(firstNames))]
+		last := lastNames[g.rng.Intn(len(lastNames))]
+		local := strings.ToLower(emailSlug.Replace(first + "." + last))
+		// Homonymes : camille.martin, camille.martin2, ...
+		seen[local]++
+		if seen[local] > 1 {
+			local += strconv.Itoa(seen[local])
+		}
+		member, err := g.user(first+" "+last, local+"@bibliotheque.test", models.RoleMember)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// conditions pondère l'état physique des exemplaires générés : la plupart sont en bon état.
+var conditions = []string{
+	models.ConditionNew, models.ConditionGood, models.ConditionGood, models.ConditionGood,
+	models.ConditionWorn, models.ConditionWorn, models.ConditionDamaged,
+}
+
+// resources crée les titres du catalogue absents de la base, avec un à trois exemplaires.
+func (g *generator) resources() ([]models.Resource, error) {
+	var created []models.Resource
+	for _, item := range catalogue {
+		// Le catalogue est partagé : chaque exécution travaille sur sa propre copie des métadonnées
+		resource := item
+		if item.Book != nil {
+			details := *item.Book
+			resource.Book = &details
+		}
+		if item.Game != nil {
+			details := *item.Game
+			resource.Game = &details
+		}
+
+		copies := make([]models.Copy, 1+g.rng.Intn(3))
+		for i := range copies {
+			copies[i].Condition = conditions[g.rng.Intn(len(conditions))]
+		}
+
+		var existing int64
+		if err := g.tx.Model(&models.Resource{}).
+			Where("title = ? AND type = ?", resource.Title, resource.Type).
+			Count(&existing).Error; err != nil {
+			return nil, err
+		}
+		if existing > 0 {
+			continue
+		}
+
+		resource.Copies = copies
+		if err := g.catalog.Create(&resource); err != nil {
+			return nil, err
+		}
+		created = append(created, resource)
+		g.summary.Resources++
+	}
+	return created, nil
+}
+
+// policy retourne la politique de prêt applicable, lue une seule fois par type de ressource et d'emprunt.
+func (g *generator) policy(resourceType, borrowType string) (models.LoanPolicy, error) {
+	key := resourceType + "/" + borrowType
+	if policy, ok := g.policies[key]; ok {
+		return policy, nil
+	}
+	policy, err := g.loanSvc.Policy(resourceType, borrowType)
+	if err != nil {
+		return policy, err
+	}
+	g.policies[key] = policy
+	return policy, nil
+}
+
+// at retourne une heure d'ouverture de la bibliothèque, le nombre de jours donné avant la date de référence.
+func (g *generator) at(daysAgo int) time.Time {
+	year, month, day := g.opts.Now.AddDate(0, 0, -daysAgo).Date()
+	t := time.Date(year, month, day, 10+g.rng.Intn(8), g.rng.Intn(60), 0, 0, g.opts.Now.Location())
+	if t.After(g.opts.Now) {
+		return g.opts.Now
+	}
+	return t
+}
+
+// loans génère l'historique des exemplaires disponibles des titres créés : des prêts rendus sur les
+// derniers mois puis, pour certains exemplaires, un prêt en cours ou en retard, avec son amende.
+func (g *generator) loans(members []models.User, resources []models.Resource) error {
+	if len(members) == 0 {
+		return nil
+	}
+	open := map[uint]int{} // Prêts ouverts par adhérent
+
+	for _, resource := range resources {
+		takeAway, err := g.policy(resource.Type, models.BorrowTakeAway)
+		if err != nil {
+			return err
+		}
+		onSite, err := g.policy(resource.Type, models.BorrowOnSite)
+		if err != nil {
+			return err
+		}
+
+		for _, copy := range resource.Copies {
+			if copy.Status != models.ResourceAvailable {
+				continue
+			}
+
+			// Prêts rendus, les uns après les autres, avant la période des prêts ouverts
+			daysAgo := 180 + g.rng.Intn(30)
+			for daysAgo > takeAway.LoanDays*2+30 && g.rng.Intn(4) > 0 {
+				borrowType, policy := models.BorrowTakeAway, takeAway
+				if g.rng.Intn(5) == 0 || !takeAway.Allowed {
+					borrowType, policy = models.BorrowOnSite, onSite
+				}
+				if !policy.Allowed {
+					break
+				}
+				loanDate := g.at(daysAgo)
+				member := members[g.rng.Intn(len(members))]
+				if _, err := g.createLoan(member, resource, copy, borrowType, loanDate, policy.DueDate(loanDate), models.LoanReturned); err != nil {
+					return err
+				}
+				daysAgo -= policy.LoanDays + 1 + g.rng.Intn(20)
+			}
+
+			// Un exemplaire sur quatre est encore prêté, dont un sur trois en retard
+			if !takeAway.Allowed || g.rng.Intn(4) > 0 {
+				continue
+			}
+			member := members[g.rng.Intn(len(members))]
+			if open[member.ID] >= maxOpenLoans {
+				continue
+			}
+			overdue := g.rng.Intn(3) == 0
+			loanDate := g.at(g.rng.Intn(max(takeAway.LoanDays, 1)))
+			if overdue {
+				loanDate = g.at(takeAway.LoanDays + 1 + g.rng.Intn(20))
+			}
+			due := takeAway.DueDate(loanDate)
+			if overdue != due.Before(g.opts.Now) {
+				continue
+			}
+			if err := g.openLoan(member, resource, copy, loanDate, due); err != nil {
+				return err
+			}
+			open[member.ID]++
+		}
+	}
+	return nil
+}
+
+// createLoan enregistre un prêt généré.
+func (g *generator) createLoan(member models.User, resource models.Resource, copy models.Copy, borrowType string, loanDate, due time.Time, status models.LoanStatus) (models.Loan, error) {
+	loan := models.Loan{
+		UserID:     member.ID,
+		ResourceID: resource.ID,
+		CopyID:     copy.ID,
+		BorrowType: borrowType,
+		LoanDate:   loanDate,
+		ReturnDate: due,
+		Status:     status,
+	}
+	if err := g.tx.Create(&loan).Error; err != nil {
+		return loan, err
+	}
+	g.summary.Loans++
+	return loan, nil
+}
+
+// openLoan enregistre un prêt à emporter non rendu : l'exemplaire passe en emprunté et, si la date
+// de retour est dépassée, le prêt passe en retard avec l'amende accumulée à la date de référence.
+func (g *generator) openLoan(member models.User, resource models.Resource, copy models.Copy, loanDate, due time.Time) error {
+	if _, err := g.catalog.TransitionCopy(copy.ID, models.ResourceBorrow); err != nil {
+		return err
+	}
+	loan, err := g.createLoan(member, resource, copy, models.BorrowTakeAway, loanDate, due, models.LoanActive)
+	if err != nil || !due.Before(g.opts.Now) {
+		return err
+	}
+	if err := g.store.Loans().SetStatus(loan.ID, models.LoanActive, models.LoanOverdue); err != nil {
+		return err
+	}
+	loan.Status = models.LoanOverdue
+	if _, err := g.loanSvc.AccrueFine(&loan, g.opts.Now); err != nil {
+		return err
+	}
+	g.summary.Overdue++
+	return nil
+}
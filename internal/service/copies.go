@@ -0,0 +1,368 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/models"
+)
+
+// ErrBarcodeTaken signale un code-barres déjà attribué à un autre exemplaire.
+var ErrBarcodeTaken = errors.New("code-barres déjà utilisé")
+
+// CopyCounts compte les exemplaires d'une ressource par statut.
+type CopyCounts struct {
+	ResourceID  uint
+	Total       int
+	Available   int
+	Borrowed    int
+	Reserved    int
+	Unavailable int
+}
+
+// Status retourne le statut de synthèse d'une ressource : disponible si au moins un exemplaire l'est,
+// emprunté si au moins un exemplaire est prêté, réservé si les exemplaires restants attendent
+// leur réservataire, indisponible sinon (y compris sans exemplaire).
+func (c CopyCounts) Status() models.ResourceStatus {
+	switch {
+	case c.Available > 0:
+		return models.ResourceAvailable
+	case c.Borrowed > 0:
+		return models.ResourceBorrowed
+	case c.Reserved > 0:
+		return models.ResourceReserved
+	default:
+		return models.ResourceUnavailable
+	}
+}
+
+// Add compte un exemplaire du statut donné.
+func (c *CopyCounts) Add(status models.ResourceStatus) {
+	c.Total++
+	switch status {
+	case models.ResourceAvailable:
+		c.Available++
+	case models.ResourceBorrowed:
+		c.Borrowed++
+	case models.ResourceReserved:
+		c.Reserved++
+	default:
+		c.Unavailable++
+	}
+}
+
+// SummaryStatus retourne le statut de synthèse d'une ressource dont les exemplaires ont les statuts donnés.
+func SummaryStatus(statuses []models.ResourceStatus) models.ResourceStatus {
+	var counts CopyCounts
+	for _, status := range statuses {
+		counts.Add(status)
+	}
+	return counts.Status()
+}
+
+// refreshStatus recalcule le statut de synthèse d'une ressource à partir de ses exemplaires et le retourne.
+func refreshStatus(store Store, resourceID uint) (models.ResourceStatus, error) {
+	counts, err := store.Copies().Counts([]uint{resourceID})
+	if err != nil {
+		return "", err
+	}
+	status := counts[resourceID].Status()
+	return status, store.Resources().SetStatus(resourceID, status)
+}
+
+// fillCopyCounts renseigne AvailableCopies et TotalCopies pour chaque ressource.
+func fillCopyCounts(store Store, resources []models.Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(resources))
+	for i, resource := range resources {
+		ids[i] = resource.ID
+	}
+
+	counts, err := store.Copies().Counts(ids)
+	if err != nil {
+		return err
+	}
+	for i := range resources {
+		resources[i].TotalCopies = counts[resources[i].ID].Total
+		resources[i].AvailableCopies = counts[resources[i].ID].Available
+	}
+	return nil
+}
+
+// createResource enregistre une ressource et ses exemplaires. Sans exemplaire fourni, un exemplaire unique
+// est créé. Les exemplaires sans statut prennent celui de la ressource et ceux sans code-barres reçoivent
+// un code généré ; le statut de la ressource devient la synthèse de ses exemplaires.
+func createResource(store Store, resource *models.Resource) error {
+	copies := resource.Copies
+	if len(copies) == 0 {
+		copies = []models.Copy{{}}
+	}
+	resource.Copies = nil
+
+	if err := store.Resources().Create(resource); err != nil {
+		return err
+	}
+	for i := range copies {
+		if copies[i].Status == "" {
+			copies[i].Status = resource.Status
+		}
+		if err := addCopy(store, resource.ID, &copies[i]); err != nil {
+			return err
+		}
+	}
+	resource.Copies = copies
+
+	status, err := refreshStatus(store, resource.ID)
+	if err != nil {
+		return err
+	}
+	resource.Status = status
+	return nil
+}
+
+// addCopy ajoute un exemplaire à une ressource existante. Le statut de synthèse de la ressource n'est pas recalculé.
+func addCopy(store Store, resourceID uint, copy *models.Copy) error {
+	copy.ID = 0
+	copy.ResourceID = resourceID
+	if copy.Status == "" {
+		copy.Status = models.ResourceAvailable
+	}
+	if copy.Condition == "" {
+		copy.Condition = models.ConditionGood
+	}
+
+	if copy.Barcode == "" {
+		barcode, err := nextBarcode(store, resourceID)
+		if err != nil {
+			return err
+		}
+		copy.Barcode = barcode
+	}
+	return store.Copies().Create(copy)
+}
+
+// nextBarcode génère un code-barres libre de la forme "R00042-3" pour un exemplaire de la ressource.
+func nextBarcode(store Store, resourceID uint) (string, error) {
+	counts, err := store.Copies().Counts([]uint{resourceID})
+	if err != nil {
+		return "", err
+	}
+	for n := counts[resourceID].Total + 1; ; n++ {
+		barcode := fmt.Sprintf("R%05d-%d", resourceID, n)
+		taken, err := store.Copies().BarcodeTaken(barcode, 0)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return barcode, nil
+		}
+	}
+}
+
+// borrowCopy passe en emprunté un exemplaire de la ressource. Si copyID vaut 0, le premier exemplaire
+// disponible est choisi.
+func borrowCopy(store Store, resourceID, copyID uint) (*models.Copy, error) {
+	resource, err := store.Resources().FindByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exemplaire précis demandé : il doit appartenir à la ressource
+	if copyID != 0 {
+		copy, err := store.Copies().FindByID(copyID)
+		if err != nil {
+			return nil, err
+		}
+		if copy.ResourceID != resourceID {
+			return nil, ErrNotFound
+		}
+		return transitionCopy(store, copy.ID, models.ResourceBorrow)
+	}
+
+	candidates, err := store.Copies().ListAvailable(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un autre emprunteur peut prendre un exemplaire entre la lecture et la mise à jour :
+	// on essaie alors le suivant.
+	for _, candidate := range candidates {
+		copy, err := transitionCopy(store, candidate.ID, models.ResourceBorrow)
+		if err == nil {
+			return copy, nil
+		}
+		var transitionErr *TransitionError
+		if !errors.As(err, &transitionErr) {
+			return nil, err
+		}
+	}
+
+	if resource, err = store.Resources().FindByID(resourceID); err != nil {
+		return nil, err
+	}
+	return nil, &TransitionError{
+		Entity:    "resource",
+		Action:    string(models.ResourceBorrow),
+		Current:   string(resource.Status),
+		Requested: string(models.ResourceBorrow.Target()),
+	}
+}
+
+// takeCopy passe en emprunté l'exemplaire de la ressource destiné à l'utilisateur : celui mis de côté
+// par sa réservation prête, sinon l'exemplaire copyID, sinon le premier disponible.
+func takeCopy(store Store, userID, resourceID, copyID uint) (*models.Copy, error) {
+	hold, err := store.Holds().FindReady(userID, resourceID)
+	if errors.Is(err, ErrNotFound) {
+		return borrowCopy(store, resourceID, copyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pickUpHold(store, hold)
+}
+
+// Copies liste les exemplaires d'une ressource.
+func (s *CatalogService) Copies(resourceID uint) ([]models.Copy, error) {
+	if _, err := s.store.Resources().FindByID(resourceID); err != nil {
+		return nil, notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+	}
+	return s.store.Copies().ListByResource(resourceID)
+}
+
+// AddCopy ajoute un exemplaire à une ressource existante et met à jour son statut de synthèse.
+func (s *CatalogService) AddCopy(resourceID uint, copy *models.Copy) error {
+	return s.store.Transaction(func(tx Store) error {
+		if _, err := tx.Resources().FindByID(resourceID); err != nil {
+			return notFound(err, apierror.CodeCopyNotFound, "Ressource ou exemplaire non trouvé")
+		}
+		if copy.Barcode != "" {
+			if err := checkBarcode(tx, copy.Barcode, 0); err != nil {
+				return err
+			}
+		}
+		if err := addCopy(tx, resourceID, copy); err != nil {
+			return err
+		}
+		_, err := refreshStatus(tx, resourceID)
+		return err
+	})
+}
+
+// UpdateCopy modifie le code-barres, l'emplacement ou l'état d'un exemplaire ; les champs vides de changes sont conservés.
+func (s *CatalogService) UpdateCopy(copyID uint, changes models.Copy) (*models.Copy, error) {
+	var copy *models.Copy
+	err := s.store.Transaction(func(tx Store) error {
+		var err error
+		if copy, err = tx.Copies().FindByID(copyID); err != nil {
+			return notFound(err, apierror.CodeCopyNotFound, "Ressource ou exemplaire non trouvé")
+		}
+		changed := false
+		if changes.Barcode != "" && changes.Barcode != copy.Barcode {
+			if err := checkBarcode(tx, changes.Barcode, copy.ID); err != nil {
+				return err
+			}
+			copy.Barcode, changed = changes.Barcode, true
+		}
+		if changes.Location != "" {
+			copy.Location, changed = changes.Location, true
+		}
+		if changes.Condition != "" {
+			copy.Condition, changed = changes.Condition, true
+		}
+		if !changed {
+			return nil
+		}
+		return tx.Copies().Update(copy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copy, nil
+}
+
+// checkBarcode refuse un code-barres déjà attribué à un autre exemplaire que exceptID.
+func checkBarcode(store Store, barcode string, exceptID uint) error {
+	taken, err := store.Copies().BarcodeTaken(barcode, exceptID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return newError(KindConflict, apierror.CodeBarcodeTaken, ErrBarcodeTaken, "Ce code-barres est déjà utilisé")
+	}
+	return nil
+}
+
+// TransitionCopy applique une action du personnel (retirer, remettre en service) à un exemplaire
+// en respectant la table de transitions.
+func (s *CatalogService) TransitionCopy(copyID uint, action models.ResourceAction) (*models.Copy, error) {
+	var copy *models.Copy
+	err := s.store.Transaction(func(tx Store) error {
+		var err error
+		copy, err = transitionCopy(tx, copyID, action)
+		return err
+	})
+	if err != nil {
+		return nil, transitionConflict(notFound(err, apierror.CodeCopyNotFound, "Ressource ou exemplaire non trouvé"),
+			apierror.CodeInvalidTransition, "Changement de statut impossible")
+	}
+	return copy, nil
+}
+
+// TransitionCopies applique une action à tous les exemplaires de la ressource qui l'autorisent
+// (par exemple retirer tous les exemplaires disponibles). Si aucun exemplaire ne l'autorise,
+// le conflit porte le statut de synthèse de la ressource. Retourne la ressource avec ses exemplaires.
+func (s *CatalogService) TransitionCopies(resourceID uint, action models.ResourceAction) (*models.Resource, error) {
+	err := s.store.Transaction(func(tx Store) error {
+		resource, err := tx.Resources().FindByID(resourceID)
+		if err != nil {
+			return notFound(err, apierror.CodeResourceNotFound, "Ressource introuvable")
+		}
+		copies, err := tx.Copies().ListByResource(resourceID)
+		if err != nil {
+			return err
+		}
+
+		changed := 0
+		for _, copy := range copies {
+			if !copy.Status.Allows(action) {
+				continue
+			}
+			if _, err := transitionCopy(tx, copy.ID, action); err != nil {
+				var transitionErr *TransitionError
+				if errors.As(err, &transitionErr) {
+					continue
+				}
+				return err
+			}
+			changed++
+		}
+		if changed == 0 {
+			return transitionConflict(&TransitionError{
+				Entity:    "resource",
+				Action:    string(action),
+				Current:   string(resource.Status),
+				Requested: string(action.Target()),
+			}, apierror.CodeInvalidTransition, "Changement de statut impossible")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.store.Resources().FindByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if resource.Copies, err = s.store.Copies().ListByResource(resourceID); err != nil {
+		return nil, err
+	}
+	resources := []models.Resource{*resource}
+	if err := fillCopyCounts(s.store, resources); err != nil {
+		return nil, err
+	}
+	return &resources[0], nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+
+	"awesomeProject/internal/apierror"
+)
+
+// Kind classe les erreurs du domaine ; chaque adaptateur (HTTP, ligne de commande) en déduit sa réponse.
+type Kind string
+
+const (
+	KindInvalid      Kind = "invalid"      // Donnée refusée par une règle de validation
+	KindUnauthorized Kind = "unauthorized" // Identifiants invalides
+	KindForbidden    Kind = "forbidden"    // Opération interdite à l'utilisateur
+	KindNotFound     Kind = "not_found"    // Enregistrement absent
+	KindConflict     Kind = "conflict"     // Opération incompatible avec l'état courant
+)
+
+// ErrNotFound signale un enregistrement absent. Les dépôts le retournent quel que soit le stockage.
+var ErrNotFound = errors.New("enregistrement non trouvé")
+
+// ErrConstraint signale une écriture refusée par une contrainte d'intégrité du stockage
+// (unicité, clé étrangère, CHECK, NOT NULL). L'erreur d'origine reste consultable avec errors.As.
+var ErrConstraint = errors.New("contrainte d'intégrité non respectée")
+
+// ErrStale signale une mise à jour conditionnelle restée sans effet : l'enregistrement a changé depuis sa lecture.
+var ErrStale = errors.New("enregistrement modifié depuis sa lecture")
+
+// Error est une erreur du domaine, porteuse d'un code stable et d'un message destinés à l'utilisateur.
+type Error struct {
+	Kind    Kind
+	Code    apierror.Code  // Code exposé par l'API, par exemple RESOURCE_UNAVAILABLE
+	Message string         // Message destiné à l'utilisateur
+	Details map[string]any // Précisions, par exemple le nombre maximal de renouvellements
+	Err     error          // Cause, consultable avec errors.Is et errors.As
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + " : " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError construit une erreur du domaine.
+func newError(kind Kind, code apierror.Code, cause error, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Err: cause}
+}
+
+// with ajoute une précision à l'erreur.
+func (e *Error) with(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// transitionConflict traduit un changement de statut interdit en conflit, avec le statut actuel
+// et le statut demandé. Les autres erreurs sont retournées telles quelles.
+func transitionConflict(err error, code apierror.Code, message string) error {
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		return err
+	}
+	return newError(KindConflict, code, err, message).
+		with("current_status", transitionErr.Current).
+		with("requested_status", transitionErr.Requested)
+}
+
+// notFound traduit ErrNotFound en erreur du domaine. Les autres erreurs sont retournées telles quelles.
+func notFound(err error, code apierror.Code, message string) error {
+	if errors.Is(err, ErrNotFound) {
+		return newError(KindNotFound, code, err, message)
+	}
+	return err
+}
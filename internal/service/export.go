@@ -0,0 +1,74 @@
+package service
+
+import (
+	"time"
+
+	"awesomeProject/internal/models"
+)
+
+// ResourceExport est une ligne de l'export du catalogue. Les métadonnées absentes sont vides.
+type ResourceExport struct {
+	ID              uint     `json:"id"`
+	Title           string   `json:"title"`
+	Type            string   `json:"type"`
+	Status          string   `json:"status"`
+	TotalCopies     int      `json:"total_copies"`
+	AvailableCopies int      `json:"available_copies"`
+	Authors         []string `json:"authors" gorm:"serializer:json"`
+	Publisher       string   `json:"publisher"`
+	Year            int      `json:"year"`
+	ISBN            string   `json:"isbn"`
+	Language        string   `json:"language"`
+	Genres          []string `json:"genres" gorm:"serializer:json"`
+	CoverURL        string   `json:"cover_url"`
+	Designer        string   `json:"designer"`
+	MinPlayers      int      `json:"min_players"`
+	MaxPlayers      int      `json:"max_players"`
+	PlayTime        int      `json:"play_time"`
+	MinAge          int      `json:"min_age"`
+	Complexity      float64  `json:"complexity"`
+}
+
+// ResourceExportFilter restreint l'export du catalogue. Les champs vides ne filtrent pas.
+type ResourceExportFilter struct {
+	Type   string
+	Status models.ResourceStatus
+}
+
+// LoanExport est une ligne de l'export des prêts, avec l'adhérent, la ressource et l'exemplaire.
+type LoanExport struct {
+	ID            uint      `json:"id"`
+	LoanDate      time.Time `json:"loan_date"`
+	ReturnDate    time.Time `json:"return_date"`
+	Status        string    `json:"status"`
+	BorrowType    string    `json:"borrow_type"`
+	RenewalCount  int       `json:"renewal_count"`
+	UserID        uint      `json:"user_id"`
+	UserName      string    `json:"user_name"`
+	UserEmail     string    `json:"user_email"`
+	ResourceID    uint      `json:"resource_id"`
+	ResourceTitle string    `json:"resource_title"`
+	ResourceType  string    `json:"resource_type"`
+	CopyBarcode   string    `json:"copy_barcode"`
+}
+
+// LoanExportFilter restreint l'export des prêts. Les champs vides ne filtrent pas.
+type LoanExportFilter struct {
+	From   time.Time // Prêts accordés à partir de cette date
+	To     time.Time // Prêts accordés avant cette date (exclue)
+	Status models.LoanStatus
+	Type   string // Type de la ressource empruntée
+	UserID uint
+}
+
+// ExportResources lit l'export du catalogue ligne par ligne, par identifiant, et passe chaque ligne à fn.
+// Une erreur retournée par fn interrompt l'export.
+func (s *CatalogService) ExportResources(filter ResourceExportFilter, fn func(ResourceExport) error) error {
+	return s.store.Resources().Export(filter, fn)
+}
+
+// ExportLoans lit l'export des prêts ligne par ligne, par date de prêt, et passe chaque ligne à fn.
+// Les prêts des ressources supprimées restent dans l'export.
+func (s *CatalogService) ExportLoans(filter LoanExportFilter, fn func(LoanExport) error) error {
+	return s.store.Loans().Export(filter, fn)
+}
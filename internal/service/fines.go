@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/models"
+)
+
+var (
+	// ErrInvalidAmount signale un montant nul ou négatif.
+	ErrInvalidAmount = errors.New("montant invalide")
+	// ErrAmountExceedsBalance signale un paiement ou une remise supérieur au solde dû.
+	ErrAmountExceedsBalance = errors.New("montant supérieur au solde dû")
+)
+
+// LateFine calcule l'amende d'un prêt dû à dueDate : rate par jour de retard entamé à now, plafonnée à cap.
+func LateFine(dueDate, now time.Time, rate, cap int) int {
+	if !now.After(dueDate) {
+		return 0
+	}
+	days := int((now.Sub(dueDate) + 24*time.Hour - 1) / (24 * time.Hour))
+	return min(days*rate, cap)
+}
+
+// MarkOverdue passe en retard les prêts en cours dont la date de retour précède now.
+// Retourne le nombre de prêts signalés.
+func (s *LoanService) MarkOverdue(now time.Time) (int, error) {
+	loans, err := s.store.Loans().ListDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, loan := range loans {
+		_, err := transitionLoan(s.store, loan.ID, models.LoanMarkOverdue)
+		var transitionErr *TransitionError
+		if errors.As(err, &transitionErr) {
+			// Rendu ou renouvelé entre-temps
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// AccrueFine complète l'amende d'un prêt pour atteindre LateFine à now, au taux de sa politique de prêt.
+// Retourne le montant ajouté.
+func (s *LoanService) AccrueFine(loan *models.Loan, now time.Time) (int, error) {
+	return s.accrueFine(s.store, loan, now)
+}
+
+// accrueFine complète l'amende d'un prêt sur le stockage fourni.
+func (s *LoanService) accrueFine(store Store, loan *models.Loan, now time.Time) (int, error) {
+	policy, err := s.loanPolicy(store, loan)
+	if err != nil {
+		return 0, err
+	}
+	due := LateFine(loan.ReturnDate, now, policy.FineDailyRate, policy.FineCap)
+	if due == 0 {
+		return 0, nil
+	}
+	return store.Fines().Charge(loan, due, now)
+}
+
+// AccrueFines met à jour l'amende de chaque prêt en retard, au taux de la politique de prêt applicable.
+// Retourne le nombre de prêts dont l'amende a augmenté.
+func (s *LoanService) AccrueFines(now time.Time) (int, error) {
+	loans, err := s.store.Loans().ListOverdue()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range loans {
+		added, err := s.AccrueFine(&loans[i], now)
+		if err != nil {
+			return count, err
+		}
+		if added > 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FineService regroupe les règles du compte d'amendes : consultation, paiement et remise.
+type FineService struct {
+	store Store
+	clock clock.Clock
+}
+
+// NewFineService crée le service des amendes sur le stockage fourni, avec l'horloge fournie.
+func NewFineService(store Store, clk clock.Clock) *FineService {
+	return &FineService{store: store, clock: clk}
+}
+
+// List retourne le solde dû et l'historique du compte d'amendes de l'utilisateur.
+// Le personnel peut consulter le compte d'un autre utilisateur en fournissant userID.
+func (s *FineService) List(actor Actor, userID uint) (int, []models.FineEntry, error) {
+	if userID == 0 {
+		userID = actor.ID
+	} else if userID != actor.ID && !actor.IsStaff() {
+		return 0, nil, newError(KindForbidden, apierror.CodeForbidden, ErrForbidden, "Accès non autorisé")
+	}
+
+	entries, err := s.store.Fines().ListByUser(userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	balance, err := s.store.Fines().Balance(userID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return balance, entries, nil
+}
+
+// invalidAmount est l'erreur d'un montant refusé : négatif, nul pour un paiement, ou remise totale d'un solde nul.
+func invalidAmount() *Error {
+	return newError(KindInvalid, apierror.CodeValidationFailed, ErrInvalidAmount, "Montant invalide").with("field", "amount")
+}
+
+// RecordPayment enregistre un paiement d'amende saisi par le personnel et retourne le nouveau solde.
+func (s *FineService) RecordPayment(actor Actor, userID uint, amount int, note string) (*models.FineEntry, int, error) {
+	if amount <= 0 {
+		return nil, 0, invalidAmount()
+	}
+	return s.credit(actor, userID, models.FinePayment, amount, note)
+}
+
+// Waive accorde une remise sur le solde dû et retourne le nouveau solde. Un montant nul annule la totalité du solde.
+func (s *FineService) Waive(actor Actor, userID uint, amount int, note string) (*models.FineEntry, int, error) {
+	if amount < 0 {
+		return nil, 0, invalidAmount()
+	}
+	return s.credit(actor, userID, models.FineWaiver, amount, note)
+}
+
+// credit inscrit un paiement ou une remise, sans dépasser le solde dû ; un montant nul crédite
+// la totalité du solde. L'adhérent est verrouillé pour que deux crédits simultanés ne dépassent pas ce solde.
+func (s *FineService) credit(actor Actor, userID uint, kind string, amount int, note string) (*models.FineEntry, int, error) {
+	var entry models.FineEntry
+	var balance int
+	err := s.store.Transaction(func(tx Store) error {
+		if err := tx.Users().Lock(userID); err != nil {
+			return notFound(err, apierror.CodeUserNotFound, "Utilisateur non trouvé")
+		}
+		var err error
+		if balance, err = tx.Fines().Balance(userID); err != nil {
+			return err
+		}
+		if amount == 0 {
+			if balance <= 0 {
+				return invalidAmount()
+			}
+			amount = balance
+		}
+		if amount > balance {
+			return newError(KindConflict, apierror.CodeAmountExceedsBalance, ErrAmountExceedsBalance, "Le montant dépasse le solde dû")
+		}
+
+		staffID := actor.ID
+		entry = models.FineEntry{
+			UserID:      userID,
+			Kind:        kind,
+			Amount:      -amount,
+			Note:        note,
+			CreatedByID: &staffID,
+			CreatedAt:   s.clock.Now(),
+		}
+		if err := tx.Fines().Create(&entry); err != nil {
+			return err
+		}
+		balance -= amount
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &entry, balance, nil
+}
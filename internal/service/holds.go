@@ -0,0 +1,358 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/models"
+)
+
+var (
+	// ErrResourceAvailable signale une réservation inutile : un exemplaire peut être emprunté directement.
+	ErrResourceAvailable = errors.New("un exemplaire est disponible")
+	// ErrHoldExists signale que l'adhérent a déjà une réservation active sur la ressource.
+	ErrHoldExists = errors.New("réservation déjà en cours")
+	// ErrAlreadyBorrowed signale que l'adhérent a déjà un prêt en cours sur la ressource.
+	ErrAlreadyBorrowed = errors.New("ressource déjà empruntée par l'adhérent")
+)
+
+// HoldService regroupe les règles de réservation : file d'attente, mise de côté et expiration.
+type HoldService struct {
+	store Store
+	cfg   config.LoanConfig
+	clock clock.Clock
+}
+
+// NewHoldService crée le service de réservation sur le stockage fourni, avec les règles de prêt et l'horloge fournies.
+func NewHoldService(store Store, cfg config.LoanConfig, clk clock.Clock) *HoldService {
+	return &HoldService{store: store, cfg: cfg, clock: clk}
+}
+
+// fillHoldPositions renseigne le rang dans la file des réservations en attente.
+func fillHoldPositions(store Store, holds []models.Hold) error {
+	positions := map[uint]int{}
+	queued := map[uint]bool{}
+	for i := range holds {
+		resourceID := holds[i].ResourceID
+		if holds[i].Status == models.HoldWaiting && !queued[resourceID] {
+			queue, err := store.Holds().Queue(resourceID)
+			if err != nil {
+				return err
+			}
+			for position, waiting := range queue {
+				positions[waiting.ID] = position + 1
+			}
+			queued[resourceID] = true
+		}
+		holds[i].Position = positions[holds[i].ID]
+	}
+	return nil
+}
+
+// Queue retourne les réservations en attente d'une ressource, avec leur rang.
+func (s *HoldService) Queue(resourceID uint) ([]models.Hold, error) {
+	holds, err := s.store.Holds().Queue(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range holds {
+		holds[i].Position = i + 1
+	}
+	return holds, nil
+}
+
+// Place inscrit l'adhérent dans la file d'attente d'une ressource dont aucun exemplaire n'est disponible.
+// La ressource est verrouillée pendant les vérifications, qui ne peuvent pas être faussées par une réservation simultanée.
+func (s *HoldService) Place(actor Actor, resourceID uint) (*models.Hold, error) {
+	now := s.clock.Now()
+	if err := processResourceHolds(s.store, resourceID, now, s.cfg.HoldPickupDelay); err != nil {
+		return nil, err
+	}
+
+	var hold models.Hold
+	err := s.store.Transaction(func(tx Store) error {
+		if err := tx.Resources().Lock(resourceID); err != nil {
+			return notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+		}
+
+		available, err := tx.Copies().ListAvailable(resourceID)
+		if err != nil {
+			return err
+		}
+		if len(available) > 0 {
+			return newError(KindConflict, apierror.CodeResourceAvailable, ErrResourceAvailable, "Un exemplaire est disponible : empruntez-le directement")
+		}
+
+		count, err := tx.Holds().CountActive(actor.ID, resourceID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return newError(KindConflict, apierror.CodeHoldExists, ErrHoldExists, "Vous avez déjà une réservation pour cette ressource")
+		}
+
+		if count, err = tx.Loans().CountOpenByResource(resourceID, actor.ID); err != nil {
+			return err
+		}
+		if count > 0 {
+			return newError(KindConflict, apierror.CodeAlreadyBorrowed, ErrAlreadyBorrowed, "Vous empruntez déjà cette ressource")
+		}
+
+		hold = models.Hold{UserID: actor.ID, ResourceID: resourceID, Status: models.HoldWaiting, CreatedAt: now}
+		if err := tx.Holds().Create(&hold); err != nil {
+			return err
+		}
+		holds := []models.Hold{hold}
+		if err := fillHoldPositions(tx, holds); err != nil {
+			return err
+		}
+		hold = holds[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// List retourne les réservations de l'utilisateur, avec leur rang dans la file. Le personnel peut
+// consulter les réservations actives d'une ressource en fournissant resourceID.
+func (s *HoldService) List(actor Actor, resourceID uint) ([]models.Hold, error) {
+	if resourceID != 0 && !actor.IsStaff() {
+		return nil, newError(KindForbidden, apierror.CodeForbidden, ErrForbidden, "Accès non autorisé")
+	}
+
+	// Traiter les files des ressources consultées, pour afficher des statuts et des rangs à jour
+	list := func() ([]models.Hold, error) {
+		if resourceID != 0 {
+			return s.store.Holds().ListActive(resourceID)
+		}
+		return s.store.Holds().ListByUser(actor.ID)
+	}
+	holds, err := list()
+	if err != nil {
+		return nil, err
+	}
+	processed := map[uint]bool{}
+	for _, hold := range holds {
+		if processed[hold.ResourceID] || (hold.Status != models.HoldWaiting && hold.Status != models.HoldReady) {
+			continue
+		}
+		processed[hold.ResourceID] = true
+		if err := processResourceHolds(s.store, hold.ResourceID, s.clock.Now(), s.cfg.HoldPickupDelay); err != nil {
+			return nil, err
+		}
+	}
+	if len(processed) > 0 {
+		if holds, err = list(); err != nil {
+			return nil, err
+		}
+	}
+	if err := fillHoldPositions(s.store, holds); err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// Cancel annule une réservation de l'utilisateur (ou de n'importe qui pour le personnel).
+// Si un exemplaire était mis de côté, il est proposé à la réservation suivante ou redevient disponible.
+func (s *HoldService) Cancel(actor Actor, holdID uint) (*models.Hold, error) {
+	hold, err := s.store.Holds().FindByID(holdID)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeHoldNotFound, "Réservation non trouvée")
+	}
+	if hold.UserID != actor.ID && !actor.IsStaff() {
+		return nil, newError(KindForbidden, apierror.CodeForbidden, ErrForbidden, "Accès non autorisé")
+	}
+
+	var cancelled *models.Hold
+	err = s.store.Transaction(func(tx Store) error {
+		var err error
+		if cancelled, err = transitionHold(tx, hold.ID, models.HoldCancel, nil); err != nil {
+			return transitionConflict(err, apierror.CodeInvalidTransition, "La réservation n'est plus active")
+		}
+		if cancelled.CopyID == nil {
+			return nil
+		}
+		return releaseCopy(tx, *cancelled.CopyID, models.ResourceRelease, s.clock.Now(), s.cfg.HoldPickupDelay)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// Process fait expirer les réservations prêtes dont la date limite de retrait précède now et sert
+// les files d'attente des ressources redevenues disponibles. Retourne le nombre de réservations expirées et servies.
+func (s *HoldService) Process(now time.Time) (expired, served int, err error) {
+	return processHolds(s.store, now, s.cfg.HoldPickupDelay)
+}
+
+// serveHolds met de côté les exemplaires disponibles d'une ressource pour les premières réservations
+// de la file. Chaque réservation servie devient prête, avec une date limite de retrait à now + pickupDelay.
+// Retourne le nombre de réservations servies.
+func serveHolds(store Store, resourceID uint, now time.Time, pickupDelay time.Duration) (int, error) {
+	served := 0
+	for {
+		queue, err := store.Holds().Queue(resourceID)
+		if err != nil || len(queue) == 0 {
+			return served, err
+		}
+		available, err := store.Copies().ListAvailable(resourceID)
+		if err != nil || len(available) == 0 {
+			return served, err
+		}
+
+		copyID := available[0].ID
+		if _, err := transitionCopy(store, copyID, models.ResourceHold); err != nil {
+			return served, err
+		}
+		deadline := now.Add(pickupDelay)
+		readyAt := now
+		_, err = transitionHold(store, queue[0].ID, models.HoldNotify, func(hold *models.Hold) {
+			hold.CopyID = &copyID
+			hold.ReadyAt = &readyAt
+			hold.PickupDeadline = &deadline
+		})
+		if err != nil {
+			return served, err
+		}
+		served++
+	}
+}
+
+// pickUpHold emprunte l'exemplaire mis de côté pour une réservation prête et marque celle-ci honorée.
+func pickUpHold(store Store, hold *models.Hold) (*models.Copy, error) {
+	if hold.CopyID == nil {
+		return nil, &TransitionError{Entity: "hold", Action: string(models.HoldFulfill), Current: string(hold.Status), Requested: string(models.HoldFulfilled)}
+	}
+	copy, err := transitionCopy(store, *hold.CopyID, models.ResourcePickup)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := transitionHold(store, hold.ID, models.HoldFulfill, nil); err != nil {
+		return nil, err
+	}
+	return copy, nil
+}
+
+// processHolds fait expirer les réservations prêtes dont la date limite de retrait est dépassée,
+// en passant leur exemplaire à la réservation suivante, puis sert les files d'attente des ressources
+// ayant de nouveau un exemplaire disponible (remise en service, ajout d'un exemplaire).
+// Chaque réservation est traitée dans sa propre transaction ; seules les transactions validées sont comptées.
+// Ce parcours de toutes les ressources est réservé à la tâche planifiée : les opérations des adhérents
+// ne traitent que la ressource concernée, avec processResourceHolds.
+func processHolds(store Store, now time.Time, pickupDelay time.Duration) (expired, served int, err error) {
+	overdue, err := store.Holds().ListExpired(0, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, hold := range overdue {
+		err := store.Transaction(func(tx Store) error {
+			return expireHold(tx, hold.ID, now, pickupDelay)
+		})
+		var transitionErr *TransitionError
+		if errors.As(err, &transitionErr) {
+			// Réservation retirée ou annulée entre-temps
+			continue
+		}
+		if err != nil {
+			return expired, served, err
+		}
+		expired++
+	}
+
+	resourceIDs, err := store.Holds().ResourcesToServe()
+	if err != nil {
+		return expired, served, err
+	}
+	for _, resourceID := range resourceIDs {
+		var count int
+		err := store.Transaction(func(tx Store) error {
+			var err error
+			count, err = serveHolds(tx, resourceID, now, pickupDelay)
+			return err
+		})
+		if err != nil {
+			return expired, served, err
+		}
+		served += count
+	}
+	return expired, served, nil
+}
+
+// processResourceHolds fait expirer les réservations dépassées d'une ressource et sert sa file d'attente,
+// dans une transaction qui verrouille la ressource. Si une opération simultanée a déjà traité la ressource,
+// la transaction est annulée sans erreur.
+func processResourceHolds(store Store, resourceID uint, now time.Time, pickupDelay time.Duration) error {
+	err := store.Transaction(func(tx Store) error {
+		if err := tx.Resources().Lock(resourceID); err != nil {
+			return err
+		}
+		overdue, err := tx.Holds().ListExpired(resourceID, now)
+		if err != nil {
+			return err
+		}
+		for _, hold := range overdue {
+			if err := expireHold(tx, hold.ID, now, pickupDelay); err != nil {
+				return err
+			}
+		}
+		_, err = serveHolds(tx, resourceID, now, pickupDelay)
+		return err
+	})
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) || errors.Is(err, ErrNotFound) {
+		// Traitée entre-temps, ou ressource inconnue : l'opération appelante le signalera
+		return nil
+	}
+	return err
+}
+
+// expireHold fait expirer une réservation prête et passe son exemplaire à la réservation suivante.
+func expireHold(store Store, holdID uint, now time.Time, pickupDelay time.Duration) error {
+	expiredHold, err := transitionHold(store, holdID, models.HoldExpire, nil)
+	if err != nil {
+		return err
+	}
+	if expiredHold.CopyID == nil {
+		return nil
+	}
+	return releaseCopy(store, *expiredHold.CopyID, models.ResourceRelease, now, pickupDelay)
+}
+
+// releaseCopy remet en disponible un exemplaire mis de côté (action libérer) ou rendu (action retourner),
+// puis sert la file d'attente.
+func releaseCopy(store Store, copyID uint, action models.ResourceAction, now time.Time, pickupDelay time.Duration) error {
+	copy, err := transitionCopy(store, copyID, action)
+	if err != nil {
+		return err
+	}
+	_, err = serveHolds(store, copy.ResourceID, now, pickupDelay)
+	return err
+}
+
+// cancelResourceHolds annule toutes les réservations actives d'une ressource retirée du catalogue.
+// Les exemplaires mis de côté redeviennent disponibles sans servir de nouvelle réservation.
+func cancelResourceHolds(store Store, resourceID uint) error {
+	holds, err := store.Holds().ListActive(resourceID)
+	if err != nil {
+		return err
+	}
+	for _, hold := range holds {
+		cancelled, err := transitionHold(store, hold.ID, models.HoldCancel, nil)
+		if err != nil {
+			return err
+		}
+		if cancelled.CopyID != nil {
+			if _, err := transitionCopy(store, *cancelled.CopyID, models.ResourceRelease); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+
+	"awesomeProject/internal/models"
+)
+
+// Sort d'une ressource importée
+const (
+	ImportCreated = "created"
+	ImportSkipped = "skipped"
+	ImportFailed  = "error"
+)
+
+// errImportRollback annule la transaction d'un import que l'appelant ne valide pas.
+var errImportRollback = errors.New("import annulé")
+
+// ImportResult décrit le sort d'une ressource importée.
+type ImportResult struct {
+	Status string // ImportCreated, ImportSkipped ou ImportFailed ; vide pour une ligne écartée par l'appelant
+	ID     uint   // Ressource créée, ou doublon existant
+	Reason string // Motif, message du catalogue à traduire
+}
+
+// Import enregistre un lot de ressources validées dans une seule transaction. Une ressource déjà
+// au catalogue (même ISBN, ou même titre et type) ou déjà présente plus haut dans le lot est ignorée ;
+// une entrée nil est une ligne écartée par l'appelant. L'échec d'une ressource n'annule pas les précédentes.
+// commit reçoit les résultats et décide de conserver le lot ; Import retourne les résultats et
+// indique si le lot a été conservé.
+func (s *CatalogService) Import(resources []*models.Resource, commit func([]ImportResult) bool) ([]ImportResult, bool, error) {
+	results := make([]ImportResult, len(resources))
+	err := s.store.Transaction(func(tx Store) error {
+		// Doublons à l'intérieur du lot
+		seen := map[string]uint{}
+		for i, resource := range resources {
+			if resource == nil {
+				continue
+			}
+			if err := importResource(tx, resource, seen, &results[i]); err != nil {
+				return err
+			}
+		}
+		if !commit(results) {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, false, err
+	}
+	return results, err == nil, nil
+}
+
+// importResource enregistre une ressource du lot et complète son résultat. Une ressource refusée
+// par une règle du domaine ou une contrainte du stockage est signalée sur sa ligne ; toute autre
+// erreur du stockage interrompt le lot.
+func importResource(tx Store, resource *models.Resource, seen map[string]uint, result *ImportResult) error {
+	key := "title:" + models.TitleKey(resource.Title) + "\x00" + resource.Type
+	if resource.Book != nil && resource.Book.ISBN != nil {
+		key = "isbn:" + *resource.Book.ISBN
+	}
+	if id, ok := seen[key]; ok {
+		*result = ImportResult{Status: ImportSkipped, ID: id, Reason: "doublon d'une ligne précédente"}
+		return nil
+	}
+	// Un ISBN reste attribué à une ressource supprimée, qu'un administrateur peut restaurer
+	existing, err := tx.Resources().FindDuplicate(*resource)
+	if err != nil {
+		return err
+	}
+	if existing != 0 {
+		*result = ImportResult{Status: ImportSkipped, ID: existing, Reason: "déjà au catalogue"}
+		seen[key] = existing
+		return nil
+	}
+
+	// Point de sauvegarde : l'échec d'une ressource n'annule pas les précédentes
+	if err := tx.Transaction(func(tx Store) error {
+		return createResource(tx, resource)
+	}); err != nil {
+		var domainErr *Error
+		if !errors.As(err, &domainErr) && !errors.Is(err, ErrConstraint) {
+			return err
+		}
+		*result = ImportResult{Status: ImportFailed, Reason: "enregistrement impossible"}
+		return nil
+	}
+	*result = ImportResult{Status: ImportCreated, ID: resource.ID}
+	seen[key] = resource.ID
+	return nil
+}
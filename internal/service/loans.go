@@ -0,0 +1,274 @@
+package service
+
+import (
+	"errors"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/models"
+)
+
+var (
+	// ErrNotRenewable signale un prêt que sa politique ne permet pas de renouveler (par défaut, un prêt sur place).
+	ErrNotRenewable = errors.New("prêt non renouvelable")
+	// ErrLoanOverdue signale un prêt dont la date de retour est dépassée.
+	ErrLoanOverdue = errors.New("prêt en retard")
+	// ErrRenewalLimit signale que le nombre maximal de renouvellements est atteint.
+	ErrRenewalLimit = errors.New("nombre maximal de renouvellements atteint")
+	// ErrHoldPending signale qu'un autre adhérent attend la ressource.
+	ErrHoldPending = errors.New("réservation en attente sur la ressource")
+	// ErrFinesUnpaid signale un adhérent dont le solde d'amendes dépasse le seuil de blocage.
+	ErrFinesUnpaid = errors.New("amendes impayées")
+	// ErrForbidden signale une opération sur le prêt d'un autre adhérent.
+	ErrForbidden = errors.New("accès non autorisé")
+)
+
+// LoanService regroupe les règles de prêt : emprunt, retour et renouvellement.
+type LoanService struct {
+	store Store
+	cfg   config.LoanConfig
+	clock clock.Clock
+}
+
+// NewLoanService crée le service de prêt sur le stockage fourni, avec les règles de prêt et l'horloge fournies.
+func NewLoanService(store Store, cfg config.LoanConfig, clk clock.Clock) *LoanService {
+	return &LoanService{store: store, cfg: cfg, clock: clk}
+}
+
+// BorrowRequest décrit une demande d'emprunt.
+type BorrowRequest struct {
+	ResourceID uint
+	CopyID     uint // Optionnel : exemplaire précis, sinon le premier disponible
+	BorrowType string
+}
+
+// Policy retourne la politique enregistrée pour le type de ressource et le type d'emprunt,
+// ou la politique par défaut de la configuration.
+func (s *LoanService) Policy(resourceType, borrowType string) (models.LoanPolicy, error) {
+	return s.policy(s.store, resourceType, borrowType)
+}
+
+// policy résout la politique applicable sur le stockage fourni.
+func (s *LoanService) policy(store Store, resourceType, borrowType string) (models.LoanPolicy, error) {
+	policy, err := store.Policies().Find(resourceType, borrowType)
+	if errors.Is(err, ErrNotFound) {
+		return DefaultPolicy(s.cfg, resourceType, borrowType), nil
+	}
+	if err != nil {
+		return models.LoanPolicy{}, err
+	}
+	return *policy, nil
+}
+
+// loanPolicy retourne la politique applicable à un prêt existant.
+func (s *LoanService) loanPolicy(store Store, loan *models.Loan) (models.LoanPolicy, error) {
+	resource, err := store.Resources().FindByID(loan.ResourceID)
+	if err != nil {
+		return models.LoanPolicy{}, err
+	}
+	return s.policy(store, resource.Type, loan.BorrowType)
+}
+
+// Borrow crée un prêt pour l'utilisateur. Un adhérent dont la réservation est prête
+// emprunte l'exemplaire mis de côté pour lui.
+func (s *LoanService) Borrow(actor Actor, req BorrowRequest) (*models.Loan, error) {
+	// La politique de prêt du type de ressource fixe la date de retour et les limites d'emprunt
+	resource, err := s.store.Resources().FindByID(req.ResourceID)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+	}
+	policy, err := s.policy(s.store, resource.Type, req.BorrowType)
+	if err != nil {
+		return nil, err
+	}
+	loanDate := s.clock.Now()
+
+	// Un adhérent qui doit trop d'amendes ne peut plus emprunter
+	balance, err := s.store.Fines().Balance(actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	if balance > s.cfg.FineBlockThreshold {
+		return nil, newError(KindForbidden, apierror.CodeFinesUnpaid, ErrFinesUnpaid, "Amendes impayées : réglez votre solde avant d'emprunter").
+			with("balance", balance)
+	}
+
+	if err := processResourceHolds(s.store, req.ResourceID, loanDate, s.cfg.HoldPickupDelay); err != nil {
+		return nil, err
+	}
+
+	loan := models.Loan{
+		UserID:     actor.ID,
+		ResourceID: req.ResourceID,
+		BorrowType: req.BorrowType,
+		LoanDate:   loanDate,
+		ReturnDate: policy.DueDate(loanDate),
+		Status:     models.LoanActive,
+	}
+
+	// Réserver un exemplaire et créer le prêt dans une seule transaction :
+	// la transition conditionnelle garantit qu'un exemplaire n'est prêté qu'une fois.
+	err = s.store.Transaction(func(tx Store) error {
+		// Verrouiller la ressource sérialise l'emprunt avec sa suppression
+		if err := tx.Resources().Lock(req.ResourceID); err != nil {
+			return notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+		}
+		if !policy.Allowed {
+			return newError(KindConflict, apierror.CodeBorrowTypeNotAllowed, ErrBorrowTypeNotAllowed, "Ce type d'emprunt n'est pas autorisé pour cette ressource").
+				with("borrow_type", req.BorrowType)
+		}
+		if policy.MaxConcurrentLoans > 0 {
+			// Verrouiller l'adhérent sérialise ses emprunts : sinon, deux emprunts simultanés
+			// d'exemplaires différents passent tous deux la limite sous PostgreSQL.
+			if err := tx.Users().Lock(actor.ID); err != nil {
+				return err
+			}
+			open, err := tx.Loans().CountOpen(actor.ID, policy.ResourceType, policy.BorrowType)
+			if err != nil {
+				return err
+			}
+			if open >= policy.MaxConcurrentLoans {
+				return newError(KindConflict, apierror.CodeLoanLimitReached, ErrTooManyLoans, "Nombre maximal de prêts simultanés atteint").
+					with("max_concurrent_loans", policy.MaxConcurrentLoans)
+			}
+		}
+
+		copy, err := takeCopy(tx, actor.ID, req.ResourceID, req.CopyID)
+		if err != nil {
+			return transitionConflict(notFound(err, apierror.CodeCopyNotFound, "Ressource ou exemplaire non trouvé"),
+				apierror.CodeResourceUnavailable, "La ressource n'est pas disponible")
+		}
+		loan.CopyID = copy.ID
+		return tx.Loans().Create(&loan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// List retourne les prêts de l'utilisateur, avec l'historique des renouvellements.
+// Le personnel peut consulter les prêts d'un autre utilisateur en fournissant userID.
+func (s *LoanService) List(actor Actor, userID uint) ([]models.Loan, error) {
+	if userID == 0 {
+		userID = actor.ID
+	} else if !actor.IsStaff() {
+		return nil, newError(KindForbidden, apierror.CodeForbidden, ErrForbidden, "Accès non autorisé")
+	}
+	return s.store.Loans().ListByUser(userID)
+}
+
+// find retourne le prêt s'il appartient à l'utilisateur ou si celui-ci fait partie du personnel.
+func (s *LoanService) find(actor Actor, loanID uint) (*models.Loan, error) {
+	loan, err := s.store.Loans().FindByID(loanID)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeLoanNotFound, "Prêt non trouvé")
+	}
+	if loan.UserID != actor.ID && !actor.IsStaff() {
+		return nil, newError(KindForbidden, apierror.CodeForbidden, ErrForbidden, "Accès non autorisé")
+	}
+	return loan, nil
+}
+
+// Return marque le retour d'un prêt et arrête son amende au jour du retour.
+// Si des adhérents attendent la ressource, l'exemplaire rendu est mis de côté pour le premier de la file.
+func (s *LoanService) Return(actor Actor, loanID uint) (*models.Loan, error) {
+	loan, err := s.find(actor, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// La transition conditionnelle empêche un double retour concurrent
+	now := s.clock.Now()
+	var returned *models.Loan
+	err = s.store.Transaction(func(tx Store) error {
+		var err error
+		if returned, err = transitionLoan(tx, loan.ID, models.LoanReturn); err != nil {
+			return transitionConflict(err, apierror.CodeLoanAlreadyReturned, "Le prêt est déjà retourné")
+		}
+
+		if _, err := s.accrueFine(tx, returned, now); err != nil {
+			return err
+		}
+
+		// Remettre l'exemplaire en service ; il est proposé au premier de la file d'attente
+		err = releaseCopy(tx, returned.CopyID, models.ResourceReturn, now, s.cfg.HoldPickupDelay)
+		return transitionConflict(err, apierror.CodeInvalidTransition, "Le statut de la ressource ne permet pas le retour")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return returned, nil
+}
+
+// Renew repousse la date de retour d'un prêt selon sa politique de prêt et enregistre le renouvellement.
+// Le renouvellement est refusé pour un prêt non renouvelable, en retard, ayant atteint le nombre maximal
+// de renouvellements, ou quand une réservation attend la ressource.
+func (s *LoanService) Renew(actor Actor, loanID uint) (*models.Loan, error) {
+	if _, err := s.find(actor, loanID); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	err := s.store.Transaction(func(tx Store) error {
+		loan, err := tx.Loans().FindByID(loanID)
+		if err != nil {
+			return notFound(err, apierror.CodeLoanNotFound, "Prêt non trouvé")
+		}
+		policy, err := s.loanPolicy(tx, loan)
+		if err != nil {
+			return err
+		}
+
+		if loan.Status == models.LoanOverdue {
+			return newError(KindConflict, apierror.CodeLoanOverdue, ErrLoanOverdue, "Le prêt est en retard et ne peut plus être renouvelé")
+		}
+		if !loan.Status.Allows(models.LoanRenew) {
+			return transitionConflict(&TransitionError{
+				Entity:    "loan",
+				Action:    string(models.LoanRenew),
+				Current:   string(loan.Status),
+				Requested: string(models.LoanRenew.Target()),
+			}, apierror.CodeLoanNotRenewable, "Seul un prêt en cours peut être renouvelé")
+		}
+		if policy.MaxRenewals == 0 || policy.RenewalDays == 0 {
+			return newError(KindConflict, apierror.CodeLoanNotRenewable, ErrNotRenewable, "Ce prêt ne peut pas être renouvelé")
+		}
+		if now.After(loan.ReturnDate) {
+			return newError(KindConflict, apierror.CodeLoanOverdue, ErrLoanOverdue, "Le prêt est en retard et ne peut plus être renouvelé")
+		}
+		if loan.RenewalCount >= policy.MaxRenewals {
+			return newError(KindConflict, apierror.CodeRenewalLimitReached, ErrRenewalLimit, "Nombre maximal de renouvellements atteint").
+				with("max_renewals", policy.MaxRenewals)
+		}
+
+		waiting, err := tx.Holds().Queue(loan.ResourceID)
+		if err != nil {
+			return err
+		}
+		if len(waiting) > 0 {
+			return newError(KindConflict, apierror.CodeHoldPending, ErrHoldPending, "La ressource est réservée par un autre adhérent")
+		}
+
+		err = tx.Loans().Renew(loan, models.LoanRenewal{
+			LoanID:             loan.ID,
+			RenewedAt:          now,
+			PreviousReturnDate: loan.ReturnDate,
+			NewReturnDate:      loan.ReturnDate.AddDate(0, 0, policy.RenewalDays),
+			RenewedByID:        actor.ID,
+		})
+		if errors.Is(err, ErrStale) {
+			// Retourné ou renouvelé entre la lecture et la mise à jour
+			if loan, err = tx.Loans().FindByID(loanID); err != nil {
+				return err
+			}
+			err = &TransitionError{Entity: "loan", Action: string(models.LoanRenew), Current: string(loan.Status), Requested: string(models.LoanRenew.Target())}
+		}
+		return transitionConflict(err, apierror.CodeLoanNotRenewable, "Seul un prêt en cours peut être renouvelé")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.store.Loans().FindByID(loanID)
+}
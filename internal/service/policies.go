@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/models"
+)
+
+var (
+	// ErrBorrowTypeNotAllowed signale un type d'emprunt interdit par la politique de prêt.
+	ErrBorrowTypeNotAllowed = errors.New("type d'emprunt non autorisé")
+	// ErrTooManyLoans signale que l'adhérent a atteint le nombre maximal de prêts simultanés.
+	ErrTooManyLoans = errors.New("nombre maximal de prêts simultanés atteint")
+	// ErrPolicyExists signale qu'une politique existe déjà pour ce type de ressource et d'emprunt.
+	ErrPolicyExists = errors.New("politique de prêt existante")
+)
+
+// DefaultPolicy retourne la politique déduite de la configuration, appliquée quand aucune politique
+// n'est enregistrée. Un prêt sur place se rend le jour même et ne se renouvelle pas.
+func DefaultPolicy(cfg config.LoanConfig, resourceType, borrowType string) models.LoanPolicy {
+	policy := models.LoanPolicy{
+		ResourceType:  resourceType,
+		BorrowType:    borrowType,
+		Allowed:       true,
+		FineDailyRate: cfg.FineDailyRate,
+		FineCap:       cfg.FineCap,
+	}
+	if borrowType == models.BorrowTakeAway {
+		policy.LoanDays = days(cfg.LoanPeriod)
+		policy.MaxRenewals = cfg.MaxRenewals
+		policy.RenewalDays = days(cfg.RenewalPeriod)
+	}
+	return policy
+}
+
+// days convertit une durée en nombre de jours entiers.
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
+
+// PolicyService regroupe la gestion des politiques de prêt enregistrées.
+// La politique applicable à un prêt est résolue par LoanService.Policy.
+type PolicyService struct {
+	store Store
+}
+
+// NewPolicyService crée le service des politiques de prêt sur le stockage fourni.
+func NewPolicyService(store Store) *PolicyService {
+	return &PolicyService{store: store}
+}
+
+// List retourne les politiques enregistrées, par type de ressource et d'emprunt.
+func (s *PolicyService) List() ([]models.LoanPolicy, error) {
+	return s.store.Policies().List()
+}
+
+// Create enregistre une nouvelle politique ; une seule politique par type de ressource et d'emprunt.
+func (s *PolicyService) Create(policy *models.LoanPolicy) error {
+	policy.ID = 0
+	return s.save(policy)
+}
+
+// Update remplace les règles de la politique policyID par celles de policy.
+func (s *PolicyService) Update(policyID uint, policy *models.LoanPolicy) error {
+	if _, err := s.store.Policies().FindByID(policyID); err != nil {
+		return notFound(err, apierror.CodePolicyNotFound, "Politique de prêt non trouvée")
+	}
+	policy.ID = policyID
+	return s.save(policy)
+}
+
+// save vérifie l'unicité de la politique par type de ressource et d'emprunt, puis l'enregistre.
+func (s *PolicyService) save(policy *models.LoanPolicy) error {
+	return s.store.Transaction(func(tx Store) error {
+		exists, err := tx.Policies().Exists(policy.ResourceType, policy.BorrowType, policy.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return newError(KindConflict, apierror.CodePolicyExists, ErrPolicyExists, "Une politique existe déjà pour ce type de ressource et d'emprunt")
+		}
+		return tx.Policies().Save(policy)
+	})
+}
+
+// Delete supprime une politique : les règles par défaut s'appliquent de nouveau.
+func (s *PolicyService) Delete(policyID uint) error {
+	return notFound(s.store.Policies().Delete(policyID), apierror.CodePolicyNotFound, "Politique de prêt non trouvée")
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"time"
+
+	"awesomeProject/internal/models"
+)
+
+// Store donne accès aux dépôts. Les services n'en connaissent que ces interfaces :
+// le stockage SQL (package repository) et le stockage en mémoire des tests sont interchangeables.
+// Les dépôts stockent et retrouvent les données ; les règles métier restent dans les services.
+type Store interface {
+	Users() UserRepository
+	Resources() ResourceRepository
+	Copies() CopyRepository
+	Holds() HoldRepository
+	Loans() LoanRepository
+	Fines() FineRepository
+	Policies() PolicyRepository
+	Tokens() TokenRepository
+
+	// Transaction exécute fn avec un Store transactionnel : si fn retourne une erreur,
+	// aucune de ses écritures n'est conservée. Une transaction imbriquée n'annule que ses propres écritures.
+	Transaction(fn func(Store) error) error
+}
+
+// UserRepository stocke les utilisateurs. Un utilisateur absent produit ErrNotFound.
+type UserRepository interface {
+	FindByID(id uint) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	List() ([]models.User, error) // Sans les mots de passe
+	Create(user *models.User) error
+	Update(user *models.User) error
+	SetRole(id uint, role string) error
+
+	// Lock verrouille l'utilisateur jusqu'à la fin de la transaction, ce qui sérialise ses demandes concurrentes.
+	Lock(id uint) error
+
+	// RevokeSessions révoque les tokens de rafraîchissement de l'utilisateur et refuse ses tokens d'accès émis avant at.
+	RevokeSessions(id uint, at time.Time) error
+}
+
+// TokenRepository stocke les tokens de rafraîchissement et les tokens d'accès révoqués avant leur expiration.
+// Un token de rafraîchissement absent produit ErrNotFound.
+type TokenRepository interface {
+	FindRefresh(hash string) (*models.RefreshToken, error)
+	CreateRefresh(token *models.RefreshToken) error
+
+	// ConsumeRefresh révoque le token de rafraîchissement s'il ne l'est pas déjà, d'un seul tenant :
+	// de deux rotations simultanées du même token, une seule aboutit, l'autre obtient ErrStale.
+	ConsumeRefresh(id uint, at time.Time) error
+	SetReplacedBy(id, replacedByID uint) error
+
+	// RevokeRefresh révoque le token de rafraîchissement de l'utilisateur ; sans effet s'il est inconnu ou déjà révoqué.
+	RevokeRefresh(userID uint, hash string, at time.Time) error
+
+	// RevokeAccess refuse le token d'accès jusqu'à son expiration et oublie les tokens expirés avant now.
+	RevokeAccess(token models.RevokedToken, now time.Time) error
+	AccessRevoked(jti string) (bool, error)
+}
+
+// ResourceRepository stocke les ressources du catalogue et leurs métadonnées.
+// Une ressource absente ou supprimée produit ErrNotFound.
+type ResourceRepository interface {
+	FindByID(id uint) (*models.Resource, error)
+	FindWithDetails(id uint) (*models.Resource, error) // Avec les exemplaires, la notice et la fiche
+	ListByIDs(ids []uint) ([]models.Resource, error)   // Avec la notice et la fiche
+
+	// Search retourne une page de ressources, avec la notice et la fiche, et le nombre total de résultats.
+	Search(q ResourceQuery) ([]models.Resource, int64, error)
+
+	// FullText retourne une page des titres correspondant à la saisie, les plus pertinents en premier,
+	// et le nombre total de correspondances. Les termes trouvés sont entourés de MarkStart et MarkEnd.
+	FullText(input, resourceType string, page, perPage int) ([]TextMatch, int64, error)
+
+	// FindDuplicate retourne la ressource de même ISBN si resource en a un, supprimée ou non, sinon
+	// la ressource de même titre (comparé selon models.TitleKey) et de même type ; 0 s'il n'y en a pas.
+	FindDuplicate(resource models.Resource) (uint, error)
+
+	// ISBNTaken indique si l'ISBN est attribué à une autre ressource que exceptID.
+	ISBNTaken(isbn string, exceptID uint) (bool, error)
+
+	Create(resource *models.Resource) error // Avec la notice et la fiche, sans les exemplaires
+	Update(resource *models.Resource) error // Titre, type, notice et fiche ; une notice ou une fiche absente est supprimée
+	SetStatus(id uint, status models.ResourceStatus) error
+
+	// Lock verrouille la ressource jusqu'à la fin de la transaction.
+	Lock(id uint) error
+
+	Delete(id uint) error // Suppression logique
+	ListDeleted() ([]models.Resource, error)
+	Restore(id uint) error // ErrNotFound si la ressource n'est pas supprimée
+
+	// Export lit l'export du catalogue ligne par ligne, par identifiant, et passe chaque ligne à fn.
+	Export(filter ResourceExportFilter, fn func(ResourceExport) error) error
+}
+
+// CopyRepository stocke les exemplaires. Un exemplaire absent produit ErrNotFound.
+type CopyRepository interface {
+	FindByID(id uint) (*models.Copy, error)
+	ListByResource(resourceID uint) ([]models.Copy, error) // Par identifiant
+	ListAvailable(resourceID uint) ([]models.Copy, error)  // Exemplaires disponibles, par identifiant
+
+	// Counts compte les exemplaires par statut pour chacune des ressources données.
+	Counts(resourceIDs []uint) (map[uint]CopyCounts, error)
+
+	// BarcodeTaken indique si le code-barres est attribué à un autre exemplaire que exceptID.
+	BarcodeTaken(barcode string, exceptID uint) (bool, error)
+
+	Create(copy *models.Copy) error
+	Update(copy *models.Copy) error // Code-barres, emplacement et état
+
+	// SetStatus passe l'exemplaire du statut from au statut to, ou retourne ErrStale si son statut n'est plus from.
+	SetStatus(id uint, from, to models.ResourceStatus) error
+}
+
+// HoldRepository stocke les réservations. Une réservation absente produit ErrNotFound.
+// Les listes suivent l'ordre de la file : date de réservation, puis identifiant.
+type HoldRepository interface {
+	FindByID(id uint) (*models.Hold, error)
+	ListByUser(userID uint) ([]models.Hold, error)
+	ListActive(resourceID uint) ([]models.Hold, error) // En attente ou prêtes
+	Queue(resourceID uint) ([]models.Hold, error)      // En attente
+
+	// FindReady retourne la réservation prête de l'utilisateur pour la ressource.
+	FindReady(userID, resourceID uint) (*models.Hold, error)
+
+	// CountActive compte les réservations en attente ou prêtes de l'utilisateur pour la ressource.
+	CountActive(userID, resourceID uint) (int, error)
+
+	// ListExpired retourne les réservations prêtes dont la date limite de retrait précède now, par date limite,
+	// de la ressource resourceID ou, si resourceID vaut 0, de toutes les ressources.
+	ListExpired(resourceID uint, now time.Time) ([]models.Hold, error)
+
+	// ResourcesToServe retourne les ressources ayant une réservation en attente et un exemplaire disponible.
+	ResourcesToServe() ([]uint, error)
+
+	Create(hold *models.Hold) error
+
+	// Update enregistre le statut et la mise à disposition de la réservation si son statut est encore from,
+	// sinon retourne ErrStale.
+	Update(hold *models.Hold, from models.HoldStatus) error
+}
+
+// LoanRepository stocke les prêts. Un prêt absent produit ErrNotFound.
+type LoanRepository interface {
+	FindByID(id uint) (*models.Loan, error)        // Avec l'historique des renouvellements
+	ListByUser(userID uint) ([]models.Loan, error) // Avec l'historique des renouvellements
+	ListDue(now time.Time) ([]models.Loan, error)  // Prêts en cours dont la date de retour précède now, par identifiant
+	ListOverdue() ([]models.Loan, error)           // Prêts en retard, par identifiant
+	Create(loan *models.Loan) error
+
+	// CountOpen compte les prêts non rendus de l'utilisateur pour un type de ressource et d'emprunt.
+	CountOpen(userID uint, resourceType, borrowType string) (int, error)
+
+	// CountOpenByResource compte les prêts non rendus de la ressource, ceux de l'utilisateur si userID n'est pas nul.
+	CountOpenByResource(resourceID, userID uint) (int, error)
+
+	// SetStatus passe le prêt du statut from au statut to, ou retourne ErrStale si son statut n'est plus from.
+	SetStatus(id uint, from, to models.LoanStatus) error
+
+	// Renew enregistre le renouvellement si le statut et le nombre de renouvellements du prêt
+	// n'ont pas changé depuis sa lecture, sinon retourne ErrStale.
+	Renew(loan *models.Loan, renewal models.LoanRenewal) error
+
+	// Export lit l'export des prêts ligne par ligne, par date de prêt, et passe chaque ligne à fn.
+	// Les prêts des ressources supprimées restent dans l'export.
+	Export(filter LoanExportFilter, fn func(LoanExport) error) error
+}
+
+// FineRepository stocke le compte d'amendes des adhérents.
+type FineRepository interface {
+	ListByUser(userID uint) ([]models.FineEntry, error) // Par date d'écriture
+	Balance(userID uint) (int, error)                   // Solde dû, en centimes
+
+	// Charge complète l'amende inscrite pour le prêt jusqu'à due et retourne le montant ajouté.
+	// L'écart est calculé et inscrit d'un seul tenant : deux calculs simultanés n'inscrivent pas deux fois la même amende.
+	Charge(loan *models.Loan, due int, now time.Time) (int, error)
+
+	Create(entry *models.FineEntry) error
+}
+
+// PolicyRepository stocke les politiques de prêt. Une politique absente produit ErrNotFound.
+type PolicyRepository interface {
+	FindByID(id uint) (*models.LoanPolicy, error)
+	Find(resourceType, borrowType string) (*models.LoanPolicy, error)
+	List() ([]models.LoanPolicy, error) // Par type de ressource et d'emprunt
+
+	// Exists indique si une autre politique que exceptID existe pour ce type de ressource et d'emprunt.
+	Exists(resourceType, borrowType string, exceptID uint) (bool, error)
+
+	Save(policy *models.LoanPolicy) error
+	Delete(id uint) error
+}
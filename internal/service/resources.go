@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/models"
+)
+
+// ResourceSortFields associe les champs de tri acceptés par l'API à leur colonne.
+var ResourceSortFields = map[string]string{
+	"id":     "id",
+	"title":  "title",
+	"type":   "type",
+	"status": "status",
+}
+
+// ResourceQuery décrit une recherche dans le catalogue. Les champs vides ne filtrent pas.
+type ResourceQuery struct {
+	Search     string                // Mots recherchés dans le titre, tous requis
+	Type       string                // "Livre" ou "Jeu"
+	Status     models.ResourceStatus // Statut de la ressource
+	Sort       string                // Clé de ResourceSortFields
+	Descending bool
+	Page       int // À partir de 1
+	PerPage    int
+
+	// Filtres sur les métadonnées des livres
+	Author   string // Partie du nom d'un auteur
+	Language string // Code ISO 639-1
+	Genre    string // Genre exact, sans tenir compte de la casse
+	Year     int
+
+	// Filtres sur les métadonnées des jeux
+	Players     int // Nombre de joueurs accepté
+	MaxPlayTime int // Durée maximale d'une partie, en minutes
+	Age         int // Âge du plus jeune joueur
+}
+
+var (
+	// ErrOpenLoans signale une ressource dont des exemplaires sont encore prêtés.
+	ErrOpenLoans = errors.New("prêts en cours sur la ressource")
+	// ErrISBNTaken signale un ISBN déjà attribué à une autre ressource.
+	ErrISBNTaken = errors.New("ISBN déjà utilisé")
+)
+
+// CatalogService regroupe les règles du catalogue : ressources, exemplaires, recherche, import et export.
+type CatalogService struct {
+	store Store
+}
+
+// NewCatalogService crée le service du catalogue sur le stockage fourni.
+func NewCatalogService(store Store) *CatalogService {
+	return &CatalogService{store: store}
+}
+
+// Search retourne une page de ressources correspondant à la recherche, avec leur nombre d'exemplaires,
+// et le nombre total de résultats. L'identifiant départage les égalités pour que la pagination reste stable.
+func (s *CatalogService) Search(q ResourceQuery) ([]models.Resource, int64, error) {
+	resources, total, err := s.store.Resources().Search(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := fillCopyCounts(s.store, resources); err != nil {
+		return nil, 0, err
+	}
+	return resources, total, nil
+}
+
+// Get retourne une ressource avec ses exemplaires, ses métadonnées et sa disponibilité.
+func (s *CatalogService) Get(id uint) (*models.Resource, error) {
+	resource, err := s.store.Resources().FindWithDetails(id)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+	}
+	resources := []models.Resource{*resource}
+	if err := fillCopyCounts(s.store, resources); err != nil {
+		return nil, err
+	}
+	return &resources[0], nil
+}
+
+// checkISBN refuse un ISBN déjà attribué à une autre ressource que exceptID.
+func checkISBN(store Store, book *models.BookDetails, exceptID uint) error {
+	if book == nil || book.ISBN == nil {
+		return nil
+	}
+	taken, err := store.Resources().ISBNTaken(*book.ISBN, exceptID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return newError(KindConflict, apierror.CodeISBNTaken, ErrISBNTaken, "Cet ISBN est déjà attribué à une autre ressource")
+	}
+	return nil
+}
+
+// Create enregistre une ressource, ses métadonnées et ses exemplaires. Sans exemplaire fourni,
+// un exemplaire unique est créé. Les exemplaires sans statut prennent celui de la ressource
+// et ceux sans code-barres reçoivent un code généré.
+func (s *CatalogService) Create(resource *models.Resource) error {
+	// Une ressource se supprime par Delete, jamais à la création
+	resource.DeletedAt.Valid = false
+
+	return s.store.Transaction(func(tx Store) error {
+		if err := checkISBN(tx, resource.Book, 0); err != nil {
+			return err
+		}
+		return createResource(tx, resource)
+	})
+}
+
+// Update remplace le titre, le type et les métadonnées de la ressource resource.ID ; une notice
+// ou une fiche absente est supprimée. Retourne la ressource à jour.
+func (s *CatalogService) Update(resource *models.Resource) (*models.Resource, error) {
+	err := s.store.Transaction(func(tx Store) error {
+		if _, err := tx.Resources().FindByID(resource.ID); err != nil {
+			return notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+		}
+		if err := checkISBN(tx, resource.Book, resource.ID); err != nil {
+			return err
+		}
+		return tx.Resources().Update(resource)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(resource.ID)
+}
+
+// Delete retire une ressource du catalogue (suppression logique). La suppression est refusée
+// tant qu'un prêt est ouvert ; les réservations actives sont annulées.
+// Les prêts rendus restent rattachés à la ressource.
+func (s *CatalogService) Delete(resourceID uint) error {
+	return s.store.Transaction(func(tx Store) error {
+		// Le verrou empêche un emprunt simultané de passer entre la vérification et la suppression
+		if err := tx.Resources().Lock(resourceID); err != nil {
+			return notFound(err, apierror.CodeResourceNotFound, "Ressource non trouvée")
+		}
+
+		open, err := tx.Loans().CountOpenByResource(resourceID, 0)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return newError(KindConflict, apierror.CodeCopiesOnLoan, ErrOpenLoans, "Des exemplaires sont encore prêtés : la ressource ne peut pas être supprimée")
+		}
+
+		if err := cancelResourceHolds(tx, resourceID); err != nil {
+			return transitionConflict(err, apierror.CodeInvalidTransition, "Changement de statut impossible")
+		}
+		return tx.Resources().Delete(resourceID)
+	})
+}
+
+// Deleted liste les ressources supprimées, les plus récentes en premier.
+func (s *CatalogService) Deleted() ([]models.Resource, error) {
+	return s.store.Resources().ListDeleted()
+}
+
+// Restore remet au catalogue une ressource supprimée et la retourne.
+func (s *CatalogService) Restore(resourceID uint) (*models.Resource, error) {
+	if err := s.store.Resources().Restore(resourceID); err != nil {
+		return nil, notFound(err, apierror.CodeResourceNotFound, "Aucune ressource supprimée avec cet ID")
+	}
+	return s.Get(resourceID)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"html"
+	"strings"
+
+	"awesomeProject/internal/models"
+)
+
+// Marqueurs des termes trouvés dans un extrait, remplacés par <mark> une fois le texte échappé.
+const (
+	MarkStart = "\x02"
+	MarkEnd   = "\x03"
+)
+
+// SearchHit est une ressource trouvée par la recherche plein texte.
+type SearchHit struct {
+	models.Resource
+	Snippet string  // Extrait HTML, termes trouvés entre <mark> et </mark>
+	Score   float64 // Pertinence : plus elle est élevée, meilleur est le résultat
+}
+
+// TextMatch est une correspondance de la recherche plein texte, telle que retournée par le dépôt.
+type TextMatch struct {
+	ID      uint
+	Snippet string // Texte brut, termes trouvés entre MarkStart et MarkEnd
+	Score   float64
+}
+
+// FullTextSearch recherche dans les titres du catalogue, les résultats les plus pertinents en premier.
+// Retourne une page de résultats et le nombre total de correspondances.
+func (s *CatalogService) FullTextSearch(input, resourceType string, page, perPage int) ([]SearchHit, int64, error) {
+	hits := []SearchHit{}
+	matches, total, err := s.store.Resources().FullText(input, resourceType, page, perPage)
+	if err != nil || len(matches) == 0 {
+		return hits, total, err
+	}
+
+	ids := make([]uint, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID
+	}
+	resources, err := s.store.Resources().ListByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := fillCopyCounts(s.store, resources); err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]models.Resource, len(resources))
+	for _, resource := range resources {
+		byID[resource.ID] = resource
+	}
+
+	highlighter := strings.NewReplacer(MarkStart, "<mark>", MarkEnd, "</mark>")
+	for _, match := range matches {
+		hits = append(hits, SearchHit{
+			Resource: byID[match.ID],
+			Snippet:  highlighter.Replace(html.EscapeString(match.Snippet)),
+			Score:    match.Score,
+		})
+	}
+	return hits, total, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// TokenPair regroupe les tokens renvoyés lors de la connexion et du rafraîchissement.
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Durée de validité du token d'accès, en secondes
+}
+
+// TokenReuseError signale la présentation d'un token de rafraîchissement déjà consommé.
+// Le token a probablement été volé : toutes les sessions de l'utilisateur ont été révoquées.
+type TokenReuseError struct {
+	UserID uint // Propriétaire du token
+}
+
+func (e *TokenReuseError) Error() string {
+	return fmt.Sprintf("token de rafraîchissement réutilisé (utilisateur %d)", e.UserID)
+}
+
+// SessionService regroupe les règles des sessions : émission, rotation et révocation des tokens.
+type SessionService struct {
+	store Store
+	cfg   config.AuthConfig
+}
+
+// NewSessionService crée le service des sessions sur le stockage fourni.
+func NewSessionService(store Store, cfg config.AuthConfig) *SessionService {
+	return &SessionService{store: store, cfg: cfg}
+}
+
+// randomToken génère une valeur aléatoire encodée en base64 URL.
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken calcule l'empreinte stockée pour un token de rafraîchissement.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// invalidRefresh est l'erreur d'un token de rafraîchissement inconnu, expiré ou réutilisé.
+func invalidRefresh(cause error) *Error {
+	return newError(KindUnauthorized, apierror.CodeRefreshTokenInvalid, cause, "Token de rafraîchissement invalide ou expiré")
+}
+
+// Issue génère un token d'accès JWT et un token de rafraîchissement pour l'utilisateur.
+func (s *SessionService) Issue(user models.User) (TokenPair, error) {
+	pair, _, err := s.issue(s.store, user)
+	return pair, err
+}
+
+// issue génère la paire de tokens et enregistre le token de rafraîchissement dans store.
+func (s *SessionService) issue(store Store, user models.User) (TokenPair, *models.RefreshToken, error) {
+	now := time.Now()
+	jti, err := randomToken(16)
+	if err != nil {
+		return TokenPair{}, nil, err
+	}
+
+	// Générer un token JWT
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    user.Role,
+		"jti":     jti,
+		"iat":     float64(now.UnixMilli()) / 1000, // à la milliseconde, comparée à la révocation des sessions
+		"exp":     now.Add(s.cfg.AccessTokenTTL).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(s.cfg.JWTSecret))
+	if err != nil {
+		return TokenPair{}, nil, err
+	}
+
+	// Générer et enregistrer le token de rafraîchissement
+	refreshString, err := randomToken(32)
+	if err != nil {
+		return TokenPair{}, nil, err
+	}
+	refresh := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshString),
+		ExpiresAt: now.Add(s.cfg.RefreshTokenTTL),
+	}
+	if err := store.Tokens().CreateRefresh(&refresh); err != nil {
+		return TokenPair{}, nil, err
+	}
+
+	return TokenPair{
+		Token:        tokenString,
+		RefreshToken: refreshString,
+		ExpiresIn:    int64(s.cfg.AccessTokenTTL.Seconds()),
+	}, &refresh, nil
+}
+
+// Refresh échange un token de rafraîchissement contre une nouvelle paire de tokens.
+// Le token présenté est révoqué (rotation) ; de deux rotations simultanées du même token, une seule aboutit.
+// Un token déjà consommé révoque toutes les sessions de l'utilisateur : l'erreur retournée
+// a alors pour cause un *TokenReuseError.
+func (s *SessionService) Refresh(refreshToken string) (TokenPair, error) {
+	var pair TokenPair
+	var reused *TokenReuseError
+	err := s.store.Transaction(func(tx Store) error {
+		stored, err := tx.Tokens().FindRefresh(hashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if stored.RevokedAt == nil && now.After(stored.ExpiresAt) {
+			return ErrNotFound
+		}
+
+		// La révocation est conditionnelle : un token déjà révoqué, éventuellement par une
+		// rotation concurrente, est une réutilisation et révoque toutes les sessions
+		err = tx.Tokens().ConsumeRefresh(stored.ID, now)
+		if errors.Is(err, ErrStale) {
+			reused = &TokenReuseError{UserID: stored.UserID}
+			return tx.Users().RevokeSessions(stored.UserID, now)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Le rôle est relu pour prendre en compte les changements récents
+		user, err := tx.Users().FindByID(stored.UserID)
+		if err != nil {
+			return err
+		}
+		newPair, newRefresh, err := s.issue(tx, *user)
+		if err != nil {
+			return err
+		}
+		if err := tx.Tokens().SetReplacedBy(stored.ID, newRefresh.ID); err != nil {
+			return err
+		}
+		pair = newPair
+		return nil
+	})
+	if err == nil && reused != nil {
+		return TokenPair{}, invalidRefresh(reused)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return TokenPair{}, invalidRefresh(err)
+	}
+	return pair, err
+}
+
+// Logout révoque le token d'accès jti jusqu'à son expiration et, s'il est fourni,
+// le token de rafraîchissement refreshToken de l'utilisateur.
+func (s *SessionService) Logout(userID uint, jti string, expiresAt time.Time, refreshToken string) error {
+	return s.store.Transaction(func(tx Store) error {
+		now := time.Now()
+		if err := tx.Tokens().RevokeAccess(models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}, now); err != nil {
+			return err
+		}
+		if refreshToken != "" {
+			return tx.Tokens().RevokeRefresh(userID, hashToken(refreshToken), now)
+		}
+		return nil
+	})
+}
+
+// Verify retourne l'utilisateur d'un token d'accès dont la signature a été vérifiée. Le token est refusé
+// s'il a été révoqué par une déconnexion, une révocation des sessions de l'utilisateur ou un changement
+// de rôle survenu depuis son émission, ou si l'utilisateur a été supprimé. Les autres erreurs du stockage
+// sont retournées telles quelles, pour ne pas déconnecter tout le monde pendant une panne.
+func (s *SessionService) Verify(jti string, userID uint, role string, issuedAt time.Time) (*models.User, error) {
+	revokedErr := newError(KindUnauthorized, apierror.CodeTokenRevoked, nil, "Token révoqué")
+	if jti == "" {
+		return nil, revokedErr
+	}
+	revoked, err := s.store.Tokens().AccessRevoked(jti)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, revokedErr
+	}
+
+	user, err := s.store.Users().FindByID(userID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, revokedErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != role {
+		return nil, revokedErr
+	}
+
+	// Les tokens portent leur date d'émission à la milliseconde : une connexion dans la même seconde
+	// qu'une révocation reste valide, un token émis avant elle est refusé.
+	if user.TokensRevokedAt != nil && !issuedAt.After(user.TokensRevokedAt.Truncate(time.Millisecond)) {
+		return nil, revokedErr
+	}
+	return user, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"awesomeProject/internal/models"
+)
+
+// TransitionError signale un changement de statut interdit par la table de transitions.
+type TransitionError struct {
+	Entity    string // "resource", "copy", "hold" ou "loan"
+	Action    string // Opération demandée, par exemple "retirer"
+	Current   string // Statut actuel
+	Requested string // Statut qu'aurait produit l'opération
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("impossible de %s (%s) : %s → %s", e.Action, e.Entity, e.Current, e.Requested)
+}
+
+// transitionCopy applique une action à un exemplaire, puis met à jour le statut de synthèse de la ressource.
+// La mise à jour est conditionnée au statut lu : une modification concurrente produit une *TransitionError
+// plutôt qu'un écrasement. Retourne ErrNotFound si l'exemplaire n'existe pas.
+func transitionCopy(store Store, copyID uint, action models.ResourceAction) (*models.Copy, error) {
+	copy, err := store.Copies().FindByID(copyID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := action.Target()
+	transitionErr := func() error {
+		return &TransitionError{Entity: "copy", Action: string(action), Current: string(copy.Status), Requested: string(to)}
+	}
+	if !copy.Status.Allows(action) {
+		return nil, transitionErr()
+	}
+
+	err = store.Copies().SetStatus(copy.ID, copy.Status, to)
+	if errors.Is(err, ErrStale) {
+		// Le statut a changé entre la lecture et la mise à jour
+		if copy, err = store.Copies().FindByID(copyID); err != nil {
+			return nil, err
+		}
+		return nil, transitionErr()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	copy.Status = to
+	if _, err := refreshStatus(store, copy.ResourceID); err != nil {
+		return nil, err
+	}
+	return copy, nil
+}
+
+// transitionLoan applique une action à un prêt, selon les mêmes règles que transitionCopy.
+func transitionLoan(store Store, loanID uint, action models.LoanAction) (*models.Loan, error) {
+	loan, err := store.Loans().FindByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := action.Target()
+	transitionErr := func() error {
+		return &TransitionError{Entity: "loan", Action: string(action), Current: string(loan.Status), Requested: string(to)}
+	}
+	if !loan.Status.Allows(action) {
+		return nil, transitionErr()
+	}
+
+	err = store.Loans().SetStatus(loan.ID, loan.Status, to)
+	if errors.Is(err, ErrStale) {
+		// Le statut a changé entre la lecture et la mise à jour
+		if loan, err = store.Loans().FindByID(loanID); err != nil {
+			return nil, err
+		}
+		return nil, transitionErr()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loan.Status = to
+	return loan, nil
+}
+
+// transitionHold applique une action à une réservation, selon les mêmes règles que transitionCopy.
+// update complète la réservation (exemplaire mis de côté, date limite) avant son enregistrement.
+func transitionHold(store Store, holdID uint, action models.HoldAction, update func(*models.Hold)) (*models.Hold, error) {
+	hold, err := store.Holds().FindByID(holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := hold.Status, action.Target()
+	transitionErr := func() error {
+		return &TransitionError{Entity: "hold", Action: string(action), Current: string(hold.Status), Requested: string(to)}
+	}
+	if !from.Allows(action) {
+		return nil, transitionErr()
+	}
+
+	hold.Status = to
+	if update != nil {
+		update(hold)
+	}
+	err = store.Holds().Update(hold, from)
+	if errors.Is(err, ErrStale) {
+		// Le statut a changé entre la lecture et la mise à jour
+		if hold, err = store.Holds().FindByID(holdID); err != nil {
+			return nil, err
+		}
+		return nil, transitionErr()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
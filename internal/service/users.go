@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrEmailTaken signale un email déjà attribué à un autre compte.
+	ErrEmailTaken = errors.New("email déjà utilisé")
+	// ErrInvalidCredentials signale un email inconnu ou un mot de passe erroné.
+	ErrInvalidCredentials = errors.New("identifiants invalides")
+	// ErrOwnRole signale un administrateur qui tente de modifier son propre rôle.
+	ErrOwnRole = errors.New("modification de son propre rôle")
+)
+
+// Actor est l'utilisateur à l'origine d'une opération, tel qu'authentifié par le token.
+type Actor struct {
+	ID   uint
+	Role string
+}
+
+// IsStaff indique si l'utilisateur fait partie du personnel.
+func (a Actor) IsStaff() bool {
+	return models.IsStaffRole(a.Role)
+}
+
+// UserService regroupe les règles des comptes utilisateurs.
+type UserService struct {
+	store Store
+}
+
+// NewUserService crée le service des comptes sur le stockage fourni.
+func NewUserService(store Store) *UserService {
+	return &UserService{store: store}
+}
+
+// Register crée le compte d'un adhérent. Les rôles du personnel sont attribués par un administrateur.
+func (s *UserService) Register(name, email, password string) (*models.User, error) {
+	if _, err := s.store.Users().FindByEmail(email); err == nil {
+		return nil, newError(KindConflict, apierror.CodeEmailTaken, ErrEmailTaken, "Utilisateur existant")
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		Name:     name,
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     models.RoleMember,
+	}
+	if err := s.store.Users().Create(user); err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// Authenticate vérifie l'email et le mot de passe d'un utilisateur et retourne son compte.
+// Un email inconnu et un mot de passe erroné produisent la même erreur.
+func (s *UserService) Authenticate(email, password string) (*models.User, error) {
+	user, err := s.store.Users().FindByEmail(email)
+	if errors.Is(err, ErrNotFound) {
+		return nil, newError(KindUnauthorized, apierror.CodeInvalidCredentials, ErrInvalidCredentials, "Email ou mot de passe invalide")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, newError(KindUnauthorized, apierror.CodeInvalidCredentials, ErrInvalidCredentials, "Email ou mot de passe invalide")
+	}
+	return user, nil
+}
+
+// Profile retourne le compte de l'utilisateur, sans son mot de passe.
+func (s *UserService) Profile(userID uint) (*models.User, error) {
+	user, err := s.store.Users().FindByID(userID)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeUserNotFound, "Utilisateur non trouvé")
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// UpdateProfile modifie le nom et l'email de l'utilisateur, ainsi que sa langue si language est fourni :
+// "fr" ou "en", ou vide pour suivre l'en-tête Accept-Language.
+func (s *UserService) UpdateProfile(userID uint, name, email string, language *string) (*models.User, error) {
+	if language != nil && *language != "" {
+		lang, ok := i18n.Parse(*language)
+		if !ok {
+			return nil, newError(KindInvalid, apierror.CodeValidationFailed, nil, "Langue invalide").with("field", "language")
+		}
+		normalized := string(lang)
+		language = &normalized
+	}
+	user, err := s.store.Users().FindByID(userID)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeUserNotFound, "Utilisateur non trouvé")
+	}
+	if other, err := s.store.Users().FindByEmail(email); err == nil && other.ID != userID {
+		return nil, newError(KindConflict, apierror.CodeEmailTaken, ErrEmailTaken, "Email déjà utilisé")
+	} else if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	user.Name = name
+	user.Email = email
+	if language != nil {
+		user.Language = *language
+	}
+	if err := s.store.Users().Update(user); err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// List retourne tous les comptes, sans les mots de passe.
+func (s *UserService) List() ([]models.User, error) {
+	return s.store.Users().List()
+}
+
+// ChangeRole promeut ou rétrograde un utilisateur. Un administrateur ne peut pas modifier
+// son propre rôle, ce qui évite de perdre le dernier accès administrateur.
+func (s *UserService) ChangeRole(actor Actor, userID uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, newError(KindInvalid, apierror.CodeValidationFailed, nil, "Rôle invalide").with("field", "role")
+	}
+	if actor.ID == userID {
+		return nil, newError(KindConflict, apierror.CodeOwnRole, ErrOwnRole, "Impossible de modifier son propre rôle")
+	}
+
+	user, err := s.store.Users().FindByID(userID)
+	if err != nil {
+		return nil, notFound(err, apierror.CodeUserNotFound, "Utilisateur non trouvé")
+	}
+	if err := s.store.Users().SetRole(user.ID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	user.Password = ""
+	return user, nil
+}
+
+// RevokeSessions révoque immédiatement toutes les sessions d'un utilisateur : ses tokens de rafraîchissement
+// et les tokens d'accès déjà émis.
+func (s *UserService) RevokeSessions(userID uint) error {
+	if _, err := s.store.Users().FindByID(userID); err != nil {
+		return notFound(err, apierror.CodeUserNotFound, "Utilisateur non trouvé")
+	}
+	return s.store.Transaction(func(tx Store) error {
+		return tx.Users().RevokeSessions(userID, time.Now())
+	})
+}
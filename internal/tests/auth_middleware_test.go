@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/middleware"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// signToken génère un token JWT avec les claims et la méthode de signature fournis.
+func signToken(t *testing.T, method jwt.SigningMethod, claims jwt.MapClaims, key interface{}) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("Erreur lors de la signature du token: %v", err)
+	}
+	return token
+}
+
+// createTestUser crée un utilisateur de test avec le rôle donné.
+func createTestUser(t *testing.T, db *gorm.DB, email, role string) models.User {
+	user := models.User{Name: "Test " + role, Email: email, Password: "x", Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("Erreur lors de la création de l'utilisateur: %v", err)
+	}
+	return user
+}
+
+// validClaims retourne des claims valides pour l'utilisateur donné.
+func validClaims(user models.User) jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id": user.ID,
+		"role":    user.Role,
+		"jti":     fmt.Sprintf("test-%d-%d", user.ID, time.Now().UnixNano()),
+		"iat":     time.Now().Add(-time.Second).Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// bearerToken retourne un en-tête Authorization valide pour l'utilisateur donné.
+func bearerToken(t *testing.T, user models.User) string {
+	return "Bearer " + signToken(t, jwt.SigningMethodHS256, validClaims(user), []byte(testConfig.Auth.JWTSecret))
+}
+
+// sendJSON envoie une requête au routeur, avec payload encodé en JSON s'il n'est pas nil
+// et l'en-tête Authorization fourni s'il n'est pas vide.
+func sendJSON(t *testing.T, router http.Handler, authorization, method, path string, payload interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	if payload != nil {
+		assert.NoError(t, json.NewEncoder(&body).Encode(payload))
+	}
+	req, _ := http.NewRequest(method, path, &body)
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// sendAs envoie une requête JSON au routeur avec un token valide de l'utilisateur donné.
+func sendAs(t *testing.T, router http.Handler, user models.User, method, path string, payload interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return sendJSON(t, router, bearerToken(t, user), method, path, payload)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Errors())
+	r.GET("/protected", middleware.AuthRequired([]byte(testConfig.Auth.JWTSecret), service.NewSessionService(repository.NewGormStore(db), testConfig.Auth)), func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		c.JSON(http.StatusOK, gin.H{"user_id": userID, "role": c.GetString("userRole")})
+	})
+
+	// Définir une fonction helper pour tester un en-tête Authorization
+	testHeader := func(name, header string, expectedStatus int) {
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, expectedStatus, w.Code, "Erreur sur le cas %s", name)
+	}
+
+	user := createTestUser(t, db, "middleware@example.com", models.RoleLibrarian)
+
+	// Token valide : l'ID utilisateur est injecté dans le contexte
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", bearerToken(t, user))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"user_id": %d, "role": "librarian"}`, user.ID), w.Body.String())
+
+	// withClaims modifie des claims valides pour construire un cas de refus
+	withClaims := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := validClaims(user)
+		for key, value := range changes {
+			if value == nil {
+				delete(claims, key)
+			} else {
+				claims[key] = value
+			}
+		}
+		return claims
+	}
+	signed := func(changes jwt.MapClaims) string {
+		return "Bearer " + signToken(t, jwt.SigningMethodHS256, withClaims(changes), []byte(testConfig.Auth.JWTSecret))
+	}
+
+	// Cas de refus
+	testHeader("sans en-tête", "", http.StatusUnauthorized)
+	testHeader("sans préfixe Bearer", signToken(t, jwt.SigningMethodHS256, validClaims(user), []byte(testConfig.Auth.JWTSecret)), http.StatusUnauthorized)
+	testHeader("mauvais secret", "Bearer "+signToken(t, jwt.SigningMethodHS256, validClaims(user), []byte("autre_secret")), http.StatusUnauthorized)
+	testHeader("token expiré", signed(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), http.StatusUnauthorized)
+	testHeader("sans expiration", signed(jwt.MapClaims{"exp": nil}), http.StatusUnauthorized)
+	testHeader("algorithme HS512", "Bearer "+signToken(t, jwt.SigningMethodHS512, validClaims(user), []byte(testConfig.Auth.JWTSecret)), http.StatusUnauthorized)
+	testHeader("algorithme none", "Bearer "+signToken(t, jwt.SigningMethodNone, validClaims(user), jwt.UnsafeAllowNoneSignatureType), http.StatusUnauthorized)
+	testHeader("rôle inconnu", signed(jwt.MapClaims{"role": "superuser"}), http.StatusUnauthorized)
+	testHeader("rôle modifié depuis l'émission", signed(jwt.MapClaims{"role": models.RoleAdmin}), http.StatusUnauthorized)
+	testHeader("sans user_id", signed(jwt.MapClaims{"user_id": nil}), http.StatusUnauthorized)
+	testHeader("utilisateur inexistant", signed(jwt.MapClaims{"user_id": 999999}), http.StatusUnauthorized)
+	testHeader("sans jti", signed(jwt.MapClaims{"jti": nil}), http.StatusUnauthorized)
+
+	// Révocation des sessions : seuls les tokens émis après elle sont acceptés, même dans la même seconde
+	revokedAt := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	assert.NoError(t, db.Model(&user).Update("tokens_revoked_at", revokedAt).Error)
+	testHeader("émis avant la révocation", signed(jwt.MapClaims{"iat": float64(revokedAt.UnixMilli()-100) / 1000}), http.StatusUnauthorized)
+	testHeader("émis dans la seconde de la révocation", signed(jwt.MapClaims{"iat": float64(revokedAt.UnixMilli()+100) / 1000}), http.StatusOK)
+
+	// Une panne de la base n'est pas un token révoqué
+	outage := false
+	assert.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:outage", func(tx *gorm.DB) {
+		if outage {
+			_ = tx.AddError(errors.New("base indisponible"))
+		}
+	}))
+	outage = true
+	testHeader("base indisponible", signed(jwt.MapClaims{"iat": float64(revokedAt.UnixMilli()+100) / 1000}), http.StatusInternalServerError)
+}
@@ -7,47 +7,48 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"awesomeProject/internal/database"
 	"awesomeProject/internal/handlers"
+	"awesomeProject/internal/middleware"
 	"awesomeProject/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 // setupRouterForAuth configure un routeur avec les endpoints Register et Login.
-func setupRouterForAuth() *gin.Engine {
-	r := gin.Default()
-	r.POST("/register", handlers.RegisterUser)
-	r.POST("/login", handlers.LoginUser)
+func setupRouterForAuth(db *gorm.DB) *gin.Engine {
+	auth := handlers.NewAuthHandler(testConfig.Auth, db)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Errors())
+	r.POST("/register", auth.RegisterUser)
+	r.POST("/login", auth.LoginUser)
 	return r
 }
 
 // setupRouterForProfile configure un routeur pour les endpoints de profil.
 // On simule ici l'authentification en injectant l'ID utilisateur dans le contexte.
-func setupRouterForProfile(userID uint) *gin.Engine {
-	r := gin.Default()
+func setupRouterForProfile(db *gorm.DB, userID uint) *gin.Engine {
+	users := handlers.NewUserHandler(db)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Errors())
 	r.GET("/profile", func(c *gin.Context) {
 		c.Set("userID", userID)
-		handlers.GetProfile(c)
+		users.GetProfile(c)
 	})
 	r.PUT("/profile", func(c *gin.Context) {
 		c.Set("userID", userID)
-		handlers.UpdateProfile(c)
+		users.UpdateProfile(c)
 	})
 	return r
 }
 
 func TestAuthAndProfileEndpoints(t *testing.T) {
-	// Initialisation de la base de données pour les tests
-	database.InitDB()
-	// Migration du modèle User (on peut également migrer les autres modèles si nécessaire)
-	database.DB.AutoMigrate(&models.User{})
-	// Nettoyage de la table des utilisateurs pour obtenir un environnement propre
-	database.DB.Exec("DELETE FROM users")
-	defer database.CloseDB()
+	t.Parallel()
+	// Chaque test dispose de sa propre base en mémoire
+	db := newTestDB(t)
 
 	// --- Test de l'inscription (RegisterUser) ---
-	routerAuth := setupRouterForAuth()
+	routerAuth := setupRouterForAuth(db)
 	registerPayload := map[string]interface{}{
 		"name":     "Test User",
 		"email":    "test@example.com",
@@ -98,7 +99,7 @@ func TestAuthAndProfileEndpoints(t *testing.T) {
 	assert.NotEmpty(t, token)
 
 	// --- Test de la récupération du profil (GetProfile) ---
-	routerProfile := setupRouterForProfile(testUserID)
+	routerProfile := setupRouterForProfile(db, testUserID)
 	reqGetProfile, _ := http.NewRequest("GET", "/profile", nil)
 	wGetProfile := httptest.NewRecorder()
 	routerProfile.ServeHTTP(wGetProfile, reqGetProfile)
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigLoad(t *testing.T) {
+	// Sans fichier ni variable d'environnement, la configuration de développement est utilisée
+	t.Setenv("CONFIG_FILE", "")
+	cfg, err := config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, config.Default(), cfg)
+
+	// Le fichier YAML est surchargé par les variables d'environnement
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+env: development
+server:
+  addr: ":9090"
+database:
+  dsn: "file:test.db"
+auth:
+  access_token_ttl: 5m
+cors:
+  allow_origins: ["https://bibliotheque.example.com"]
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "3000")
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("LOAN_MAX_RENEWALS", "0")
+	t.Setenv("DATABASE_DRIVER", "postgres")
+	t.Setenv("LOG_EVENT_LEVEL", "debug")
+
+	cfg, err = config.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, ":3000", cfg.Server.Addr)
+	assert.Equal(t, config.DriverPostgres, cfg.Database.Driver)
+	assert.Equal(t, "file:test.db", cfg.Database.DSN)
+	assert.Equal(t, 5*time.Minute, cfg.Auth.AccessTokenTTL)
+	assert.Equal(t, 30*24*time.Hour, cfg.Auth.RefreshTokenTTL)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowOrigins)
+	assert.Equal(t, 0, cfg.Loans.MaxRenewals)
+	assert.Equal(t, "debug", cfg.Log.EventLevel)
+	assert.Equal(t, "info", cfg.Log.Level)
+
+	// Une clé inconnue dans le fichier est refusée
+	assert.NoError(t, os.WriteFile(path, []byte("unknown_key: 1\n"), 0o600))
+	_, err = config.Load()
+	assert.Error(t, err)
+}
+
+func TestConfigValidate(t *testing.T) {
+	// Définir une fonction helper pour valider une configuration modifiée
+	validate := func(change func(cfg *config.Config)) error {
+		cfg := config.Default()
+		change(cfg)
+		return cfg.Validate()
+	}
+
+	assert.NoError(t, validate(func(cfg *config.Config) {}))
+
+	// Le secret par défaut est interdit en production
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Env = config.EnvProduction }))
+	assert.Error(t, validate(func(cfg *config.Config) {
+		cfg.Env = config.EnvProduction
+		cfg.Auth.JWTSecret = "trop-court"
+	}))
+	assert.NoError(t, validate(func(cfg *config.Config) {
+		cfg.Env = config.EnvProduction
+		cfg.Auth.JWTSecret = "un-secret-de-production-suffisamment-long"
+	}))
+
+	// Valeurs invalides
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Env = "staging" }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Server.Addr = "" }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Database.DSN = "" }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Database.Driver = "mysql" }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Auth.AccessTokenTTL = 0 }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Auth.RefreshTokenTTL = time.Minute }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Loans.HoldPickupDelay = 0 }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Loans.RenewalPeriod = -time.Hour }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Loans.RenewalPeriod = 12 * time.Hour }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Loans.MaxRenewals = -1 }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Loans.FineCap = -1 }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Loans.OverdueInterval = 0 }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.CORS.AllowOrigins = nil }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.CORS.AllowOrigins = []string{"*"} }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Log.Level = "verbose" }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Log.EventLevel = "" }))
+	assert.Error(t, validate(func(cfg *config.Config) { cfg.Log.Format = "xml" }))
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// createTestResource crée directement en base une ressource disponible avec le nombre d'exemplaires demandé.
+func createTestResource(t *testing.T, db *gorm.DB, title, resourceType string, copies int) models.Resource {
+	t.Helper()
+	resource := models.Resource{Title: title, Type: resourceType, Status: models.ResourceAvailable, Copies: make([]models.Copy, copies)}
+	if err := service.NewCatalogService(repository.NewGormStore(db)).Create(&resource); err != nil {
+		t.Fatalf("création de la ressource de test: %v", err)
+	}
+	return resource
+}
+
+func TestResourceCopies(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	librarian := createTestUser(t, db, "copies.librarian@example.com", models.RoleLibrarian)
+	fetch := func(id uint) models.Resource {
+		w := sendAs(t, router, librarian, "GET", "/api/resources/"+strconv.Itoa(int(id)), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resource models.Resource
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resource))
+		return resource
+	}
+
+	// Création d'un titre avec trois exemplaires
+	w := sendAs(t, router, librarian, "POST", "/api/resources", gin.H{
+		"Title": "Les Aventuriers du Rail",
+		"Type":  "Jeu",
+		"Copies": []gin.H{
+			{"Location": "Étagère A"},
+			{"Location": "Étagère A", "Condition": models.ConditionWorn},
+			{"Location": "Réserve", "Barcode": "JEU-RAIL-3"},
+		},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Resource
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	resource := fetch(created.ID)
+	if !assert.Len(t, resource.Copies, 3) {
+		return
+	}
+	assert.Equal(t, 3, resource.TotalCopies)
+	assert.Equal(t, 3, resource.AvailableCopies)
+	assert.Equal(t, "JEU-RAIL-3", resource.Copies[2].Barcode)
+	assert.NotEmpty(t, resource.Copies[0].Barcode)
+
+	// Un état inconnu ou un code-barres déjà attribué sont refusés
+	copiesPath := "/api/resources/" + strconv.Itoa(int(created.ID)) + "/copies"
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, librarian, "POST", copiesPath, gin.H{"condition": "cassé"}).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "POST", copiesPath, gin.H{"barcode": "JEU-RAIL-3"}).Code)
+
+	// Deux emprunts : il reste un exemplaire, le titre est toujours disponible
+	loan := gin.H{"resource_id": created.ID, "borrow_type": "a_emporter"}
+	assert.Equal(t, http.StatusCreated, sendAs(t, router, librarian, "POST", "/api/loans", loan).Code)
+	w = sendAs(t, router, librarian, "POST", "/api/loans", loan)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var secondLoan models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &secondLoan))
+	assert.NotZero(t, secondLoan.CopyID)
+	resource = fetch(created.ID)
+	assert.Equal(t, 1, resource.AvailableCopies)
+	assert.Equal(t, models.ResourceAvailable, resource.Status)
+
+	// Le dernier exemplaire est retiré : plus rien à emprunter
+	last := resource.Copies[2]
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PUT", "/api/copies/"+strconv.Itoa(int(last.ID))+"/disable", nil).Code)
+	resource = fetch(created.ID)
+	assert.Equal(t, 0, resource.AvailableCopies)
+	assert.Equal(t, models.ResourceBorrowed, resource.Status)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "POST", "/api/loans", loan).Code)
+
+	// Un exemplaire emprunté ne peut pas être demandé explicitement
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "POST", "/api/loans", gin.H{"resource_id": created.ID, "copy_id": secondLoan.CopyID, "borrow_type": "sur_place"}).Code)
+
+	// Le retour libère l'exemplaire prêté
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PUT", "/api/loans/"+strconv.Itoa(int(secondLoan.ID))+"/return", nil).Code)
+	resource = fetch(created.ID)
+	assert.Equal(t, 1, resource.AvailableCopies)
+	assert.Equal(t, models.ResourceAvailable, resource.Status)
+
+	// Modification de l'emplacement et de l'état d'un exemplaire
+	w = sendAs(t, router, librarian, "PUT", "/api/copies/"+strconv.Itoa(int(last.ID)), gin.H{"location": "Vitrine", "condition": models.ConditionDamaged})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var updated models.Copy
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "Vitrine", updated.Location)
+	assert.Equal(t, models.ConditionDamaged, updated.Condition)
+	assert.Equal(t, models.ResourceUnavailable, updated.Status)
+
+	// Un exemplaire d'une autre ressource ne peut pas être emprunté à sa place
+	other := createTestResource(t, db, "Autre titre", "Livre", 1)
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, librarian, "POST", "/api/loans", gin.H{"resource_id": other.ID, "copy_id": last.ID, "borrow_type": "sur_place"}).Code)
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/middleware"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// errorEnvelope reproduit l'enveloppe JSON des réponses en erreur.
+type errorEnvelope struct {
+	Error struct {
+		Code      apierror.Code          `json:"code"`
+		Message   string                 `json:"message"`
+		Fields    []apierror.FieldError  `json:"fields"`
+		Details   map[string]interface{} `json:"details"`
+		RequestID string                 `json:"request_id"`
+	} `json:"error"`
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	send := func(method, path, body string, header http.Header) (*httptest.ResponseRecorder, errorEnvelope) {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, values := range header {
+			req.Header.Set(key, values[0])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var envelope errorEnvelope
+		if w.Code >= 400 {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope), "Corps invalide pour %s %s", method, path)
+		}
+		return w, envelope
+	}
+
+	// Les règles de validation non respectées sont détaillées champ par champ, sous leur nom JSON
+	w, envelope := send("POST", "/api/register", `{"name": "Alice", "email": "alice", "password": "123"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.CodeValidationFailed, envelope.Error.Code)
+	assert.NotEmpty(t, envelope.Error.Message)
+	assert.ElementsMatch(t, []string{"email", "password"}, []string{envelope.Error.Fields[0].Field, envelope.Error.Fields[1].Field})
+	assert.Equal(t, "email", envelope.Error.Fields[0].Rule)
+	assert.Equal(t, "min", envelope.Error.Fields[1].Rule)
+
+	// Un JSON illisible produit un code distinct
+	w, envelope = send("POST", "/api/login", `{`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.CodeMalformedBody, envelope.Error.Code)
+
+	// L'identifiant de requête fourni par le client est repris dans l'en-tête et dans l'enveloppe
+	w, envelope = send("GET", "/api/profile", "", http.Header{middleware.RequestIDHeader: {"req-42"}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, apierror.CodeTokenMissing, envelope.Error.Code)
+	assert.Equal(t, "req-42", envelope.Error.RequestID)
+	assert.Equal(t, "req-42", w.Header().Get(middleware.RequestIDHeader))
+
+	// À défaut, un identifiant est généré
+	w, envelope = send("GET", "/api/search", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.CodeValidationFailed, envelope.Error.Code)
+	assert.Equal(t, "q", envelope.Error.Fields[0].Field)
+	assert.NotEmpty(t, envelope.Error.RequestID)
+	assert.Equal(t, envelope.Error.RequestID, w.Header().Get(middleware.RequestIDHeader))
+
+	// Les règles propres au domaine désignent aussi le champ refusé
+	librarian := createTestUser(t, db, "envelope.librarian@example.com", models.RoleLibrarian)
+	w, envelope = send("POST", "/api/resources", `{"Title": "Dune", "Type": "Livre", "Book": {"ISBN": "9782253004227"}}`, http.Header{"Authorization": {bearerToken(t, librarian)}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.CodeValidationFailed, envelope.Error.Code)
+	if assert.Len(t, envelope.Error.Fields, 1) {
+		assert.Equal(t, "Book.ISBN", envelope.Error.Fields[0].Field)
+		assert.Equal(t, "isbn13", envelope.Error.Fields[0].Rule)
+		assert.Equal(t, envelope.Error.Message, envelope.Error.Fields[0].Message)
+	}
+	admin := createTestUser(t, db, "envelope.admin@example.com", models.RoleAdmin)
+	w, envelope = send("POST", "/api/admin/loan-policies", `{"resource_type": "Livre", "borrow_type": "a_emporter", "max_renewals": 2}`, http.Header{"Authorization": {bearerToken(t, admin)}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	if assert.Len(t, envelope.Error.Fields, 1) {
+		assert.Equal(t, "renewal_days", envelope.Error.Fields[0].Field)
+		assert.Equal(t, "required_if", envelope.Error.Fields[0].Rule)
+	}
+
+	// Un paramètre de requête invalide est désigné par son nom
+	w, envelope = send("GET", "/api/resources?per_page=1000", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apierror.CodeInvalidParameter, envelope.Error.Code)
+	assert.Equal(t, "per_page", envelope.Error.Fields[0].Field)
+
+	// Les erreurs du domaine conservent leur code
+	member := createTestUser(t, db, "envelope.member@example.com", models.RoleMember)
+	w, envelope = send("POST", "/api/loans", `{"resource_id": 999, "borrow_type": "sur_place"}`, http.Header{"Authorization": {bearerToken(t, member)}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, apierror.CodeResourceNotFound, envelope.Error.Code)
+
+	// Une route d'API inconnue répond en JSON
+	w, envelope = send("GET", "/api/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, apierror.CodeRouteNotFound, envelope.Error.Code)
+}
+
+func TestErrorEnvelopeRecovery(t *testing.T) {
+	t.Parallel()
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Errors(), middleware.Recovery())
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var envelope errorEnvelope
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, apierror.CodeInternal, envelope.Error.Code)
+	assert.NotContains(t, w.Body.String(), "boom")
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExports(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	member := createTestUser(t, db, "export.member@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "export.librarian@example.com", models.RoleLibrarian)
+
+	readCSV := func(w *httptest.ResponseRecorder, comma rune) [][]string {
+		body := w.Body.Bytes()
+		assert.True(t, bytes.HasPrefix(body, []byte("\xef\xbb\xbf")), "BOM UTF-8 attendu")
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))))
+		reader.Comma = comma
+		records, err := reader.ReadAll()
+		assert.NoError(t, err)
+		return records
+	}
+
+	book := createTestResource(t, db, "Qzexport Livre", "Livre", 2)
+	assert.NoError(t, db.Create(&models.BookDetails{ResourceID: book.ID, Authors: []string{"Une", "Deux"}, Year: 1999}).Error)
+	game := createTestResource(t, db, "Qzexport Jeu", "Jeu", 1)
+
+	// Prêts de l'adhérent à des dates connues
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 10, 0, 0, 0, time.Local) }
+	for _, loan := range []models.Loan{
+		{UserID: member.ID, ResourceID: book.ID, CopyID: book.Copies[0].ID, LoanDate: day(1), ReturnDate: day(15), Status: models.LoanReturned},
+		{UserID: member.ID, ResourceID: game.ID, CopyID: game.Copies[0].ID, LoanDate: day(10), ReturnDate: day(24), Status: models.LoanReturned},
+		{UserID: member.ID, ResourceID: book.ID, CopyID: book.Copies[1].ID, LoanDate: day(31), ReturnDate: day(31).AddDate(0, 0, 14), Status: models.LoanReturned},
+	} {
+		assert.NoError(t, db.Create(&loan).Error)
+	}
+
+	// Réservé au personnel
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "GET", "/api/export/resources", nil).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "GET", "/api/export/loans", nil).Code)
+
+	// Paramètres invalides
+	for _, path := range []string{
+		"/api/export/resources?format=xml",
+		"/api/export/resources?status=perdu",
+		"/api/export/loans?delimiter=tab",
+		"/api/export/loans?from=01/03/2024",
+		"/api/export/loans?from=2024-03-10&to=2024-03-01",
+		"/api/export/loans?user=abc",
+	} {
+		assert.Equal(t, http.StatusBadRequest, sendAs(t, router, librarian, "GET", path, nil).Code, path)
+	}
+
+	// Catalogue en CSV : en-tête, compteurs d'exemplaires et listes séparées par "|"
+	w := sendAs(t, router, librarian, "GET", "/api/export/resources?type=Livre", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	records := readCSV(w, ',')
+	if assert.NotEmpty(t, records) {
+		assert.Equal(t, "id", records[0][0])
+	}
+	var found []string
+	for _, record := range records[1:] {
+		assert.Equal(t, "Livre", record[2])
+		if record[0] == strconv.Itoa(int(book.ID)) {
+			found = record
+		}
+	}
+	if assert.NotNil(t, found, "livre absent de l'export") {
+		assert.Equal(t, []string{"Qzexport Livre", "2", "2", "Une|Deux", "1999"}, []string{found[1], found[4], found[5], found[6], found[8]})
+	}
+
+	// Catalogue en JSON
+	w = sendAs(t, router, librarian, "GET", "/api/export/resources?format=json&type=Jeu", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resources []service.ResourceExport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resources))
+	ids := map[uint]bool{}
+	for _, r := range resources {
+		assert.Equal(t, "Jeu", r.Type)
+		ids[r.ID] = true
+	}
+	assert.True(t, ids[game.ID])
+	assert.False(t, ids[book.ID])
+
+	// Prêts d'un adhérent sur une période, bornes incluses, avec le séparateur point-virgule
+	userPath := "/api/export/loans?user=" + strconv.Itoa(int(member.ID))
+	w = sendAs(t, router, librarian, "GET", userPath+"&from=2024-03-01&to=2024-03-10&delimiter=semicolon", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	records = readCSV(w, ';')
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, "2024-03-01 10:00:00", records[1][1])
+		assert.Equal(t, "Qzexport Livre", records[1][10])
+		assert.Equal(t, "Qzexport Jeu", records[2][10])
+		assert.Equal(t, member.Email, records[2][8])
+	}
+
+	// Prêts en JSON filtrés par type de ressource
+	w = sendAs(t, router, librarian, "GET", userPath+"&format=json&type=Livre", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var loans []service.LoanExport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loans))
+	if assert.Len(t, loans, 2) {
+		assert.True(t, loans[0].LoanDate.Equal(day(1)))
+		assert.Equal(t, "retourné", loans[1].Status)
+	}
+
+	// Les cellules qu'un tableur exécuterait comme formules sont préfixées d'une apostrophe en CSV
+	attacker := createTestUser(t, db, "export.formule@example.com", models.RoleMember)
+	assert.NoError(t, db.Model(&attacker).Update("name", `=HYPERLINK("http://evil.example","x")`).Error)
+	formula := createTestResource(t, db, "+Qzexport formule", "Jeu", 1)
+	assert.NoError(t, db.Create(&models.Loan{UserID: attacker.ID, ResourceID: formula.ID, CopyID: formula.Copies[0].ID,
+		LoanDate: day(5), ReturnDate: day(19), Status: models.LoanReturned}).Error)
+	attackerPath := "/api/export/loans?user=" + strconv.Itoa(int(attacker.ID))
+	records = readCSV(sendAs(t, router, librarian, "GET", attackerPath, nil), ',')
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, `'=HYPERLINK("http://evil.example","x")`, records[1][7])
+		assert.Equal(t, "'+Qzexport formule", records[1][10])
+	}
+	w = sendAs(t, router, librarian, "GET", attackerPath+"&format=json", nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loans))
+	if assert.Len(t, loans, 1) {
+		assert.Equal(t, "+Qzexport formule", loans[0].ResourceTitle)
+	}
+
+	// Aucun prêt en cours pour cet adhérent : l'export JSON est un tableau vide
+	w = sendAs(t, router, librarian, "GET", userPath+"&format=json&status=en_cours", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/jobs"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLateFineCalculation(t *testing.T) {
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 0, service.LateFine(due, due, 20, 1000))
+	assert.Equal(t, 0, service.LateFine(due, due.Add(-time.Hour), 20, 1000))
+	assert.Equal(t, 20, service.LateFine(due, due.Add(time.Minute), 20, 1000)) // Jour entamé
+	assert.Equal(t, 20, service.LateFine(due, due.Add(24*time.Hour), 20, 1000))
+	assert.Equal(t, 40, service.LateFine(due, due.Add(25*time.Hour), 20, 1000))
+	assert.Equal(t, 1000, service.LateFine(due, due.Add(365*24*time.Hour), 20, 1000)) // Plafond
+}
+
+func TestOverdueFinesAndBorrowingBlock(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	// Horloge contrôlée : les jours de retard s'écoulent sans attendre
+	clk := clock.NewFake(time.Now())
+	router := routes.NewRouter(testConfig, db, clk, logging.Discard())
+	job := jobs.NewOverdueJob(db, clk, testConfig.Loans, logging.Discard())
+	rules := testConfig.Loans
+	balanceOf := func(user models.User) int {
+		balance, err := repository.NewGormStore(db).Fines().Balance(user.ID)
+		assert.NoError(t, err)
+		return balance
+	}
+
+	member := createTestUser(t, db, "fines.member@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "fines.librarian@example.com", models.RoleLibrarian)
+
+	w := sendAs(t, router, member, "POST", "/api/loans", gin.H{"resource_id": createTestResource(t, db, "Le Horla", "Livre", 1).ID, "borrow_type": "a_emporter"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var loan models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loan))
+	loanStatus := func() models.LoanStatus {
+		var stored models.Loan
+		assert.NoError(t, db.First(&stored, loan.ID).Error)
+		return stored.Status
+	}
+
+	// Avant l'échéance, la tâche ne signale rien pour ce prêt
+	_, err := job.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, models.LoanActive, loanStatus())
+	assert.Zero(t, balanceOf(member))
+
+	// Une heure après l'échéance : prêt en retard et un jour d'amende
+	clk.Set(loan.ReturnDate.Add(time.Hour))
+	_, err = job.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, models.LoanOverdue, loanStatus())
+	assert.Equal(t, rules.FineDailyRate, balanceOf(member))
+
+	// Un second passage au même instant n'ajoute rien
+	_, err = job.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, rules.FineDailyRate, balanceOf(member))
+
+	// Le retard ne se renouvelle pas
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", "/api/loans/"+strconv.Itoa(int(loan.ID))+"/renew", nil).Code)
+
+	// Au-delà du seuil, les nouveaux emprunts sont bloqués
+	days := rules.FineBlockThreshold/rules.FineDailyRate + 1
+	clk.Set(loan.ReturnDate.Add(time.Duration(days)*24*time.Hour - time.Hour))
+	_, err = job.RunOnce()
+	assert.NoError(t, err)
+	owed := min(days*rules.FineDailyRate, rules.FineCap)
+	assert.Equal(t, owed, balanceOf(member))
+	other := createTestResource(t, db, "Bel-Ami", "Livre", 1)
+	w = sendAs(t, router, member, "POST", "/api/loans", gin.H{"resource_id": other.ID, "borrow_type": "sur_place"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// L'amende est plafonnée
+	clk.Advance(365 * 24 * time.Hour)
+	_, err = job.RunOnce()
+	assert.NoError(t, err)
+	assert.Equal(t, rules.FineCap, balanceOf(member))
+
+	// Le retour arrête l'amende
+	assert.Equal(t, http.StatusOK, sendAs(t, router, member, "PUT", "/api/loans/"+strconv.Itoa(int(loan.ID))+"/return", nil).Code)
+	assert.Equal(t, models.LoanReturned, loanStatus())
+
+	// Seul le personnel saisit paiements et remises, sans dépasser le solde
+	paymentsPath := "/api/users/" + strconv.Itoa(int(member.ID)) + "/fines/payments"
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "POST", paymentsPath, gin.H{"amount": 100}).Code)
+	w = sendAs(t, router, librarian, "POST", paymentsPath, gin.H{"amount": 0})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var invalid errorEnvelope
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &invalid))
+	assert.Equal(t, apierror.CodeValidationFailed, invalid.Error.Code)
+	assert.Equal(t, "amount", invalid.Error.Details["field"])
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "POST", paymentsPath, gin.H{"amount": rules.FineCap + 1}).Code)
+	w = sendAs(t, router, librarian, "POST", paymentsPath, gin.H{"amount": 300, "note": "Espèces"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, rules.FineCap-300, balanceOf(member))
+
+	// Une remise sans montant solde le compte ; l'adhérent peut de nouveau emprunter
+	assert.Equal(t, http.StatusCreated, sendAs(t, router, librarian, "POST", "/api/users/"+strconv.Itoa(int(member.ID))+"/fines/waive", gin.H{"note": "Geste commercial"}).Code)
+	assert.Zero(t, balanceOf(member))
+	assert.Equal(t, http.StatusCreated, sendAs(t, router, member, "POST", "/api/loans", gin.H{"resource_id": other.ID, "borrow_type": "sur_place"}).Code)
+
+	// L'historique du compte est consultable par l'adhérent et par le personnel
+	w = sendAs(t, router, member, "GET", "/api/fines", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var ledger struct {
+		Balance int
+		Entries []models.FineEntry
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &ledger))
+	assert.Zero(t, ledger.Balance)
+	kinds := []string{}
+	for _, entry := range ledger.Entries {
+		kinds = append(kinds, entry.Kind)
+	}
+	assert.Contains(t, kinds, models.FineCharge)
+	assert.Contains(t, kinds, models.FinePayment)
+	assert.Contains(t, kinds, models.FineWaiver)
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "GET", "/api/fines?user_id="+strconv.Itoa(int(librarian.ID)), nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "GET", "/api/fines?user_id="+strconv.Itoa(int(member.ID)), nil).Code)
+}
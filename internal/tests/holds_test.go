@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldQueue(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	holdStatus := func(id uint) models.HoldStatus {
+		var stored models.Hold
+		assert.NoError(t, db.First(&stored, id).Error)
+		return stored.Status
+	}
+	decodeHold := func(w *httptest.ResponseRecorder) models.Hold {
+		var hold models.Hold
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hold))
+		return hold
+	}
+
+	borrower := createTestUser(t, db, "holds.borrower@example.com", models.RoleMember)
+	first := createTestUser(t, db, "holds.first@example.com", models.RoleMember)
+	second := createTestUser(t, db, "holds.second@example.com", models.RoleMember)
+	third := createTestUser(t, db, "holds.third@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "holds.librarian@example.com", models.RoleLibrarian)
+
+	resource := createTestResource(t, db, "Dune", "Livre", 1)
+	hold := gin.H{"resource_id": resource.ID}
+	loan := gin.H{"resource_id": resource.ID, "borrow_type": "a_emporter"}
+
+	// Une ressource disponible ne se réserve pas : elle s'emprunte
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, first, "POST", "/api/holds", hold).Code)
+	w := sendAs(t, router, borrower, "POST", "/api/loans", loan)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var borrowed models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &borrowed))
+
+	// L'emprunteur ne peut pas réserver ce qu'il a déjà
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, borrower, "POST", "/api/holds", hold).Code)
+
+	// Trois adhérents font la queue, dans l'ordre d'arrivée
+	w = sendAs(t, router, first, "POST", "/api/holds", hold)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	firstHold := decodeHold(w)
+	assert.Equal(t, 1, firstHold.Position)
+	assert.Equal(t, models.HoldWaiting, firstHold.Status)
+
+	w = sendAs(t, router, second, "POST", "/api/holds", hold)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	secondHold := decodeHold(w)
+	assert.Equal(t, 2, secondHold.Position)
+
+	w = sendAs(t, router, third, "POST", "/api/holds", hold)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	thirdHold := decodeHold(w)
+	assert.Equal(t, 3, thirdHold.Position)
+
+	// Une seule réservation active par adhérent et par ressource
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, first, "POST", "/api/holds", hold).Code)
+
+	// Seul le personnel consulte la file d'une ressource
+	queuePath := "/api/holds?resource_id=" + strconv.Itoa(int(resource.ID))
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, first, "GET", queuePath, nil).Code)
+	w = sendAs(t, router, librarian, "GET", queuePath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var queue []models.Hold
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &queue))
+	if assert.Len(t, queue, 3) {
+		assert.Equal(t, []uint{first.ID, second.ID, third.ID}, []uint{queue[0].UserID, queue[1].UserID, queue[2].UserID})
+		assert.Equal(t, []int{1, 2, 3}, []int{queue[0].Position, queue[1].Position, queue[2].Position})
+	}
+
+	// Le troisième annule ; un autre adhérent ne peut pas annuler à sa place
+	thirdPath := "/api/holds/" + strconv.Itoa(int(thirdHold.ID))
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, second, "DELETE", thirdPath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, third, "DELETE", thirdPath, nil).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, third, "DELETE", thirdPath, nil).Code)
+
+	// Au retour, l'exemplaire est mis de côté pour le premier de la file
+	before := time.Now()
+	assert.Equal(t, http.StatusOK, sendAs(t, router, borrower, "PUT", "/api/loans/"+strconv.Itoa(int(borrowed.ID))+"/return", nil).Code)
+
+	var ready models.Hold
+	assert.NoError(t, db.First(&ready, firstHold.ID).Error)
+	assert.Equal(t, models.HoldReady, ready.Status)
+	if assert.NotNil(t, ready.CopyID) && assert.NotNil(t, ready.PickupDeadline) {
+		assert.Equal(t, borrowed.CopyID, *ready.CopyID)
+		assert.WithinDuration(t, before.Add(testConfig.Loans.HoldPickupDelay), *ready.PickupDeadline, 5*time.Second)
+	}
+	var stored models.Resource
+	assert.NoError(t, db.First(&stored, resource.ID).Error)
+	assert.Equal(t, models.ResourceReserved, stored.Status)
+
+	// Le second est désormais premier de la file et ne peut pas prendre l'exemplaire mis de côté
+	w = sendAs(t, router, second, "GET", "/api/holds", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var mine []models.Hold
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &mine))
+	if assert.Len(t, mine, 1) {
+		assert.Equal(t, 1, mine[0].Position)
+	}
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, second, "POST", "/api/loans", loan).Code)
+
+	// Passé la date limite de retrait, la réservation expire et passe au suivant
+	holds := service.NewHoldService(repository.NewGormStore(db), testConfig.Loans, clock.Real())
+	expired, _, err := holds.Process(before.Add(testConfig.Loans.HoldPickupDelay + time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, models.HoldExpired, holdStatus(firstHold.ID))
+	assert.Equal(t, models.HoldReady, holdStatus(secondHold.ID))
+
+	// Le premier a perdu son tour ; le second emprunte l'exemplaire mis de côté
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, first, "POST", "/api/loans", loan).Code)
+	w = sendAs(t, router, second, "POST", "/api/loans", loan)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var picked models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &picked))
+	assert.Equal(t, borrowed.CopyID, picked.CopyID)
+	assert.Equal(t, models.HoldFulfilled, holdStatus(secondHold.ID))
+	assert.NoError(t, db.First(&stored, resource.ID).Error)
+	assert.Equal(t, models.ResourceBorrowed, stored.Status)
+}
+
+func TestHoldQueueOrderIsDeterministic(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	resource := createTestResource(t, db, "File d'attente simultanée", "Jeu", 1)
+	store := repository.NewGormStore(db)
+	catalog := service.NewCatalogService(store)
+	_, err := catalog.TransitionCopies(resource.ID, models.ResourceDisable)
+	assert.NoError(t, err)
+
+	// Des réservations enregistrées au même instant sont servies dans l'ordre d'insertion
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	holdService := service.NewHoldService(store, testConfig.Loans, clock.NewFake(now))
+	var users []models.User
+	for i := 0; i < 4; i++ {
+		user := createTestUser(t, db, fmt.Sprintf("holds.same%d@example.com", i), models.RoleMember)
+		users = append(users, user)
+		_, err := holdService.Place(service.Actor{ID: user.ID, Role: user.Role}, resource.ID)
+		assert.NoError(t, err)
+	}
+
+	queue, err := holdService.Queue(resource.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, queue, len(users)) {
+		for i, hold := range queue {
+			assert.Equal(t, users[i].ID, hold.UserID)
+			assert.Equal(t, i+1, hold.Position)
+		}
+	}
+
+	// La remise en service de l'exemplaire sert le premier de la file
+	var copy models.Copy
+	assert.NoError(t, db.Where("resource_id = ?", resource.ID).First(&copy).Error)
+	_, err = catalog.TransitionCopy(copy.ID, models.ResourceEnable)
+	assert.NoError(t, err)
+	_, served, err := holdService.Process(now)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, served, 1)
+
+	var holds []models.Hold
+	assert.NoError(t, db.Where("resource_id = ?", resource.ID).Order("id").Find(&holds).Error)
+	assert.Equal(t, models.HoldReady, holds[0].Status)
+	assert.Equal(t, models.HoldWaiting, holds[1].Status)
+}
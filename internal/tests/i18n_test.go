@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"awesomeProject/internal/apierror"
+	"awesomeProject/internal/i18n"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	t.Parallel()
+	cases := map[string]i18n.Lang{
+		"":                         i18n.FR,
+		"en":                       i18n.EN,
+		"en-GB,en;q=0.9":           i18n.EN,
+		"fr-FR,fr;q=0.9,en;q=0.8":  i18n.FR,
+		"de-DE,en;q=0.5":           i18n.EN,
+		"de,es;q=0.8":              i18n.FR,
+		"en;q=0.2, fr;q=0.7":       i18n.FR,
+		"EN-us":                    i18n.EN,
+		"en;q=0, fr;q=0.1":         i18n.FR,
+		"en;q=abc, de;q=0.9, *":    i18n.FR,
+		"  en-US ; q=0.8 , de":     i18n.EN,
+		"fr;q=0.5, en-US;q=0.5001": i18n.EN,
+	}
+	for header, expected := range cases {
+		assert.Equal(t, expected, i18n.Negotiate(header), "Accept-Language: %q", header)
+	}
+}
+
+// messageArgs indique, pour les fonctions qui reçoivent un message destiné à l'utilisateur,
+// la position de ce message parmi leurs arguments.
+var messageArgs = map[string]int{
+	"New": 2, "BadRequest": 1, "Invalid": 2, "InvalidParam": 1, "Unauthorized": 1, "NotFound": 1,
+	"Conflict": 1, "Internal": 0, "WithField": 2, "T": 1,
+	"newError": 3, "notFound": 2, "transitionConflict": 2,
+	"respondError": 2, "streamExport": 5,
+}
+
+// TestEnglishCatalogue vérifie que chaque message passé aux constructeurs d'erreurs, à i18n.T ou
+// retourné par une fonction de validation est traduit en anglais.
+func TestEnglishCatalogue(t *testing.T) {
+	t.Parallel()
+	var messages []string
+	for _, dir := range []string{"../handlers", "../service", "../apierror", "../middleware", "../routes"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		assert.NoError(t, err)
+		for _, path := range files {
+			file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			assert.NoError(t, err)
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil {
+					continue
+				}
+				validator := strings.HasPrefix(fn.Name.Name, "validate") || fn.Name.Name == "ruleMessage"
+				ast.Inspect(fn.Body, func(n ast.Node) bool {
+					var candidates []ast.Expr
+					switch n := n.(type) {
+					case *ast.CallExpr:
+						var name string
+						switch fun := n.Fun.(type) {
+						case *ast.Ident:
+							name = fun.Name
+						case *ast.SelectorExpr:
+							name = fun.Sel.Name
+						}
+						if i, ok := messageArgs[name]; ok && i < len(n.Args) {
+							candidates = append(candidates, n.Args[i])
+						}
+					case *ast.ReturnStmt:
+						if validator {
+							candidates = append(candidates, n.Results...)
+						}
+					}
+					for _, expr := range candidates {
+						if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							if message, _ := strconv.Unquote(lit.Value); message != "" {
+								messages = append(messages, message)
+							}
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+
+	assert.Greater(t, len(messages), 100)
+	for _, message := range messages {
+		assert.True(t, i18n.Has(i18n.EN, message), "Message non traduit en anglais : %q", message)
+	}
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	send := func(method, path, body string, headers map[string]string) (*httptest.ResponseRecorder, errorEnvelope) {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var envelope errorEnvelope
+		if w.Code >= 400 {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+		}
+		return w, envelope
+	}
+	english := map[string]string{"Accept-Language": "en-US,en;q=0.9"}
+
+	// Sans préférence, les messages sont en français
+	w, envelope := send("GET", "/api/resources/1", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Ressource non trouvée", envelope.Error.Message)
+	assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+
+	// Accept-Language choisit l'anglais, y compris pour les messages de validation
+	w, envelope = send("GET", "/api/resources/1", "", english)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, apierror.CodeResourceNotFound, envelope.Error.Code)
+	assert.Equal(t, "Resource not found", envelope.Error.Message)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+
+	_, envelope = send("POST", "/api/register", `{"name": "Bob", "email": "bob@example.com", "password": "123"}`, english)
+	assert.Equal(t, "Invalid data", envelope.Error.Message)
+	assert.Equal(t, "password", envelope.Error.Fields[0].Field)
+	assert.Equal(t, "At least 6 characters expected", envelope.Error.Fields[0].Message)
+
+	_, envelope = send("GET", "/api/resources?per_page=500", "", english)
+	assert.Equal(t, "per_page must be between 1 and 100", envelope.Error.Message)
+	_, envelope = send("GET", "/api/resources?per_page=500", "", nil)
+	assert.Equal(t, "per_page doit être compris entre 1 et 100", envelope.Error.Message)
+
+	// Les erreurs du domaine sont traduites aussi
+	_, envelope = send("POST", "/api/login", `{"email": "nobody@example.com", "password": "secret"}`, english)
+	assert.Equal(t, apierror.CodeInvalidCredentials, envelope.Error.Code)
+	assert.Equal(t, "Invalid email or password", envelope.Error.Message)
+
+	// La langue préférée de l'utilisateur l'emporte sur Accept-Language
+	member := createTestUser(t, db, "i18n.member@example.com", models.RoleMember)
+	authFrench := map[string]string{"Authorization": bearerToken(t, member), "Accept-Language": "fr"}
+	w, _ = send("PUT", "/api/profile", `{"name": "Member", "email": "i18n.member@example.com", "language": "de"}`, authFrench)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = send("PUT", "/api/profile", `{"name": "Member", "email": "i18n.member@example.com", "language": "en"}`, authFrench)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, envelope = send("POST", "/api/loans", `{"resource_id": 999, "borrow_type": "sur_place"}`, authFrench)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Resource not found", envelope.Error.Message)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+
+	// Une mise à jour du profil sans langue conserve la préférence ; une langue vide la retire
+	send("PUT", "/api/profile", `{"name": "Member", "email": "i18n.member@example.com"}`, authFrench)
+	_, envelope = send("POST", "/api/loans", `{"resource_id": 999, "borrow_type": "sur_place"}`, authFrench)
+	assert.Equal(t, "Resource not found", envelope.Error.Message)
+	send("PUT", "/api/profile", `{"name": "Member", "email": "i18n.member@example.com", "language": ""}`, authFrench)
+	_, envelope = send("POST", "/api/loans", `{"resource_id": 999, "borrow_type": "sur_place"}`, authFrench)
+	assert.Equal(t, "Ressource non trouvée", envelope.Error.Message)
+}
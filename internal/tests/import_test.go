@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/handlers"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestImportResources(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	member := createTestUser(t, db, "import.member@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "import.librarian@example.com", models.RoleLibrarian)
+
+	// Définir une fonction helper pour envoyer un fichier à importer
+	send := func(user models.User, query, contentType, body string) (*httptest.ResponseRecorder, handlers.ImportReport) {
+		req, _ := http.NewRequest("POST", "/api/resources/import"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", bearerToken(t, user))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var report handlers.ImportReport
+		_ = json.Unmarshal(w.Body.Bytes(), &report)
+		return w, report
+	}
+	count := func() int64 {
+		var n int64
+		db.Model(&models.Resource{}).Where("title LIKE ?", "Qzimport%").Count(&n)
+		return n
+	}
+
+	const isbn = "9782070612758"
+
+	csvFile := "title,type,copies,authors,year,isbn,language,min_players,max_players\n" +
+		"Qzimport Livre,Livre,2,Auteur Un|Auteur Deux,2001,978-2-07-061275-8,fr,,\n" +
+		"Qzimport Jeu,Jeu,,,,,,2,4\n" +
+		"Qzimport Doublon,Livre,1,,,9782070612758,,,\n" +
+		"qzimport jeu,Jeu,,,,,,,\n" +
+		",Livre,,,,,,,\n" +
+		"Qzimport Erreur,Livre,,,,,,3,\n" +
+		"Qzimport Année,Livre,,,deux mille,,,,\n"
+
+	// Seul le personnel importe
+	w, _ := send(member, "", "text/csv", csvFile)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Format ou colonnes inconnus
+	w, _ = send(librarian, "", "text/plain", csvFile)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	w, _ = send(librarian, "", "text/csv", "title,couleur\nQzimport,rouge\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Import à blanc : le rapport est complet mais rien n'est enregistré
+	w, report := send(librarian, "?dry_run=true", "text/csv", csvFile)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Committed)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 3, report.Errors)
+	assert.Equal(t, int64(0), count())
+
+	// Import atomique : une ligne en erreur annule tout le lot
+	w, report = send(librarian, "?atomic=true", "text/csv", csvFile)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.False(t, report.Committed)
+	assert.Equal(t, int64(0), count())
+
+	// Import par défaut : les lignes valides sont créées, les autres signalées
+	w, report = send(librarian, "", "text/csv", csvFile)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, report.Committed)
+	assert.Equal(t, int64(2), count())
+	if assert.Len(t, report.Rows, 7) {
+		statuses := make([]string, len(report.Rows))
+		for i, row := range report.Rows {
+			statuses[i] = row.Status
+			assert.Equal(t, i+1, row.Row)
+		}
+		assert.Equal(t, []string{"created", "created", "skipped", "skipped", "error", "error", "error"}, statuses)
+		assert.Equal(t, report.Rows[0].ID, report.Rows[2].ID, "le doublon d'ISBN renvoie la ressource existante")
+		assert.Equal(t, report.Rows[1].ID, report.Rows[3].ID, "le doublon de titre et de type renvoie la ressource existante")
+		assert.Contains(t, report.Rows[5].Reason, "Game")
+		assert.Contains(t, report.Rows[6].Reason, "year")
+	}
+
+	var book models.Resource
+	db.Preload("Copies").Preload("Book").First(&book, report.Rows[0].ID)
+	assert.Len(t, book.Copies, 2)
+	if assert.NotNil(t, book.Book) {
+		assert.Equal(t, []string{"Auteur Un", "Auteur Deux"}, book.Book.Authors)
+		assert.Equal(t, isbn, *book.Book.ISBN)
+	}
+
+	// Le même fichier importé une seconde fois ne crée rien
+	_, report = send(librarian, "", "text/csv", csvFile)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 4, report.Skipped)
+
+	// Import JSON, avec un séparateur point-virgule pour le CSV des tableurs français
+	jsonFile := `[
+		{"title": "Qzimport JSON", "type": "Jeu", "status": "indisponible", "min_players": 2, "complexity": 2.5},
+		{"title": "Qzimport Inconnu", "type": "Jeu", "couleur": "rouge"},
+		{"title": "Qzimport Statut", "type": "Jeu", "status": "perdu"}
+	]`
+	w, report = send(librarian, "", "application/json", jsonFile)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Errors)
+
+	// Le rapport est traduit, y compris les erreurs de lecture qui citent la colonne
+	english := func(contentType, body string) handlers.ImportReport {
+		req, _ := http.NewRequest("POST", "/api/resources/import?dry_run=true", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept-Language", "en")
+		req.Header.Set("Authorization", bearerToken(t, librarian))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var report handlers.ImportReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report), w.Body.String())
+		return report
+	}
+	report = english("text/csv", "title,type,year,complexity\nQzimport Année,Livre,deux mille,\nQzimport Complexité,Jeu,,facile\n")
+	if assert.Len(t, report.Rows, 2) {
+		assert.Equal(t, "year: integer expected", report.Rows[0].Reason)
+		assert.Equal(t, "complexity: number expected", report.Rows[1].Reason)
+	}
+	report = english("application/json", `[{"title": "Qzimport Inconnu", "couleur": "rouge"}, {"title": "Qzimport Type", "copies": "deux"}]`)
+	if assert.Len(t, report.Rows, 2) {
+		assert.Equal(t, "couleur: unknown field", report.Rows[0].Reason)
+		assert.Equal(t, "copies: invalid value type", report.Rows[1].Reason)
+	}
+
+	w, report = send(librarian, "?format=csv", "application/octet-stream", "title;type;genres\nQzimport Point-virgule;Livre;Conte|Poésie\n")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, int64(4), count())
+}
+
+func TestImportDuplicatesAndStorageErrors(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	librarian := createTestUser(t, db, "import.errors@example.com", models.RoleLibrarian)
+	send := func(body string) (*httptest.ResponseRecorder, handlers.ImportReport) {
+		req, _ := http.NewRequest("POST", "/api/resources/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		req.Header.Set("Authorization", bearerToken(t, librarian))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var report handlers.ImportReport
+		_ = json.Unmarshal(w.Body.Bytes(), &report)
+		return w, report
+	}
+
+	// Les titres sont comparés de la même façon dans le lot et au catalogue, accents compris
+	existing := createTestResource(t, db, "Qzimport Élan", "Livre", 1)
+	w, report := send("title,type\nqzimport élan,Livre\nQzimport Œuvre,Livre\nqzimport œuvre,Livre\n")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	if assert.Len(t, report.Rows, 3) {
+		assert.Equal(t, "skipped", report.Rows[0].Status)
+		assert.Equal(t, existing.ID, report.Rows[0].ID)
+		assert.Equal(t, "created", report.Rows[1].Status)
+		assert.Equal(t, "skipped", report.Rows[2].Status)
+		assert.Equal(t, report.Rows[1].ID, report.Rows[2].ID)
+	}
+
+	// Une contrainte du stockage refuse la ligne sans interrompre le lot
+	if testDriver() != config.DriverPostgres {
+		assert.NoError(t, db.Exec(`CREATE TRIGGER qzimport_refus BEFORE INSERT ON resources
+			WHEN NEW.title = 'Qzimport Refusé' BEGIN SELECT RAISE(ABORT, 'refusé'); END`).Error)
+		w, report = send("title,type\nQzimport Refusé,Livre\nQzimport Accepté,Livre\n")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		if assert.Len(t, report.Rows, 2) {
+			assert.Equal(t, "error", report.Rows[0].Status)
+			assert.Equal(t, "created", report.Rows[1].Status)
+		}
+	}
+
+	// Une panne du stockage fait échouer la requête
+	var failing atomic.Bool
+	assert.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:panne", func(tx *gorm.DB) {
+		if failing.Load() && tx.Statement.Table == "copies" {
+			tx.AddError(errors.New("disque plein"))
+		}
+	}))
+	failing.Store(true)
+	w, _ = send("title,type\nQzimport Panne,Livre\n")
+	failing.Store(false)
+	assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+	var n int64
+	db.Model(&models.Resource{}).Where("title = ?", "Qzimport Panne").Count(&n)
+	assert.Zero(t, n)
+}
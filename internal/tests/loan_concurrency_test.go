@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentLoanCreation(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	resource := createTestResource(t, db, "Concurrence", "Jeu", 1)
+
+	// Plusieurs adhérents cliquent sur "Emprunter" au même moment
+	const borrowers = 50
+	tokens := make([]string, borrowers)
+	for i := range tokens {
+		tokens[i] = bearerToken(t, createTestUser(t, db, fmt.Sprintf("concurrent%d@example.com", i), models.RoleMember))
+	}
+	body, _ := json.Marshal(gin.H{"resource_id": resource.ID, "borrow_type": "a_emporter"})
+
+	codes := make([]int, borrowers)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < borrowers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", "/api/loans", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", tokens[i])
+			w := httptest.NewRecorder()
+			<-start
+			router.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// Un seul prêt est accordé, les autres demandes sont refusées
+	created, conflicts := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		}
+	}
+	assert.Equal(t, 1, created, "codes reçus: %v", codes)
+	assert.Equal(t, borrowers-1, conflicts, "codes reçus: %v", codes)
+
+	var loans []models.Loan
+	assert.NoError(t, db.Where("resource_id = ?", resource.ID).Find(&loans).Error)
+	assert.Len(t, loans, 1)
+
+	var stored models.Resource
+	assert.NoError(t, db.First(&stored, resource.ID).Error)
+	assert.Equal(t, models.ResourceBorrowed, stored.Status)
+
+	// Le retour libère la ressource ; un second retour est refusé
+	var borrower models.User
+	assert.NoError(t, db.First(&borrower, loans[0].UserID).Error)
+	returnPath := "/api/loans/" + strconv.Itoa(int(loans[0].ID)) + "/return"
+	for _, expected := range []int{http.StatusOK, http.StatusConflict} {
+		req, _ := http.NewRequest("PUT", returnPath, nil)
+		req.Header.Set("Authorization", bearerToken(t, borrower))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code)
+	}
+
+	assert.NoError(t, db.First(&stored, resource.ID).Error)
+	assert.Equal(t, models.ResourceAvailable, stored.Status)
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/logging"
+	"awesomeProject/internal/middleware"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// logCapture construit un routeur dont le journal JSON est écrit dans un tampon.
+type logCapture struct {
+	buf    bytes.Buffer
+	router http.Handler
+}
+
+func newLogCapture(t *testing.T, db *gorm.DB, cfg config.LogConfig) *logCapture {
+	t.Helper()
+	capture := &logCapture{}
+	logger, err := logging.New(&capture.buf, cfg)
+	if err != nil {
+		t.Fatalf("Erreur lors de la création du journal: %v", err)
+	}
+	capture.router = routes.NewRouter(testConfig, db, clock.Real(), logger)
+	return capture
+}
+
+func (l *logCapture) send(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, values := range header {
+		req.Header.Set(key, values[0])
+	}
+	w := httptest.NewRecorder()
+	l.router.ServeHTTP(w, req)
+	return w
+}
+
+// entries retourne les messages journalisés depuis le dernier appel.
+func (l *logCapture) entries(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(l.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry), "Ligne de journal invalide : %s", line)
+		entries = append(entries, entry)
+	}
+	l.buf.Reset()
+	return entries
+}
+
+// findEntry retourne le premier message dont l'attribut key vaut value.
+func findEntry(entries []map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	for _, entry := range entries {
+		if entry[key] == value {
+			return entry
+		}
+	}
+	return nil
+}
+
+func TestStructuredLogging(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	capture := newLogCapture(t, db, config.LogConfig{Level: "info", Format: config.LogFormatJSON, EventLevel: "info"})
+	member := createTestUser(t, db, "logging.member@example.com", models.RoleMember)
+	resource := createTestResource(t, db, "Journal de bord", "Livre", 1)
+
+	// Un prêt produit l'événement du domaine et le journal d'accès, liés par l'identifiant de requête
+	w := capture.send("POST", "/api/loans", fmt.Sprintf(`{"resource_id": %d, "borrow_type": "a_emporter"}`, resource.ID),
+		http.Header{"Authorization": {bearerToken(t, member)}, middleware.RequestIDHeader: {"req-log-1"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	entries := capture.entries(t)
+
+	event := findEntry(entries, "event", "loan.created")
+	if assert.NotNil(t, event) {
+		assert.Equal(t, "INFO", event["level"])
+		assert.Equal(t, "req-log-1", event["request_id"])
+		assert.Equal(t, float64(member.ID), event["user_id"])
+		assert.Equal(t, float64(resource.ID), event["resource_id"])
+		assert.NotNil(t, event["loan_id"])
+	}
+
+	access := findEntry(entries, "msg", "Requête HTTP")
+	if assert.NotNil(t, access) {
+		assert.Equal(t, "INFO", access["level"])
+		assert.Equal(t, "req-log-1", access["request_id"])
+		assert.Equal(t, "POST", access["method"])
+		assert.Equal(t, "/api/loans", access["route"])
+		assert.Equal(t, float64(http.StatusCreated), access["status"])
+		assert.Equal(t, float64(member.ID), access["user_id"])
+		assert.Contains(t, access, "latency_ms")
+	}
+
+	// Une connexion refusée est un événement ; le journal d'accès la signale en avertissement
+	w = capture.send("POST", "/api/login", `{"email": "intrus@example.com", "password": "secret"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	entries = capture.entries(t)
+
+	event = findEntry(entries, "event", "auth.login_failed")
+	if assert.NotNil(t, event) {
+		assert.Equal(t, "intrus@example.com", event["email"])
+		assert.Equal(t, w.Header().Get(middleware.RequestIDHeader), event["request_id"])
+	}
+	access = findEntry(entries, "msg", "Requête HTTP")
+	if assert.NotNil(t, access) {
+		assert.Equal(t, "WARN", access["level"])
+		assert.Equal(t, "/api/login", access["route"])
+		assert.Equal(t, float64(http.StatusUnauthorized), access["status"])
+		assert.NotContains(t, access, "user_id")
+		assert.NotEmpty(t, access["error"])
+	}
+
+	// Les routes inconnues sont journalisées sans route
+	capture.send("GET", "/api/unknown", "", nil)
+	access = findEntry(capture.entries(t), "msg", "Requête HTTP")
+	if assert.NotNil(t, access) {
+		assert.Equal(t, "", access["route"])
+		assert.Equal(t, "/api/unknown", access["path"])
+	}
+}
+
+func TestEventLogLevel(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	member := createTestUser(t, db, "events.member@example.com", models.RoleMember)
+	login := `{"email": "intrus@example.com", "password": "secret"}`
+
+	// Événements en debug sous un journal en info : ils ne sont pas écrits
+	quiet := newLogCapture(t, db, config.LogConfig{Level: "info", Format: config.LogFormatJSON, EventLevel: "debug"})
+	quiet.send("POST", "/api/login", login, nil)
+	entries := quiet.entries(t)
+	assert.Nil(t, findEntry(entries, "event", "auth.login_failed"))
+	assert.NotNil(t, findEntry(entries, "msg", "Requête HTTP"))
+
+	// Événements en warn sous un journal en warn : seuls les événements et les erreurs sont écrits
+	events := newLogCapture(t, db, config.LogConfig{Level: "warn", Format: config.LogFormatJSON, EventLevel: "warn"})
+	events.send("POST", "/api/logout", "", http.Header{"Authorization": {bearerToken(t, member)}})
+	entries = events.entries(t)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "auth.logout", entries[0]["event"])
+		assert.Equal(t, "WARN", entries[0]["level"])
+		assert.Equal(t, float64(member.ID), entries[0]["user_id"])
+	}
+
+	// Le format texte est accepté, un niveau inconnu est refusé
+	_, err := logging.New(&bytes.Buffer{}, config.LogConfig{Level: "info", Format: config.LogFormatText, EventLevel: "info"})
+	assert.NoError(t, err)
+	_, err = logging.New(&bytes.Buffer{}, config.LogConfig{Level: "verbose", Format: config.LogFormatJSON, EventLevel: "info"})
+	assert.Error(t, err)
+}
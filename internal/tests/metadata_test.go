@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestISBN13Checksum(t *testing.T) {
+	assert.True(t, models.IsValidISBN13("9782070612758"))
+	assert.True(t, models.IsValidISBN13(models.NormalizeISBN("978-2-07-061275-8")))
+	assert.False(t, models.IsValidISBN13("9782070612759")) // Mauvaise clé
+	assert.False(t, models.IsValidISBN13("2070612759"))    // ISBN-10
+	assert.False(t, models.IsValidISBN13("978207061275X")) // Caractère invalide
+	assert.False(t, models.IsValidISBN13("1234567890128")) // Préfixe inconnu
+}
+
+func TestResourceMetadata(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	librarian := createTestUser(t, db, "metadata.librarian@example.com", models.RoleLibrarian)
+	list := func(params url.Values) []string {
+		w := sendAs(t, router, librarian, "GET", "/api/resources?"+params.Encode(), nil)
+		assert.Equal(t, http.StatusOK, w.Code, params.Encode())
+		var resources []models.Resource
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resources))
+		var titles []string
+		for _, resource := range resources {
+			titles = append(titles, resource.Title)
+		}
+		return titles
+	}
+
+	const tag = "Qzmeta"
+	const isbn = "9782070612758"
+
+	// Un livre avec sa notice ; l'ISBN est normalisé et les listes nettoyées
+	w := sendAs(t, router, librarian, "POST", "/api/resources", gin.H{
+		"Title": tag + " Le Petit Prince",
+		"Type":  "Livre",
+		"Book": gin.H{
+			"Authors":   []string{" Antoine de Saint-Exupéry ", ""},
+			"Publisher": "Gallimard",
+			"Year":      1943,
+			"ISBN":      "978-2-07-061275-8",
+			"Language":  "FR",
+			"Genres":    []string{"Conte", "Jeunesse", "conte"},
+			"CoverURL":  "https://covers.example.com/petit-prince.jpg",
+		},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var book models.Resource
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &book))
+
+	w = sendAs(t, router, librarian, "GET", "/api/resources/"+strconv.Itoa(int(book.ID)), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stored models.Resource
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	if assert.NotNil(t, stored.Book) && assert.NotNil(t, stored.Book.ISBN) {
+		assert.Equal(t, isbn, *stored.Book.ISBN)
+		assert.Equal(t, []string{"Antoine de Saint-Exupéry"}, stored.Book.Authors)
+		assert.Equal(t, []string{"Conte", "Jeunesse"}, stored.Book.Genres)
+		assert.Equal(t, "fr", stored.Book.Language)
+		assert.Equal(t, 1943, stored.Book.Year)
+	}
+	assert.Nil(t, stored.Game)
+
+	// Un ISBN ne désigne qu'une ressource
+	w = sendAs(t, router, librarian, "POST", "/api/resources", gin.H{"Title": tag + " Doublon", "Type": "Livre", "Book": gin.H{"ISBN": isbn}})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Un jeu avec sa fiche
+	w = sendAs(t, router, librarian, "POST", "/api/resources", gin.H{
+		"Title": tag + " Azul",
+		"Type":  "Jeu",
+		"Game":  gin.H{"Designer": "Michael Kiesling", "MinPlayers": 2, "MaxPlayers": 4, "PlayTime": 45, "MinAge": 8, "Complexity": 1.8},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = sendAs(t, router, librarian, "POST", "/api/resources", gin.H{
+		"Title": tag + " Twilight Imperium",
+		"Type":  "Jeu",
+		"Game":  gin.H{"MinPlayers": 3, "MaxPlayers": 6, "PlayTime": 480, "MinAge": 14, "Complexity": 4.3},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Métadonnées invalides ou sans rapport avec le type
+	for name, payload := range map[string]gin.H{
+		"clé ISBN":           {"Type": "Livre", "Book": gin.H{"ISBN": "9782070612759"}},
+		"langue":             {"Type": "Livre", "Book": gin.H{"Language": "français"}},
+		"année":              {"Type": "Livre", "Book": gin.H{"Year": 30000}},
+		"couverture":         {"Type": "Livre", "Book": gin.H{"CoverURL": "javascript:alert(1)"}},
+		"notice sur un jeu":  {"Type": "Jeu", "Book": gin.H{"Authors": []string{"X"}}},
+		"fiche sur un livre": {"Type": "Livre", "Game": gin.H{"MinPlayers": 2}},
+		"joueurs":            {"Type": "Jeu", "Game": gin.H{"MinPlayers": 4, "MaxPlayers": 2}},
+		"complexité":         {"Type": "Jeu", "Game": gin.H{"Complexity": 7}},
+		"âge":                {"Type": "Jeu", "Game": gin.H{"MinAge": -1}},
+	} {
+		payload["Title"] = tag + " Invalide"
+		assert.Equal(t, http.StatusBadRequest, sendAs(t, router, librarian, "POST", "/api/resources", payload).Code, name)
+	}
+
+	// Filtres sur les métadonnées
+	params := func(pairs ...string) url.Values {
+		values := url.Values{"q": {tag}}
+		for i := 0; i < len(pairs); i += 2 {
+			values.Set(pairs[i], pairs[i+1])
+		}
+		return values
+	}
+	assert.Equal(t, []string{tag + " Le Petit Prince"}, list(params("author", "saint-exup")))
+	assert.Equal(t, []string{tag + " Le Petit Prince"}, list(params("genre", "jeunesse", "language", "fr", "year", "1943")))
+	assert.Empty(t, list(params("genre", "jeune")))
+	assert.Empty(t, list(params("language", "en")))
+	assert.Equal(t, []string{tag + " Azul", tag + " Twilight Imperium"}, list(params("players", "4")))
+	assert.Equal(t, []string{tag + " Twilight Imperium"}, list(params("players", "5")))
+	assert.Equal(t, []string{tag + " Azul"}, list(params("max_play_time", "60", "age", "10")))
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, librarian, "GET", "/api/resources?players=deux", nil).Code)
+}
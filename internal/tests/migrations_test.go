@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Les tests utilisent SQLite en mémoire. Pour les exécuter sur PostgreSQL, par exemple lancé dans un conteneur :
+//
+//	docker run --rm -d -p 5432:5432 -e POSTGRES_PASSWORD=postgres postgres:16
+//	TEST_DATABASE_DRIVER=postgres TEST_DATABASE_DSN="host=localhost user=postgres password=postgres sslmode=disable" go test ./...
+//
+// Chaque test dispose alors de son propre schéma, supprimé à la fin du test. Les tests sont ignorés
+// si la base est injoignable, de même que les tests propres à PostgreSQL quand SQLite est utilisé.
+var (
+	postgresOnce  sync.Once
+	postgresAdmin *gorm.DB
+	postgresErr   error
+	schemaCount   atomic.Int64
+)
+
+// testDriver retourne le moteur de base de données des tests.
+func testDriver() string {
+	if driver := os.Getenv("TEST_DATABASE_DRIVER"); driver != "" {
+		return driver
+	}
+	return config.DriverSQLite
+}
+
+// openMemoryDB ouvre une base SQLite en mémoire, vide et isolée des autres tests.
+func openMemoryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: "file::memory:"}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Erreur lors de l'ouverture de la base en mémoire: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	// Chaque connexion à ":memory:" ouvre une base différente
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// openPostgresDB ouvre une connexion PostgreSQL limitée à un schéma vide, créé pour le test.
+// Le test est ignoré si la base de TEST_DATABASE_DSN est injoignable.
+func openPostgresDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	postgresOnce.Do(func() {
+		postgresAdmin, postgresErr = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if postgresErr == nil {
+			// Partagée par les schémas des tests : la créer une fois évite des créations simultanées
+			postgresErr = postgresAdmin.Exec("CREATE EXTENSION IF NOT EXISTS unaccent SCHEMA public").Error
+		}
+	})
+	if postgresErr != nil {
+		t.Skipf("PostgreSQL injoignable (%v) : test ignoré", postgresErr)
+	}
+
+	schema := fmt.Sprintf("test_%d_%d", os.Getpid(), schemaCount.Add(1))
+	if err := postgresAdmin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("Erreur lors de la création du schéma de test: %v", err)
+	}
+	t.Cleanup(func() { postgresAdmin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	pgConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("DSN PostgreSQL invalide: %v", err)
+	}
+	pgConfig.RuntimeParams["search_path"] = schema + ",public"
+	sqlDB := stdlib.OpenDB(*pgConfig)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Erreur lors de l'ouverture de la base PostgreSQL: %v", err)
+	}
+	return db
+}
+
+// openTestDB ouvre une base vide sur le moteur des tests.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	if testDriver() == config.DriverPostgres {
+		return openPostgresDB(t)
+	}
+	return openMemoryDB(t)
+}
+
+// skipUnlessPostgres ignore un test propre à PostgreSQL quand les tests utilisent SQLite.
+func skipUnlessPostgres(t *testing.T) {
+	t.Helper()
+	if testDriver() != config.DriverPostgres {
+		t.Skip("TEST_DATABASE_DRIVER=postgres requis : test ignoré")
+	}
+}
+
+// newTestDB ouvre une base vide et y applique les migrations : chaque test dispose de sa propre base.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openTestDB(t)
+	if _, err := database.Migrate(db); err != nil {
+		t.Fatalf("Erreur lors de la migration de la base de test: %v", err)
+	}
+	return db
+}
+
+func TestMigrationsKeepExistingData(t *testing.T) {
+	db := openMemoryDB(t)
+
+	// Schéma créé par l'ancien AutoMigrate, avant l'existence des migrations
+	assert.NoError(t, db.Exec("CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text NOT NULL,`email` text NOT NULL,`password` text NOT NULL,CONSTRAINT `uni_users_email` UNIQUE (`email`))").Error)
+	assert.NoError(t, db.Exec("CREATE TABLE `resources` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text NOT NULL,`type` text NOT NULL,`status` text DEFAULT \"disponible\")").Error)
+	assert.NoError(t, db.Exec("INSERT INTO users (name, email, password) VALUES ('Ancien', 'ancien@example.com', 'x')").Error)
+	assert.NoError(t, db.Exec("INSERT INTO resources (title, type, status) VALUES ('Dune', 'Livre', 'disponible')").Error)
+
+	count, err := database.Migrate(db)
+	assert.NoError(t, err)
+	assert.NotZero(t, count)
+
+	// Les données existantes sont conservées et les nouvelles colonnes ont leur valeur par défaut
+	var user struct {
+		Name string
+		Role string
+	}
+	assert.NoError(t, db.Table("users").Where("email = ?", "ancien@example.com").Take(&user).Error)
+	assert.Equal(t, "Ancien", user.Name)
+	assert.Equal(t, "member", user.Role)
+
+	var resources int64
+	db.Table("resources").Count(&resources)
+	assert.Equal(t, int64(1), resources)
+
+	// Une seconde exécution n'applique rien
+	count, err = database.Migrate(db)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestMigrationsUpDown(t *testing.T) {
+	db := openTestDB(t)
+
+	applied, err := database.Migrate(db)
+	assert.NoError(t, err)
+
+	states, err := database.Status(db)
+	assert.NoError(t, err)
+	assert.Len(t, states, applied)
+	for i, state := range states {
+		assert.NotNil(t, state.AppliedAt, "migration %d non appliquée", state.Version)
+		if i > 0 {
+			assert.Greater(t, state.Version, states[i-1].Version)
+		}
+	}
+
+	// Annulation de toutes les migrations, puis réapplication
+	rolledBack, err := database.Rollback(db, applied)
+	assert.NoError(t, err)
+	assert.Equal(t, applied, rolledBack)
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	reapplied, err := database.Migrate(db)
+	assert.NoError(t, err)
+	assert.Equal(t, applied, reapplied)
+	assert.True(t, db.Migrator().HasTable("users"))
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultLoanPolicy(t *testing.T) {
+	rules := testConfig.Loans
+
+	// Sans politique enregistrée, la configuration s'applique
+	takeAway := service.DefaultPolicy(rules, "Livre", models.BorrowTakeAway)
+	assert.True(t, takeAway.Allowed)
+	assert.Equal(t, int(rules.LoanPeriod/(24*time.Hour)), takeAway.LoanDays)
+	assert.Equal(t, rules.MaxRenewals, takeAway.MaxRenewals)
+	assert.Equal(t, rules.FineDailyRate, takeAway.FineDailyRate)
+
+	// Un prêt sur place se rend le jour même et ne se renouvelle pas
+	onSite := service.DefaultPolicy(rules, "Livre", models.BorrowOnSite)
+	assert.Equal(t, 0, onSite.MaxRenewals)
+	loanDate := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC), onSite.DueDate(loanDate))
+	assert.Equal(t, loanDate.AddDate(0, 0, takeAway.LoanDays), takeAway.DueDate(loanDate))
+}
+
+func TestLoanPolicies(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	createPolicy := func(admin models.User, payload gin.H) models.LoanPolicy {
+		w := sendAs(t, router, admin, "POST", "/api/admin/loan-policies", payload)
+		assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var policy models.LoanPolicy
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &policy))
+		return policy
+	}
+	borrow := func(user models.User, resource models.Resource, borrowType string) *httptest.ResponseRecorder {
+		return sendAs(t, router, user, "POST", "/api/loans", gin.H{"resource_id": resource.ID, "borrow_type": borrowType})
+	}
+
+	const gameType, bookType = "Jeu (politiques)", "Livre (politiques)"
+
+	admin := createTestUser(t, db, "policies.admin@example.com", models.RoleAdmin)
+	librarian := createTestUser(t, db, "policies.librarian@example.com", models.RoleLibrarian)
+	member := createTestUser(t, db, "policies.member@example.com", models.RoleMember)
+
+	// Seuls les administrateurs gèrent les politiques
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, librarian, "GET", "/api/admin/loan-policies", nil).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "POST", "/api/admin/loan-policies", gin.H{"resource_type": gameType, "borrow_type": "a_emporter"}).Code)
+
+	// Saisies invalides
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, admin, "POST", "/api/admin/loan-policies", gin.H{"resource_type": gameType, "borrow_type": "livraison"}).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, admin, "POST", "/api/admin/loan-policies", gin.H{"resource_type": gameType, "borrow_type": "a_emporter", "loan_days": -1}).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, admin, "POST", "/api/admin/loan-policies", gin.H{"resource_type": gameType, "borrow_type": "a_emporter", "max_renewals": 1}).Code)
+
+	// Les jeux ne sortent pas de la bibliothèque
+	banned := createPolicy(admin, gin.H{"resource_type": gameType, "borrow_type": "a_emporter", "allowed": false})
+	assert.False(t, banned.Allowed)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, admin, "POST", "/api/admin/loan-policies", gin.H{"resource_type": gameType, "borrow_type": "a_emporter"}).Code)
+
+	game := createTestResource(t, db, "Terraforming Mars", gameType, 2)
+	assert.Equal(t, http.StatusConflict, borrow(member, game, models.BorrowTakeAway).Code)
+	w := borrow(member, game, models.BorrowOnSite)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Les livres : 3 jours, 2 prêts simultanés au plus, un renouvellement de 7 jours
+	bookPolicy := createPolicy(admin, gin.H{
+		"resource_type": bookType, "borrow_type": "a_emporter",
+		"loan_days": 3, "max_concurrent_loans": 2, "max_renewals": 1, "renewal_days": 7,
+	})
+	assert.True(t, bookPolicy.Allowed)
+
+	before := time.Now()
+	w = borrow(member, createTestResource(t, db, "Le Nom du vent", bookType, 1), models.BorrowTakeAway)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var loan models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loan))
+	assert.WithinDuration(t, before.AddDate(0, 0, 3), loan.ReturnDate, 5*time.Second)
+
+	assert.Equal(t, http.StatusCreated, borrow(member, createTestResource(t, db, "La Peur du sage", bookType, 1), models.BorrowTakeAway).Code)
+	third := createTestResource(t, db, "Les Portes de pierre", bookType, 1)
+	assert.Equal(t, http.StatusConflict, borrow(member, third, models.BorrowTakeAway).Code)
+	// La limite ne porte que sur ce type d'emprunt
+	assert.Equal(t, http.StatusCreated, borrow(member, third, models.BorrowOnSite).Code)
+
+	// Le renouvellement suit la politique du livre
+	renewPath := "/api/loans/" + strconv.Itoa(int(loan.ID)) + "/renew"
+	w = sendAs(t, router, member, "PUT", renewPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var renewed models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &renewed))
+	assert.WithinDuration(t, loan.ReturnDate.AddDate(0, 0, 7), renewed.ReturnDate, time.Second)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", renewPath, nil).Code)
+
+	// Modification puis suppression : les règles par défaut s'appliquent de nouveau
+	policyPath := "/api/admin/loan-policies/" + strconv.Itoa(int(banned.ID))
+	w = sendAs(t, router, admin, "PUT", policyPath, gin.H{"resource_type": gameType, "borrow_type": "a_emporter", "loan_days": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var updated models.LoanPolicy
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.True(t, updated.Allowed)
+	assert.Equal(t, 2, updated.LoanDays)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, admin, "PUT", policyPath, gin.H{"resource_type": bookType, "borrow_type": "a_emporter"}).Code)
+
+	w = sendAs(t, router, admin, "GET", "/api/admin/loan-policies", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var policies []models.LoanPolicy
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &policies))
+	ids := map[uint]bool{}
+	for _, policy := range policies {
+		ids[policy.ID] = true
+	}
+	assert.True(t, ids[banned.ID] && ids[bookPolicy.ID])
+
+	assert.Equal(t, http.StatusOK, sendAs(t, router, admin, "DELETE", policyPath, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, admin, "DELETE", policyPath, nil).Code)
+	resolved, err := service.NewLoanService(repository.NewGormStore(db), testConfig.Loans, clock.Real()).Policy(gameType, models.BorrowTakeAway)
+	assert.NoError(t, err)
+	assert.Equal(t, service.DefaultPolicy(testConfig.Loans, gameType, models.BorrowTakeAway), resolved)
+}
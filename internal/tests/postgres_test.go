@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostgresRowLocking vérifie que les verrous de ligne sérialisent les lectures suivies d'une écriture :
+// sous PostgreSQL, deux transactions peuvent lire le même état avant que l'une d'elles n'écrive.
+func TestPostgresRowLocking(t *testing.T) {
+	skipUnlessPostgres(t)
+	t.Parallel()
+	db := newTestDB(t)
+
+	member := createTestUser(t, db, "locking.member@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "locking.librarian@example.com", models.RoleLibrarian)
+	resource := createTestResource(t, db, "Verrous", "Livre", 1)
+	var copy models.Copy
+	assert.NoError(t, db.Where("resource_id = ?", resource.ID).First(&copy).Error)
+	assert.NoError(t, db.Model(&copy).Update("status", models.ResourceBorrowed).Error)
+
+	now := time.Now()
+	loan := models.Loan{
+		UserID:     member.ID,
+		ResourceID: resource.ID,
+		CopyID:     copy.ID,
+		LoanDate:   now.Add(-20 * 24 * time.Hour),
+		ReturnDate: now.Add(-3 * 24 * time.Hour),
+		Status:     models.LoanOverdue,
+	}
+	assert.NoError(t, db.Create(&loan).Error)
+	store := repository.NewGormStore(db)
+	fines := service.NewFineService(store, clock.NewFake(now))
+	holds := service.NewHoldService(store, testConfig.Loans, clock.NewFake(now))
+
+	// concurrently lance n appels simultanés de fn et retourne leurs erreurs
+	concurrently := func(n int, fn func() error) []error {
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				errs[i] = fn()
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+		return errs
+	}
+
+	// Des calculs d'amende simultanés n'inscrivent l'amende qu'une fois
+	var mu sync.Mutex
+	added := 0
+	for _, err := range concurrently(10, func() error {
+		n, err := store.Fines().Charge(&loan, service.LateFine(loan.ReturnDate, now, 20, 1000), now)
+		mu.Lock()
+		added += n
+		mu.Unlock()
+		return err
+	}) {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 60, added)
+	balance, err := store.Fines().Balance(member.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 60, balance)
+
+	// Des paiements simultanés ne dépassent pas le solde dû
+	paid := 0
+	for _, err := range concurrently(10, func() error {
+		_, _, err := fines.RecordPayment(service.Actor{ID: librarian.ID, Role: librarian.Role}, member.ID, 20, "")
+		return err
+	}) {
+		if err == nil {
+			paid++
+		} else {
+			assert.ErrorIs(t, err, service.ErrAmountExceedsBalance)
+		}
+	}
+	assert.Equal(t, 3, paid)
+	balance, err = store.Fines().Balance(member.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, balance)
+
+	// Des réservations simultanées d'un même adhérent n'en créent qu'une
+	other := createTestUser(t, db, "locking.other@example.com", models.RoleMember)
+	placed := 0
+	for _, err := range concurrently(10, func() error {
+		_, err := holds.Place(service.Actor{ID: other.ID, Role: other.Role}, resource.ID)
+		return err
+	}) {
+		if err == nil {
+			placed++
+		} else {
+			assert.ErrorIs(t, err, service.ErrHoldExists)
+		}
+	}
+	assert.Equal(t, 1, placed)
+
+	// Des emprunts simultanés d'un même adhérent sur des exemplaires différents respectent la limite de prêts
+	assert.NoError(t, db.Create(&models.LoanPolicy{ResourceType: "Jeu", BorrowType: "a_emporter", Allowed: true, LoanDays: 7, MaxConcurrentLoans: 1}).Error)
+	game := createTestResource(t, db, "Verrous en jeu", "Jeu", 10)
+	borrower := createTestUser(t, db, "locking.borrower@example.com", models.RoleMember)
+	loans := service.NewLoanService(store, testConfig.Loans, clock.Real())
+	borrowed := 0
+	for _, err := range concurrently(10, func() error {
+		_, err := loans.Borrow(service.Actor{ID: borrower.ID, Role: borrower.Role}, service.BorrowRequest{ResourceID: game.ID, BorrowType: "a_emporter"})
+		return err
+	}) {
+		if err == nil {
+			borrowed++
+		} else {
+			assert.ErrorIs(t, err, service.ErrTooManyLoans)
+		}
+	}
+	assert.Equal(t, 1, borrowed)
+
+	// Une suppression simultanée à des emprunts ne laisse pas de prêt ouvert sur une ressource supprimée
+	catalog := service.NewCatalogService(store)
+	book := createTestResource(t, db, "Verrous supprimés", "Livre", 5)
+	readers := make([]models.User, 5)
+	for i := range readers {
+		readers[i] = createTestUser(t, db, fmt.Sprintf("locking.reader%d@example.com", i), models.RoleMember)
+	}
+	var calls int
+	var deleteErr error
+	concurrently(len(readers)+1, func() error {
+		mu.Lock()
+		i := calls
+		calls++
+		mu.Unlock()
+		if i == len(readers) {
+			deleteErr = catalog.Delete(book.ID)
+			return deleteErr
+		}
+		_, err := loans.Borrow(service.Actor{ID: readers[i].ID, Role: readers[i].Role}, service.BorrowRequest{ResourceID: book.ID, BorrowType: "a_emporter"})
+		return err
+	})
+	var open int64
+	assert.NoError(t, db.Model(&models.Loan{}).Where("resource_id = ? AND status = ?", book.ID, models.LoanActive).Count(&open).Error)
+	if deleteErr == nil {
+		assert.Zero(t, open)
+	} else {
+		assert.ErrorIs(t, deleteErr, service.ErrOpenLoans)
+		assert.NotZero(t, open)
+	}
+}
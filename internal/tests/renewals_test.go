@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoanRenewals(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	borrow := func(user models.User, resource models.Resource, borrowType string) models.Loan {
+		w := sendAs(t, router, user, "POST", "/api/loans", gin.H{"resource_id": resource.ID, "borrow_type": borrowType})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var loan models.Loan
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loan))
+		return loan
+	}
+	renewPath := func(loan models.Loan) string {
+		return "/api/loans/" + strconv.Itoa(int(loan.ID)) + "/renew"
+	}
+
+	member := createTestUser(t, db, "renew.member@example.com", models.RoleMember)
+	other := createTestUser(t, db, "renew.other@example.com", models.RoleMember)
+	period := testConfig.Loans.RenewalPeriod
+
+	// Chaque renouvellement repousse la date de retour et est historisé
+	loan := borrow(member, createTestResource(t, db, "Fondation", "Livre", 1), models.BorrowTakeAway)
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, other, "PUT", renewPath(loan), nil).Code)
+	expected := loan.ReturnDate
+	for i := 1; i <= testConfig.Loans.MaxRenewals; i++ {
+		w := sendAs(t, router, member, "PUT", renewPath(loan), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var renewed models.Loan
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &renewed))
+		expected = expected.Add(period)
+		assert.WithinDuration(t, expected, renewed.ReturnDate, time.Second)
+		assert.Equal(t, i, renewed.RenewalCount)
+		if assert.Len(t, renewed.Renewals, i) {
+			last := renewed.Renewals[i-1]
+			assert.WithinDuration(t, expected.Add(-period), last.PreviousReturnDate, time.Second)
+			assert.WithinDuration(t, expected, last.NewReturnDate, time.Second)
+			assert.Equal(t, member.ID, last.RenewedByID)
+		}
+	}
+
+	// Au-delà du maximum, le renouvellement est refusé
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", renewPath(loan), nil).Code)
+
+	// Refusé quand un autre adhérent attend la ressource
+	held := createTestResource(t, db, "Hypérion", "Livre", 1)
+	heldLoan := borrow(member, held, models.BorrowTakeAway)
+	assert.Equal(t, http.StatusCreated, sendAs(t, router, other, "POST", "/api/holds", gin.H{"resource_id": held.ID}).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", renewPath(heldLoan), nil).Code)
+
+	// Refusé pour un prêt en retard
+	late := borrow(member, createTestResource(t, db, "Solaris", "Livre", 1), models.BorrowTakeAway)
+	assert.NoError(t, db.Model(&models.Loan{}).Where("id = ?", late.ID).Update("return_date", time.Now().Add(-time.Hour)).Error)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", renewPath(late), nil).Code)
+
+	// Refusé pour un prêt sur place et pour un prêt rendu
+	onSite := borrow(member, createTestResource(t, db, "Ubik", "Livre", 1), models.BorrowOnSite)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", renewPath(onSite), nil).Code)
+	returned := borrow(member, createTestResource(t, db, "Neuromancien", "Livre", 1), models.BorrowTakeAway)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, member, "PUT", "/api/loans/"+strconv.Itoa(int(returned.ID))+"/return", nil).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, member, "PUT", renewPath(returned), nil).Code)
+
+	// Le personnel peut renouveler le prêt d'un adhérent
+	librarian := createTestUser(t, db, "renew.librarian@example.com", models.RoleLibrarian)
+	staffRenewed := borrow(member, createTestResource(t, db, "Les Dépossédés", "Livre", 1), models.BorrowTakeAway)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PUT", renewPath(staffRenewed), nil).Code)
+
+	var stored models.Loan
+	assert.NoError(t, db.Preload("Renewals").First(&stored, staffRenewed.ID).Error)
+	assert.Equal(t, 1, stored.RenewalCount)
+	if assert.Len(t, stored.Renewals, 1) {
+		assert.Equal(t, librarian.ID, stored.Renewals[0].RenewedByID)
+	}
+}
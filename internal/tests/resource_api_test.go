@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"awesomeProject/internal/config"
 	"awesomeProject/internal/routes"
 	"bytes"
 	"encoding/json"
@@ -10,30 +11,26 @@ import (
 	"strconv"
 	"testing"
 
-	"awesomeProject/internal/database"
 	"awesomeProject/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
-// TestMain permet d'initialiser et de nettoyer la base de données pour les tests.
-func TestMain(m *testing.M) {
-	// Initialisation de la base de données (utilisez ici une configuration adaptée aux tests)
-	database.InitDB()
-	// Migration de la table Resource (les autres tables ne sont pas utilisées ici)
-	database.DB.AutoMigrate(&models.Resource{})
-
-	// Exécuter les tests
-	code := m.Run()
+// testConfig est la configuration partagée par les tests.
+var testConfig *config.Config
 
-	// Fermer la base de données
-	database.CloseDB()
-	os.Exit(code)
+// TestMain prépare la configuration des tests. Chaque test ouvre sa propre base avec newTestDB.
+func TestMain(m *testing.M) {
+	testConfig = config.Default()
+	testConfig.Env = config.EnvTest
+	os.Exit(m.Run())
 }
 
 // TestResourceAPI teste l'ensemble des endpoints pour les ressources : création, récupération de la liste et récupération par ID.
 func TestResourceAPI(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
 	// Récupérer le routeur configuré
-	router := routes.SetupRouter()
+	router := routes.SetupRouter(testConfig, db)
 
 	// --- Test de création d'une ressource (POST /api/resources) ---
 	newResource := models.Resource{
@@ -47,6 +44,7 @@ func TestResourceAPI(t *testing.T) {
 	reqCreate, err := http.NewRequest("POST", "/api/resources", bytes.NewBuffer(jsonValue))
 	assert.NoError(t, err)
 	reqCreate.Header.Set("Content-Type", "application/json")
+	reqCreate.Header.Set("Authorization", bearerToken(t, createTestUser(t, db, "librarian@example.com", models.RoleLibrarian)))
 
 	wCreate := httptest.NewRecorder()
 	router.ServeHTTP(wCreate, reqCreate)
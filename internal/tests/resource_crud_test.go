@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceUpdateAndDelete(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	decode := func(w *httptest.ResponseRecorder) models.Resource {
+		var resource models.Resource
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resource))
+		return resource
+	}
+
+	member := createTestUser(t, db, "crud.member@example.com", models.RoleMember)
+	other := createTestUser(t, db, "crud.other@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "crud.librarian@example.com", models.RoleLibrarian)
+	admin := createTestUser(t, db, "crud.admin@example.com", models.RoleAdmin)
+
+	const isbn = "9782253004226"
+	resource := createTestResource(t, db, "Le Pettit Prince", "Livre", 1)
+	path := "/api/resources/" + strconv.Itoa(int(resource.ID))
+
+	// Seul le personnel modifie le catalogue
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "PUT", path, gin.H{"Title": "X", "Type": "Livre"}).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "PATCH", path, gin.H{"Title": "X"}).Code)
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, member, "DELETE", path, nil).Code)
+
+	// PUT remplace le titre, le type et les métadonnées
+	w := sendAs(t, router, librarian, "PUT", path, gin.H{"Title": " Le Petit Prince ", "Type": "Livre", "Book": gin.H{"Authors": []string{"Antoine de Saint-Exupéry"}, "ISBN": isbn}})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	updated := decode(w)
+	assert.Equal(t, "Le Petit Prince", updated.Title)
+	assert.Equal(t, 1, updated.TotalCopies)
+	if assert.NotNil(t, updated.Book) {
+		assert.Equal(t, []string{"Antoine de Saint-Exupéry"}, updated.Book.Authors)
+	}
+
+	// Le navigateur peut envoyer PATCH depuis le frontend autorisé
+	preflight, _ := http.NewRequest("OPTIONS", path, nil)
+	preflight.Header.Set("Origin", testConfig.CORS.AllowOrigins[0])
+	preflight.Header.Set("Access-Control-Request-Method", "PATCH")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, preflight)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+
+	// PATCH fusionne les champs fournis ; null supprime
+	w = sendAs(t, router, librarian, "PATCH", path, gin.H{"Book": gin.H{"Year": 1943, "Language": "fr"}})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	patched := decode(w)
+	assert.Equal(t, "Le Petit Prince", patched.Title)
+	if assert.NotNil(t, patched.Book) && assert.NotNil(t, patched.Book.ISBN) {
+		assert.Equal(t, isbn, *patched.Book.ISBN)
+		assert.Equal(t, 1943, patched.Book.Year)
+		assert.Equal(t, []string{"Antoine de Saint-Exupéry"}, patched.Book.Authors)
+	}
+	w = sendAs(t, router, librarian, "PATCH", path, gin.H{"Book": gin.H{"ISBN": nil}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, decode(w).Book.ISBN)
+
+	// Validation champ par champ
+	for name, request := range map[string]struct {
+		method  string
+		payload gin.H
+	}{
+		"titre vide":          {"PUT", gin.H{"Title": "  ", "Type": "Livre"}},
+		"type manquant":       {"PUT", gin.H{"Title": "Le Petit Prince"}},
+		"titre à null":        {"PATCH", gin.H{"Title": nil}},
+		"statut":              {"PATCH", gin.H{"Status": "emprunté"}},
+		"ISBN invalide":       {"PATCH", gin.H{"Book": gin.H{"ISBN": "9782253004227"}}},
+		"type sans la notice": {"PATCH", gin.H{"Type": "Jeu"}},
+	} {
+		w = sendAs(t, router, librarian, request.method, path, request.payload)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PATCH", path, gin.H{"Type": "Jeu", "Book": nil, "Game": gin.H{"MinPlayers": 2}}).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PATCH", path, gin.H{"Type": "Livre", "Game": nil}).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, librarian, "PATCH", "/api/resources/999999", gin.H{"Title": "X"}).Code)
+
+	// Le nouveau titre est indexé pour la recherche
+	w = sendAs(t, router, member, "GET", "/api/search?"+url.Values{"q": {"petit prince"}}.Encode(), nil)
+	assert.Contains(t, w.Body.String(), `"ID":`+strconv.Itoa(int(resource.ID))+`,`)
+
+	// La suppression est refusée tant qu'un prêt est ouvert
+	w = sendAs(t, router, member, "POST", "/api/loans", gin.H{"resource_id": resource.ID, "borrow_type": "a_emporter"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var loan models.Loan
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loan))
+	assert.Equal(t, http.StatusCreated, sendAs(t, router, other, "POST", "/api/holds", gin.H{"resource_id": resource.ID}).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "DELETE", path, nil).Code)
+
+	// Après le retour, l'exemplaire mis de côté est libéré et la réservation annulée
+	assert.Equal(t, http.StatusOK, sendAs(t, router, member, "PUT", "/api/loans/"+strconv.Itoa(int(loan.ID))+"/return", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "DELETE", path, nil).Code)
+	var hold models.Hold
+	assert.NoError(t, db.Where("resource_id = ? AND user_id = ?", resource.ID, other.ID).First(&hold).Error)
+	assert.Equal(t, models.HoldCancelled, hold.Status)
+	var copy models.Copy
+	assert.NoError(t, db.Where("resource_id = ?", resource.ID).First(&copy).Error)
+	assert.Equal(t, models.ResourceAvailable, copy.Status)
+
+	// La ressource disparaît du catalogue, l'historique des prêts est conservé
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, member, "GET", path, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, librarian, "DELETE", path, nil).Code)
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, member, "POST", "/api/loans", gin.H{"resource_id": resource.ID, "borrow_type": "a_emporter"}).Code)
+	w = sendAs(t, router, member, "GET", "/api/search?"+url.Values{"q": {"petit prince"}}.Encode(), nil)
+	assert.NotContains(t, w.Body.String(), `"ID":`+strconv.Itoa(int(resource.ID))+`,`)
+	assert.NoError(t, db.First(&models.Loan{}, loan.ID).Error)
+
+	// Les administrateurs listent et restaurent les ressources supprimées
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, librarian, "GET", "/api/admin/resources/deleted", nil).Code)
+	w = sendAs(t, router, admin, "GET", "/api/admin/resources/deleted", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var deleted []models.Resource
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deleted))
+	found := false
+	for _, item := range deleted {
+		found = found || item.ID == resource.ID
+	}
+	assert.True(t, found)
+
+	restorePath := "/api/admin/resources/" + strconv.Itoa(int(resource.ID)) + "/restore"
+	w = sendAs(t, router, admin, "PUT", restorePath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Le Petit Prince", decode(w).Title)
+	assert.Equal(t, http.StatusNotFound, sendAs(t, router, admin, "PUT", restorePath, nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, member, "GET", path, nil).Code)
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceSearch(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	// Définir une fonction helper pour interroger le catalogue
+	list := func(query string) (*httptest.ResponseRecorder, []models.Resource) {
+		req, _ := http.NewRequest("GET", "/api/resources?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resources []models.Resource
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resources))
+		}
+		return w, resources
+	}
+	titles := func(resources []models.Resource) []string {
+		var result []string
+		for _, resource := range resources {
+			result = append(result, resource.Title)
+		}
+		return result
+	}
+
+	const tag = "Qwzrecherche"
+	createTestResource(t, db, tag+" Alpha", "Livre", 1)
+	createTestResource(t, db, tag+" Bravo", "Jeu", 1)
+	createTestResource(t, db, tag+" Charlie", "Livre", 1)
+	delta := createTestResource(t, db, tag+" Delta 100%", "Livre", 2)
+	createTestResource(t, db, tag+" Echo", "Livre", 1)
+	assert.NoError(t, db.Model(&models.Resource{}).Where("id = ?", delta.ID).Update("status", models.ResourceUnavailable).Error)
+
+	// Recherche insensible à la casse, tous les mots requis
+	w, resources := list("q=" + strings.ToLower(tag))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, []string{tag + " Alpha", tag + " Bravo", tag + " Charlie", tag + " Delta 100%", tag + " Echo"}, titles(resources))
+	_, resources = list(url.Values{"q": {tag + " delta"}}.Encode())
+	assert.Equal(t, []string{tag + " Delta 100%"}, titles(resources))
+	// Les jokers de LIKE sont recherchés littéralement
+	_, resources = list(url.Values{"q": {tag + " 100%"}}.Encode())
+	assert.Len(t, resources, 1)
+	_, resources = list(url.Values{"q": {tag + " _"}}.Encode())
+	assert.Empty(t, resources)
+
+	// Filtres par type et par statut
+	_, resources = list("q=" + tag + "&type=Jeu")
+	assert.Equal(t, []string{tag + " Bravo"}, titles(resources))
+	_, resources = list("q=" + tag + "&type=Livre&status=disponible")
+	assert.Equal(t, []string{tag + " Alpha", tag + " Charlie", tag + " Echo"}, titles(resources))
+	if assert.NotEmpty(t, resources) {
+		assert.Equal(t, 1, resources[0].TotalCopies)
+	}
+
+	// Tri décroissant et pagination
+	w, resources = list("q=" + tag + "&sort=title&order=desc&per_page=2&page=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{tag + " Charlie", tag + " Bravo"}, titles(resources))
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	link := w.Header().Get("Link")
+	assert.Contains(t, link, `page=1&per_page=2&q=`+tag+`&sort=title>; rel="prev"`)
+	assert.Contains(t, link, `page=3&per_page=2&q=`+tag+`&sort=title>; rel="next"`)
+	assert.Contains(t, link, `page=3&per_page=2&q=`+tag+`&sort=title>; rel="last"`)
+
+	w, resources = list("q=" + tag + "&per_page=2&page=3")
+	assert.Equal(t, []string{tag + " Echo"}, titles(resources))
+	assert.NotContains(t, w.Header().Get("Link"), `rel="next"`)
+	w, resources = list("q=" + tag + "&page=9")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resources)
+	assert.Equal(t, "[]", w.Body.String())
+
+	// Paramètres invalides
+	for _, query := range []string{"sort=password", "order=up", "status=perdu", "page=0", "per_page=1000", "page=abc"} {
+		w, _ = list(query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleBasedAccess(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	// Création des utilisateurs ; le rôle de l'adhérent sera modifié par l'administrateur
+	member := createTestUser(t, db, "role.member@example.com", models.RoleMember)
+	librarian := createTestUser(t, db, "role.librarian@example.com", models.RoleLibrarian)
+	admin := createTestUser(t, db, "role.admin@example.com", models.RoleAdmin)
+
+	memberToken := bearerToken(t, member)
+	librarianToken := bearerToken(t, librarian)
+	adminToken := bearerToken(t, admin)
+	newResource := gin.H{"Title": "Role Book", "Type": "Livre"}
+
+	// Un adhérent ne peut pas modifier le catalogue ni remplir la base
+	assert.Equal(t, http.StatusForbidden, sendJSON(t, router, memberToken, "POST", "/api/resources", newResource).Code)
+	assert.Equal(t, http.StatusForbidden, sendJSON(t, router, memberToken, "PUT", "/api/resources/1/disable", nil).Code)
+	assert.Equal(t, http.StatusForbidden, sendJSON(t, router, memberToken, "POST", "/api/admin/seed", nil).Code)
+	assert.Equal(t, http.StatusForbidden, sendJSON(t, router, memberToken, "GET", "/api/loans?user_id=1", nil).Code)
+	assert.Equal(t, http.StatusForbidden, sendJSON(t, router, memberToken, "GET", "/api/admin/users", nil).Code)
+
+	// Un bibliothécaire peut modifier le catalogue mais pas les rôles
+	assert.Equal(t, http.StatusCreated, sendJSON(t, router, librarianToken, "POST", "/api/resources", newResource).Code)
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, librarianToken, "GET", "/api/loans?user_id=1", nil).Code)
+	assert.Equal(t, http.StatusForbidden, sendJSON(t, router, librarianToken, "GET", "/api/admin/users", nil).Code)
+
+	// Un administrateur peut promouvoir puis rétrograder un utilisateur
+	rolePath := "/api/admin/users/" + strconv.Itoa(int(member.ID)) + "/role"
+	w := sendJSON(t, router, adminToken, "PUT", rolePath, gin.H{"role": models.RoleLibrarian})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var promoted models.User
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &promoted))
+	assert.Equal(t, models.RoleLibrarian, promoted.Role)
+	assert.Empty(t, promoted.Password)
+
+	// Le token émis avec l'ancien rôle n'est plus accepté
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, memberToken, "GET", "/api/loans", nil).Code)
+
+	assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, adminToken, "PUT", rolePath, gin.H{"role": "superuser"}).Code)
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, adminToken, "PUT", rolePath, gin.H{"role": models.RoleMember}).Code)
+	assert.Equal(t, http.StatusNotFound, sendJSON(t, router, adminToken, "PUT", "/api/admin/users/999998/role", gin.H{"role": models.RoleMember}).Code)
+
+	var stored models.User
+	assert.NoError(t, db.First(&stored, member.ID).Error)
+	assert.Equal(t, models.RoleMember, stored.Role)
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	// Définir une fonction helper pour lancer une recherche
+	search := func(params url.Values) (*httptest.ResponseRecorder, []service.SearchHit) {
+		req, _ := http.NewRequest("GET", "/api/search?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var hits []service.SearchHit
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hits))
+		}
+		return w, hits
+	}
+	titles := func(hits []service.SearchHit) []string {
+		var result []string
+		for _, hit := range hits {
+			result = append(result, hit.Title)
+		}
+		return result
+	}
+
+	const tag = "Qzplein"
+	lord := createTestResource(t, db, tag+" Le Seigneur des Anneaux", "Livre", 2)
+	createTestResource(t, db, tag+" L'Étranger", "Livre", 1)
+	createTestResource(t, db, tag+" Anneaux, anneaux & anneaux", "Jeu", 1)
+	createTestResource(t, db, tag+" <script>Anneaux</script>", "Jeu", 1)
+
+	// Les mots peuvent être incomplets et dans le désordre ; les accents et la casse sont ignorés
+	w, hits := search(url.Values{"q": {tag + " anneaux seign"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{tag + " Le Seigneur des Anneaux"}, titles(hits))
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, lord.ID, hits[0].ID)
+		assert.Equal(t, 2, hits[0].TotalCopies)
+		assert.Equal(t, "<mark>"+tag+"</mark> Le <mark>Seigneur</mark> des <mark>Anneaux</mark>", hits[0].Snippet)
+	}
+	_, hits = search(url.Values{"q": {tag + " ETRANGER"}})
+	assert.Equal(t, []string{tag + " L'Étranger"}, titles(hits))
+	_, hits = search(url.Values{"q": {tag + " étrangé"}})
+	assert.Len(t, hits, 1)
+
+	// Les résultats les plus pertinents d'abord ; les extraits sont échappés
+	w, hits = search(url.Values{"q": {tag + " anneaux"}})
+	assert.Equal(t, "3", w.Header().Get("X-Total-Count"))
+	if assert.Len(t, hits, 3) {
+		assert.Equal(t, tag+" Anneaux, anneaux & anneaux", hits[0].Title)
+		assert.GreaterOrEqual(t, hits[0].Score, hits[1].Score)
+		assert.GreaterOrEqual(t, hits[1].Score, hits[2].Score)
+		for _, hit := range hits {
+			assert.NotContains(t, hit.Snippet, "<script>")
+		}
+	}
+	_, hits = search(url.Values{"q": {tag + " anneaux"}, "type": {"Livre"}})
+	assert.Equal(t, []string{tag + " Le Seigneur des Anneaux"}, titles(hits))
+	w, hits = search(url.Values{"q": {tag + " anneaux"}, "per_page": {"2"}, "page": {"2"}})
+	assert.Len(t, hits, 1)
+	assert.Contains(t, w.Header().Get("Link"), `rel="prev"`)
+
+	// L'index suit les modifications et suppressions du catalogue
+	assert.NoError(t, db.Model(&lord).Update("title", tag+" Bilbo le Hobbit").Error)
+	_, hits = search(url.Values{"q": {tag + " seigneur"}})
+	assert.Empty(t, hits)
+	_, hits = search(url.Values{"q": {tag + " hobbit"}})
+	assert.Len(t, hits, 1)
+	assert.NoError(t, db.Delete(&models.Resource{}, lord.ID).Error)
+	_, hits = search(url.Values{"q": {tag + " hobbit"}})
+	assert.Empty(t, hits)
+
+	// La syntaxe FTS5 est neutralisée ; une recherche vide est refusée
+	w, hits = search(url.Values{"q": {`"` + tag + `" OR NEAR(* -`}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, hits, 0)
+	w, _ = search(url.Values{"q": {"  "}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, hits = search(url.Values{"q": {"!!!"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, hits)
+}
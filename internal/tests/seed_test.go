@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/config"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"awesomeProject/internal/seed"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestSeedIsReproducible(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 18, 0, 0, 0, time.UTC)
+	opts := seed.Options{Seed: 42, Now: now, Members: 10, Loans: testConfig.Loans}
+
+	// Définir une fonction helper pour résumer les prêts d'une base
+	type loanRow struct {
+		Email      string
+		Title      string
+		BorrowType string
+		LoanDate   time.Time
+		ReturnDate time.Time
+		Status     string
+	}
+	loans := func(db *gorm.DB) []loanRow {
+		var rows []loanRow
+		assert.NoError(t, db.Table("loans").
+			Select("users.email, resources.title, loans.borrow_type, loans.loan_date, loans.return_date, loans.status").
+			Joins("JOIN users ON users.id = loans.user_id").
+			Joins("JOIN resources ON resources.id = loans.resource_id").
+			Order("loans.id").Scan(&rows).Error)
+		return rows
+	}
+	seeded := func(opts seed.Options) (*gorm.DB, seed.Summary) {
+		db := newTestDB(t)
+		summary, err := seed.Run(db, opts)
+		assert.NoError(t, err)
+		return db, summary
+	}
+
+	first, summary := seeded(opts)
+	assert.Equal(t, 12, summary.Users, "administrateur, bibliothécaire et adhérents")
+	assert.Equal(t, 60, summary.Resources)
+	assert.NotZero(t, summary.Loans)
+	assert.NotZero(t, summary.Overdue)
+
+	// Métadonnées, prêts en retard avec leur amende, exemplaires prêtés
+	var books, games, fines int64
+	first.Model(&models.BookDetails{}).Count(&books)
+	first.Model(&models.GameDetails{}).Count(&games)
+	first.Model(&models.FineEntry{}).Where("kind = ?", models.FineCharge).Count(&fines)
+	assert.Equal(t, int64(30), books)
+	assert.Equal(t, int64(30), games)
+	assert.Equal(t, int64(summary.Overdue), fines)
+	for _, loan := range loans(first) {
+		assert.False(t, loan.LoanDate.After(now), "prêt daté après la date de référence")
+		switch models.LoanStatus(loan.Status) {
+		case models.LoanActive:
+			assert.True(t, loan.ReturnDate.After(now))
+		case models.LoanOverdue:
+			assert.True(t, loan.ReturnDate.Before(now))
+		}
+	}
+	var open, borrowed int64
+	first.Model(&models.Loan{}).Where("status IN ?", models.OpenLoanStatuses).Count(&open)
+	first.Model(&models.Copy{}).Where("status = ?", models.ResourceBorrowed).Count(&borrowed)
+	assert.Equal(t, open, borrowed)
+
+	// Même graine : même jeu de données
+	second, again := seeded(opts)
+	assert.Equal(t, summary, again)
+	assert.Equal(t, loans(first), loans(second))
+
+	// Autre graine : autre jeu de données
+	other, _ := seeded(seed.Options{Seed: 43, Now: now, Members: 10, Loans: testConfig.Loans})
+	assert.NotEqual(t, loans(first), loans(other))
+
+	// Une seconde exécution ne crée rien
+	summary, err := seed.Run(first, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, seed.Summary{}, summary)
+}
+
+func TestSeedEndpoint(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	librarian := createTestUser(t, db, "seed.librarian@example.com", models.RoleLibrarian)
+	admin := createTestUser(t, db, "seed.admin@example.com", models.RoleAdmin)
+
+	// Réservé aux administrateurs, paramètres vérifiés
+	assert.Equal(t, http.StatusForbidden, sendAs(t, router, librarian, "POST", "/api/admin/seed", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, admin, "POST", "/api/admin/seed?seed=abc", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, admin, "POST", "/api/admin/seed?members=0", nil).Code)
+
+	// Absent en production
+	production := *testConfig
+	production.Env = config.EnvProduction
+	assert.Equal(t, http.StatusNotFound, sendAs(t, routes.SetupRouter(&production, db), admin, "POST", "/api/admin/seed?seed=abc", nil).Code)
+}
@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"awesomeProject/internal/clock"
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/repository"
+	"awesomeProject/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// domainError vérifie que err est une erreur du domaine de la catégorie attendue et la retourne.
+func domainError(t *testing.T, err error, kind service.Kind) *service.Error {
+	t.Helper()
+	var domainErr *service.Error
+	require.True(t, errors.As(err, &domainErr), "erreur du domaine attendue : %v", err)
+	assert.Equal(t, kind, domainErr.Kind)
+	return domainErr
+}
+
+func TestLoanServiceInMemory(t *testing.T) {
+	store := repository.NewMemoryStore()
+	clk := clock.NewFake(time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC))
+	loans := service.NewLoanService(store, testConfig.Loans, clk)
+	users := service.NewUserService(store)
+
+	member, err := users.Register("Alice", "alice@example.com", "secret1")
+	require.NoError(t, err)
+	other, err := users.Register("Bob", "bob@example.com", "secret2")
+	require.NoError(t, err)
+	alice := service.Actor{ID: member.ID, Role: models.RoleMember}
+	bob := service.Actor{ID: other.ID, Role: models.RoleMember}
+
+	book := &models.Resource{Title: "Dune", Type: "Livre"}
+	store.AddResource(book)
+	store.AddPolicy(models.LoanPolicy{
+		ResourceType: "Jeu", BorrowType: models.BorrowTakeAway, Allowed: true,
+		LoanDays: 7, MaxConcurrentLoans: 1, MaxRenewals: 1, RenewalDays: 7,
+	})
+	games := []*models.Resource{{Title: "Catan", Type: "Jeu"}, {Title: "Azul", Type: "Jeu"}}
+	for _, game := range games {
+		store.AddResource(game)
+	}
+
+	// Ressource inconnue
+	_, err = loans.Borrow(alice, service.BorrowRequest{ResourceID: 999, BorrowType: models.BorrowTakeAway})
+	domainError(t, err, service.KindNotFound)
+
+	// Emprunt du seul exemplaire, puis ressource indisponible pour le suivant
+	loan, err := loans.Borrow(alice, service.BorrowRequest{ResourceID: book.ID, BorrowType: models.BorrowTakeAway})
+	require.NoError(t, err)
+	assert.Equal(t, book.Copies[0].ID, loan.CopyID)
+	copy, _ := store.Copy(loan.CopyID)
+	assert.Equal(t, models.ResourceBorrowed, copy.Status)
+	_, err = loans.Borrow(bob, service.BorrowRequest{ResourceID: book.ID, BorrowType: models.BorrowTakeAway})
+	unavailable := domainError(t, err, service.KindConflict)
+	assert.Equal(t, "emprunté", unavailable.Details["current_status"])
+
+	// La politique du jeu limite à un prêt simultané
+	_, err = loans.Borrow(alice, service.BorrowRequest{ResourceID: games[0].ID, BorrowType: models.BorrowTakeAway})
+	require.NoError(t, err)
+	_, err = loans.Borrow(alice, service.BorrowRequest{ResourceID: games[1].ID, BorrowType: models.BorrowTakeAway})
+	tooMany := domainError(t, err, service.KindConflict)
+	assert.ErrorIs(t, err, service.ErrTooManyLoans)
+	assert.Equal(t, 1, tooMany.Details["max_concurrent_loans"])
+
+	// Seuls l'emprunteur et le personnel accèdent au prêt
+	_, err = loans.Renew(bob, loan.ID)
+	domainError(t, err, service.KindForbidden)
+	_, err = loans.List(bob, alice.ID)
+	domainError(t, err, service.KindForbidden)
+	listed, err := loans.List(service.Actor{ID: 999, Role: models.RoleLibrarian}, alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+
+	// Une réservation en attente empêche le renouvellement ; le retour sert la file
+	store.AddHold(&models.Hold{UserID: bob.ID, ResourceID: book.ID, CreatedAt: clk.Now()})
+	_, err = loans.Renew(alice, loan.ID)
+	assert.ErrorIs(t, err, service.ErrHoldPending)
+
+	clk.Advance(30 * 24 * time.Hour)
+	returned, err := loans.Return(alice, loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.LoanReturned, returned.Status)
+	copy, _ = store.Copy(loan.CopyID)
+	assert.Equal(t, models.ResourceReserved, copy.Status)
+	_, err = loans.Return(alice, loan.ID)
+	assert.Equal(t, "Le prêt est déjà retourné", domainError(t, err, service.KindConflict).Message)
+
+	// Le retour tardif a inscrit une amende qui bloque les emprunts au-delà du seuil
+	store.AddFine(models.FineEntry{UserID: alice.ID, Kind: models.FineCharge, Amount: testConfig.Loans.FineBlockThreshold + 1})
+	_, err = loans.Borrow(alice, service.BorrowRequest{ResourceID: games[1].ID, BorrowType: models.BorrowOnSite})
+	fines := domainError(t, err, service.KindForbidden)
+	assert.Greater(t, fines.Details["balance"], testConfig.Loans.FineBlockThreshold)
+
+	// Le réservataire emprunte l'exemplaire mis de côté pour lui
+	held, err := loans.Borrow(bob, service.BorrowRequest{ResourceID: book.ID, BorrowType: models.BorrowTakeAway})
+	require.NoError(t, err)
+	assert.Equal(t, loan.CopyID, held.CopyID)
+}
+
+func TestHoldProcessingIsScopedToResource(t *testing.T) {
+	store := repository.NewMemoryStore()
+	clk := clock.NewFake(time.Date(2025, time.March, 3, 10, 0, 0, 0, time.UTC))
+	holds := service.NewHoldService(store, testConfig.Loans, clk)
+	staff := service.Actor{ID: 1, Role: models.RoleLibrarian}
+
+	// Deux ressources dont l'exemplaire est mis de côté pour une réservation dont le délai de retrait est passé
+	deadline := clk.Now().Add(-time.Hour)
+	var expired []*models.Hold
+	for _, title := range []string{"Dune", "Hyperion"} {
+		resource := &models.Resource{Title: title, Type: "Livre", Copies: []models.Copy{{Status: models.ResourceReserved}}}
+		store.AddResource(resource)
+		hold := &models.Hold{UserID: 2, ResourceID: resource.ID, Status: models.HoldReady,
+			CopyID: &resource.Copies[0].ID, ReadyAt: &deadline, PickupDeadline: &deadline}
+		store.AddHold(hold)
+		expired = append(expired, hold)
+	}
+
+	// Consulter la file de la première ressource ne traite que celle-ci
+	_, err := holds.List(staff, expired[0].ResourceID)
+	require.NoError(t, err)
+	first, _ := store.Hold(expired[0].ID)
+	assert.Equal(t, models.HoldExpired, first.Status)
+	second, _ := store.Hold(expired[1].ID)
+	assert.Equal(t, models.HoldReady, second.Status)
+
+	// La tâche planifiée traite toutes les ressources
+	expiredCount, _, err := holds.Process(clk.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, expiredCount)
+	second, _ = store.Hold(expired[1].ID)
+	assert.Equal(t, models.HoldExpired, second.Status)
+}
+
+func TestMemoryStoreTransactionRollback(t *testing.T) {
+	store := repository.NewMemoryStore()
+	resource := &models.Resource{Title: "Carcassonne", Type: "Jeu"}
+	store.AddResource(resource)
+
+	failure := errors.New("échec")
+	err := store.Transaction(func(tx service.Store) error {
+		if err := tx.Copies().SetStatus(resource.Copies[0].ID, models.ResourceAvailable, models.ResourceBorrowed); err != nil {
+			return err
+		}
+		if err := tx.Resources().SetStatus(resource.ID, models.ResourceBorrowed); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	// L'exemplaire pris dans la transaction annulée est toujours disponible
+	copy, _ := store.Copy(resource.Copies[0].ID)
+	assert.Equal(t, models.ResourceAvailable, copy.Status)
+	found, err := store.Resources().FindByID(resource.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ResourceAvailable, found.Status)
+}
+
+func TestUserServiceInMemory(t *testing.T) {
+	users := service.NewUserService(repository.NewMemoryStore())
+
+	admin, err := users.Register("Admin", "admin@example.com", "secret1")
+	require.NoError(t, err)
+	assert.Empty(t, admin.Password)
+	assert.Equal(t, models.RoleMember, admin.Role)
+	_, err = users.Register("Autre", "admin@example.com", "secret2")
+	assert.ErrorIs(t, err, service.ErrEmailTaken)
+	domainError(t, err, service.KindConflict)
+
+	// Mot de passe erroné et email inconnu : même erreur
+	_, err = users.Authenticate("admin@example.com", "mauvais")
+	domainError(t, err, service.KindUnauthorized)
+	_, err = users.Authenticate("inconnu@example.com", "secret1")
+	domainError(t, err, service.KindUnauthorized)
+	logged, err := users.Authenticate("admin@example.com", "secret1")
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, logged.ID)
+
+	// Changements de rôle
+	actor := service.Actor{ID: admin.ID, Role: models.RoleAdmin}
+	member, err := users.Register("Membre", "membre@example.com", "secret3")
+	require.NoError(t, err)
+	_, err = users.ChangeRole(actor, admin.ID, models.RoleMember)
+	assert.ErrorIs(t, err, service.ErrOwnRole)
+	_, err = users.ChangeRole(actor, 999, models.RoleLibrarian)
+	domainError(t, err, service.KindNotFound)
+	promoted, err := users.ChangeRole(actor, member.ID, models.RoleLibrarian)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleLibrarian, promoted.Role)
+
+	// Un email déjà attribué ne peut pas être repris
+	_, err = users.UpdateProfile(member.ID, "Membre", "admin@example.com", nil)
+	domainError(t, err, service.KindConflict)
+	profile, err := users.UpdateProfile(member.ID, "Membre renommé", "membre@example.com", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Membre renommé", profile.Name)
+	assert.Empty(t, profile.Password)
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusTransitionTable(t *testing.T) {
+	assert.True(t, models.ResourceAvailable.Allows(models.ResourceBorrow))
+	assert.True(t, models.ResourceAvailable.Allows(models.ResourceDisable))
+	assert.True(t, models.ResourceUnavailable.Allows(models.ResourceEnable))
+	assert.True(t, models.ResourceBorrowed.Allows(models.ResourceReturn))
+	assert.False(t, models.ResourceBorrowed.Allows(models.ResourceEnable))
+	assert.False(t, models.ResourceBorrowed.Allows(models.ResourceDisable))
+	assert.False(t, models.ResourceUnavailable.Allows(models.ResourceBorrow))
+	assert.False(t, models.ResourceAvailable.Allows(models.ResourceEnable))
+	assert.Equal(t, models.ResourceAvailable, models.ResourceEnable.Target())
+	assert.False(t, models.ResourceStatus("perdu").IsValid())
+
+	assert.True(t, models.LoanActive.Allows(models.LoanReturn))
+	assert.False(t, models.LoanReturned.Allows(models.LoanReturn))
+}
+
+func TestResourceStatusTransitions(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+	librarian := createTestUser(t, db, "status.librarian@example.com", models.RoleLibrarian)
+
+	resource := createTestResource(t, db, "Machine à états", "Livre", 1)
+	resourcePath := "/api/resources/" + strconv.Itoa(int(resource.ID))
+
+	// Emprunt de la ressource
+	assert.Equal(t, http.StatusCreated, sendAs(t, router, librarian, "POST", "/api/loans", gin.H{"resource_id": resource.ID, "borrow_type": "sur_place"}).Code)
+
+	// Une ressource empruntée ne peut être ni remise en disponible, ni retirée
+	w := sendAs(t, router, librarian, "PUT", resourcePath+"/enable", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict struct {
+		Error struct {
+			Code    string
+			Details map[string]interface{}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, "INVALID_TRANSITION", conflict.Error.Code)
+	assert.Equal(t, "emprunté", conflict.Error.Details["current_status"])
+	assert.Equal(t, "disponible", conflict.Error.Details["requested_status"])
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "PUT", resourcePath+"/disable", nil).Code)
+
+	// Ni empruntée une seconde fois
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "POST", "/api/loans", gin.H{"resource_id": resource.ID, "borrow_type": "sur_place"}).Code)
+
+	// Après le retour, la ressource peut être retirée puis remise en service
+	var loan models.Loan
+	assert.NoError(t, db.Where("resource_id = ?", resource.ID).First(&loan).Error)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PUT", "/api/loans/"+strconv.Itoa(int(loan.ID))+"/return", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PUT", resourcePath+"/disable", nil).Code)
+	assert.Equal(t, http.StatusConflict, sendAs(t, router, librarian, "PUT", resourcePath+"/disable", nil).Code)
+	assert.Equal(t, http.StatusOK, sendAs(t, router, librarian, "PUT", resourcePath+"/enable", nil).Code)
+
+	// Une ressource ne peut pas être créée avec un statut arbitraire
+	assert.Equal(t, http.StatusBadRequest, sendAs(t, router, librarian, "POST", "/api/resources", gin.H{"Title": "X", "Type": "Livre", "Status": "emprunté"}).Code)
+
+	// La contrainte CHECK protège la colonne même en dehors de l'API
+	err := db.Model(&models.Resource{}).Where("id = ?", resource.ID).Update("status", "perdu").Error
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"awesomeProject/internal/models"
+	"awesomeProject/internal/routes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRefreshTokensAndLogout(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	// Création d'un utilisateur avec un mot de passe connu
+	user := createTestUser(t, db, "token@example.com", models.RoleMember)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	db.Model(&user).Update("password", string(hashed))
+	decode := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// --- Connexion : un token d'accès et un token de rafraîchissement sont émis ---
+	wLogin := sendJSON(t, router, "", "POST", "/api/login", gin.H{"email": user.Email, "password": "password123"})
+	assert.Equal(t, http.StatusOK, wLogin.Code)
+	login := decode(wLogin)
+	accessToken, _ := login["token"].(string)
+	refreshToken, _ := login["refresh_token"].(string)
+	assert.NotEmpty(t, accessToken)
+	assert.NotEmpty(t, refreshToken)
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, "Bearer "+accessToken, "GET", "/api/profile", nil).Code)
+
+	// --- Rafraîchissement avec rotation du token ---
+	wRefresh := sendJSON(t, router, "", "POST", "/api/token/refresh", gin.H{"refresh_token": refreshToken})
+	assert.Equal(t, http.StatusOK, wRefresh.Code)
+	refreshed := decode(wRefresh)
+	newAccessToken, _ := refreshed["token"].(string)
+	newRefreshToken, _ := refreshed["refresh_token"].(string)
+	assert.NotEqual(t, refreshToken, newRefreshToken)
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, "Bearer "+newAccessToken, "GET", "/api/profile", nil).Code)
+
+	// Un token inconnu est refusé
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, "", "POST", "/api/token/refresh", gin.H{"refresh_token": "inconnu"}).Code)
+
+	// --- Déconnexion : le token d'accès et le token de rafraîchissement sont révoqués ---
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, "Bearer "+newAccessToken, "POST", "/api/logout", gin.H{"refresh_token": newRefreshToken}).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, "Bearer "+newAccessToken, "GET", "/api/profile", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, "", "POST", "/api/token/refresh", gin.H{"refresh_token": newRefreshToken}).Code)
+
+	// --- Réutilisation d'un token déjà consommé : toutes les sessions sont révoquées ---
+	wLogin = sendJSON(t, router, "", "POST", "/api/login", gin.H{"email": user.Email, "password": "password123"})
+	assert.Equal(t, http.StatusOK, wLogin.Code)
+	otherSession, _ := decode(wLogin)["refresh_token"].(string)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, "", "POST", "/api/token/refresh", gin.H{"refresh_token": refreshToken}).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, "", "POST", "/api/token/refresh", gin.H{"refresh_token": otherSession}).Code)
+
+	// --- Révocation des sessions par un administrateur ---
+	admin := createTestUser(t, db, "token.admin@example.com", models.RoleAdmin)
+	revoked := createTestUser(t, db, "token.revoked@example.com", models.RoleMember)
+	memberToken := bearerToken(t, revoked)
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, memberToken, "GET", "/api/profile", nil).Code)
+	revokePath := "/api/admin/users/" + strconv.Itoa(int(revoked.ID)) + "/revoke-tokens"
+	assert.Equal(t, http.StatusOK, sendJSON(t, router, bearerToken(t, admin), "POST", revokePath, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, sendJSON(t, router, memberToken, "GET", "/api/profile", nil).Code)
+}
+
+func TestConcurrentTokenRefresh(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	router := routes.SetupRouter(testConfig, db)
+
+	user := createTestUser(t, db, "token.concurrent@example.com", models.RoleMember)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	db.Model(&user).Update("password", string(hashed))
+
+	w := sendJSON(t, router, "", "POST", "/api/login", gin.H{"email": user.Email, "password": "password123"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var login map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	body, _ := json.Marshal(gin.H{"refresh_token": login["refresh_token"]})
+
+	// Le même token de rafraîchissement est présenté plusieurs fois au même moment
+	const attempts = 10
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest("POST", "/api/token/refresh", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			<-start
+			router.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	// Une seule rotation aboutit, les autres sont traitées comme une réutilisation
+	refreshed, refused := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			refreshed++
+		case http.StatusUnauthorized:
+			refused++
+		}
+	}
+	assert.Equal(t, 1, refreshed, "codes: %v", codes)
+	assert.Equal(t, attempts-1, refused, "codes: %v", codes)
+}
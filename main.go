@@ -1,17 +1,178 @@
 package main
 
 import (
+	"awesomeProject/internal/app"
+	"awesomeProject/internal/config"
 	"awesomeProject/internal/database"
-	"awesomeProject/internal/routes"
+	"awesomeProject/internal/seed"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// Utilisation :
+//
+//	go run .                      démarre le serveur (équivalent à "serve")
+//	go run . migrate [up]         applique les migrations en attente
+//	go run . migrate down [n]     annule les n dernières migrations (1 par défaut)
+//	go run . migrate status       affiche l'état des migrations
+//	go run . seed [-seed n] [-members n]
+//	                              insère le jeu de données de développement (graine 1, 20 adhérents par défaut)
 func main() {
-	database.InitDB()
-	defer database.CloseDB()
-	
-	router := routes.SetupRouter()
-	// Lancement du serveur sur le port 8080
-	router.Run(":8080")
+	// Chargement et validation de la configuration (variables d'environnement et fichier CONFIG_FILE)
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Erreur lors du chargement de la configuration", err)
+	}
+
+	application, err := app.New(cfg)
+	if err != nil {
+		fatal("Erreur lors de l'ouverture de la base de données", err)
+	}
+	defer func() {
+		if err := application.Close(); err != nil {
+			slog.Error("Erreur lors de la fermeture de la base de données", "error", err)
+		}
+	}()
+
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		err = runServer(application)
+	case "migrate":
+		err = runMigrate(application.DB, os.Args[2:])
+	case "seed":
+		err = runSeed(application, os.Args[2:])
+	default:
+		err = fmt.Errorf("commande inconnue %q (commandes disponibles : serve, migrate, seed)", command)
+	}
+	if err != nil {
+		application.Close()
+		fatal("Erreur", err)
+	}
+}
+
+// fatal journalise l'erreur puis arrête le programme. Avant la création de l'application,
+// le journal par défaut de slog (texte sur la sortie d'erreur) est utilisé.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
+
+// runServer applique les migrations si la configuration le demande, puis démarre le serveur HTTP.
+func runServer(application *app.App) error {
+	cfg := application.Config
+	if cfg.Database.AutoMigrate {
+		count, err := database.Migrate(application.DB)
+		if err != nil {
+			return err
+		}
+		slog.Info("Migrations appliquées", "count", count)
+	}
+
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Détection des retards et amendes en tâche de fond, pendant toute la durée du serveur
+	application.StartJobs(context.Background())
+
+	router := application.Router()
+	// Lancement du serveur sur l'adresse configurée
+	slog.Info("Démarrage du serveur", "addr", cfg.Server.Addr)
+	return router.Run(cfg.Server.Addr)
+}
+
+// runMigrate exécute la sous-commande "migrate".
+func runMigrate(db *gorm.DB, args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "up":
+		count, err := database.Migrate(db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Migrations appliquées: %d\n", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("nombre de migrations à annuler invalide: %q", args[1])
+			}
+			steps = n
+		}
+		count, err := database.Rollback(db, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Migrations annulées: %d\n", count)
+	case "status":
+		states, err := database.Status(db)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			applied := "en attente"
+			if state.AppliedAt != nil {
+				applied = "appliquée le " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s %s\n", state.Version, state.Name, applied)
+		}
+	default:
+		return fmt.Errorf("action de migration inconnue %q (actions disponibles : up, down, status)", action)
+	}
+	return nil
+}
+
+// runSeed exécute la sous-commande "seed" : migrations en attente puis jeu de données reproductible.
+func runSeed(application *app.App, args []string) error {
+	cfg := application.Config
+	if cfg.IsProduction() {
+		return fmt.Errorf("le jeu de données ne peut pas être inséré en production")
+	}
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	seedValue := flags.Int64("seed", 1, "graine du générateur : même graine, même jeu de données")
+	members := flags.Int("members", seed.DefaultMembers, "nombre d'adhérents générés")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *members < 1 {
+		return fmt.Errorf("nombre d'adhérents invalide: %d", *members)
+	}
+
+	if _, err := database.Migrate(application.DB); err != nil {
+		return err
+	}
+	summary, err := seed.Run(application.DB, seed.Options{
+		Seed:    *seedValue,
+		Now:     time.Now(),
+		Members: *members,
+		Loans:   cfg.Loans,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Jeu de données (graine %d): %d utilisateurs, %d ressources, %d prêts dont %d en retard\n",
+		*seedValue, summary.Users, summary.Resources, summary.Loans, summary.Overdue)
+	fmt.Printf("Comptes: %s, %s et les adhérents, mot de passe %q\n", seed.AdminEmail, seed.LibrarianEmail, seed.Password)
+	return nil
 }
 
 /*